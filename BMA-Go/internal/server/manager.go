@@ -3,17 +3,24 @@ package server
 import (
 	"context"
 	"fmt"
-	"log"
 	"net"
 	"net/http"
 	"sync"
 	"time"
 
+	"bma-core/library"
+	"bma-core/logging"
+	"bma-core/metrics"
+	"bma-core/pairing"
+	"bma-core/settings"
 	"bma-go/internal/models"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
+// logger tags the server's log lines with component=server
+var logger = logging.Component("server")
+
 // ServerManager manages the HTTP server lifecycle and configuration
 // Equivalent to ServerManager.swift in the macOS version
 type ServerManager struct {
@@ -28,21 +35,33 @@ type ServerManager struct {
 	server       *http.Server
 	router       *mux.Router
 	
+	// Prometheus metrics, kept across restarts of the server
+	metrics       *metrics.Registry
+	metricsAddr   string       // Listener for /metrics, apart from the API
+	metricsServer *http.Server
+	
 	// Music library
-	musicLibrary *models.MusicLibrary
+	musicLibrary *library.MusicLibrary
 	
 	// Device tracking
 	connectedDevices []models.ConnectedDevice
 	devicesMutex     sync.RWMutex
 	
 	// Token management  
-	pairingTokens    map[string]time.Time // token -> expiration
+	pairingTokens    *pairing.DeviceStore // Saved in ~/.bma/devices.json
 	tokensMutex      sync.RWMutex
 	currentPairingToken string
 	
 	// Flatpak detection
 	useFlatpakSpawn bool
 	
+	// Embedded Tailscale (tsnet) node
+	embeddedMode      bool
+	embeddedHostname  string
+	embeddedNode      *EmbeddedTailscale
+	embeddedListening bool
+	tailscaleMutex    sync.Mutex
+	
 	// Shutdown context
 	ctx        context.Context
 	cancelFunc context.CancelFunc
@@ -54,10 +73,30 @@ func NewServerManager() *ServerManager {
 	
 	sm := &ServerManager{
 		Port:            8008,
-		pairingTokens:   make(map[string]time.Time),
+		metrics:         metrics.NewRegistry(),
+		metricsAddr:     settings.DefaultMetricsAddr,
 		ctx:             ctx,
 		cancelFunc:      cancel,
 	}
+	sm.registerMetrics()
+	
+	// Paired devices are kept across restarts, like in bma-cli
+	devices, err := pairing.LoadDeviceStore()
+	if err != nil {
+		logger.Warn("Failed to load paired devices, pairings will not persist", "err", err)
+		devices = pairing.NewMemoryDeviceStore()
+	}
+	sm.pairingTokens = devices
+	
+	if config, err := settings.LoadConfig(); err == nil {
+		sm.metricsAddr = config.GetMetricsAddr()
+		
+		// Use BMA's own tailnet node if the setup wizard enabled it
+		if config.UsesEmbeddedTailscale() {
+			sm.embeddedMode = true
+			sm.embeddedHostname = config.EmbeddedTailscaleHostname()
+		}
+	}
 	
 	// Initialize Tailscale detection
 	go sm.checkTailscaleStatus()
@@ -66,23 +105,19 @@ func NewServerManager() *ServerManager {
 }
 
 // SetMusicLibrary connects a music library to the server manager
-func (sm *ServerManager) SetMusicLibrary(library *models.MusicLibrary) {
-	sm.musicLibrary = library
-	log.Println("🎵 MusicLibrary connected to ServerManager")
+func (sm *ServerManager) SetMusicLibrary(musicLibrary *library.MusicLibrary) {
+	sm.musicLibrary = musicLibrary
+	logger.Debug("Music library connected")
 }
 
 // StartServer starts the HTTP server on port 8008
 func (sm *ServerManager) StartServer() error {
 	if sm.IsRunning {
-		log.Println("⚠️ Server start requested but already running")
+		logger.Warn("Server start requested but already running")
 		return fmt.Errorf("server already running")
 	}
 	
-	log.Println("🚀 Starting BMA HTTP server...")
-	log.Printf("📊 Tailscale available: %v", sm.HasTailscale)
-	if sm.HasTailscale {
-		log.Printf("🔗 Tailscale URL: %s", sm.TailscaleURL)
-	}
+	logger.Info("Starting the HTTP server", "tailscale", sm.HasTailscale, "tailscale_url", sm.TailscaleURL)
 	
 	// Setup router and routes
 	sm.setupRouter()
@@ -99,9 +134,9 @@ func (sm *ServerManager) StartServer() error {
 	
 	// Start server in goroutine
 	go func() {
-		log.Printf("📡 HTTP server listening on %s", addr)
+		logger.Info("HTTP server listening", "addr", addr)
 		if err := sm.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("❌ Server failed: %v", err)
+			logger.Error("HTTP server failed", "err", err)
 			sm.IsRunning = false
 		}
 	}()
@@ -110,7 +145,11 @@ func (sm *ServerManager) StartServer() error {
 	sm.IsRunning = true
 	sm.updateServerURLs()
 	
-	log.Println("✅ BMA server started successfully!")
+	// Also serve on the tailnet when running our own node
+	sm.attachEmbeddedListener()
+	
+	sm.startMetricsServer()
+	
 	sm.logServerInfo()
 	
 	return nil
@@ -119,19 +158,20 @@ func (sm *ServerManager) StartServer() error {
 // StopServer stops the HTTP server
 func (sm *ServerManager) StopServer() error {
 	if !sm.IsRunning {
-		log.Println("⚠️ Server stop requested but not running")
+		logger.Debug("Server stop requested but not running")
 		return nil
 	}
 	
-	log.Println("🛑 Stopping BMA server...")
+	logger.Info("Stopping the HTTP server")
 	
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	
+	sm.stopMetricsServer(ctx)
 	if sm.server != nil {
 		if err := sm.server.Shutdown(ctx); err != nil {
-			log.Printf("❌ Server shutdown error: %v", err)
+			logger.Error("HTTP server shutdown failed", "err", err)
 			return err
 		}
 	}
@@ -140,20 +180,20 @@ func (sm *ServerManager) StopServer() error {
 	sm.IsRunning = false
 	sm.ServerURL = ""
 	sm.clearConnectedDevices()
-	sm.revokeAllTokens()
+	sm.clearCurrentPairingToken()
 	
-	log.Println("✅ Server stopped successfully")
+	logger.Info("HTTP server stopped")
 	return nil
 }
 
 // Cleanup performs cleanup when app terminates
 func (sm *ServerManager) Cleanup() {
-	log.Println("🧹 ServerManager cleanup...")
 	sm.cancelFunc()
 	if sm.IsRunning {
 		sm.StopServer()
 	}
-	log.Println("✅ ServerManager cleanup completed")
+	sm.closeEmbeddedNode()
+	logger.Debug("Server manager cleaned up")
 }
 
 // updateServerURLs sets the appropriate server URLs
@@ -163,46 +203,22 @@ func (sm *ServerManager) updateServerURLs() {
 	if sm.HasTailscale && sm.TailscaleURL != "" {
 		// Use HTTP over Tailscale (network-level encryption)
 		sm.ServerURL = fmt.Sprintf("%s:%d", sm.TailscaleURL, sm.Port)
-		log.Printf("🔒 Tailscale URL: %s", sm.ServerURL)
-		log.Printf("🌐 Local URL: http://%s:%d", localIP, sm.Port)
 	} else {
 		// Use local HTTP URL
 		sm.ServerURL = fmt.Sprintf("http://%s:%d", localIP, sm.Port)
-		log.Printf("🌐 Local URL: %s", sm.ServerURL)
 	}
 }
 
-// logServerInfo logs detailed server information
+// logServerInfo logs the URLs devices can connect to
 func (sm *ServerManager) logServerInfo() {
-	localIP := sm.getLocalIPAddress()
-	
-	log.Println("\n📡 SERVER NETWORK INFORMATION:")
-	log.Printf("   Local IP: %s", localIP)
-	log.Printf("   HTTP Port: %d", sm.Port)
-	log.Printf("   Listening on: 0.0.0.0:%d", sm.Port)
+	localURL := fmt.Sprintf("http://%s:%d", sm.getLocalIPAddress(), sm.Port)
 	
 	if sm.HasTailscale && sm.TailscaleURL != "" {
-		log.Println("\n🔒 TAILSCALE CONFIGURATION:")
-		log.Printf("   Tailscale URL: %s", sm.TailscaleURL)
-		log.Printf("   Public Access: %s", sm.ServerURL)
-		log.Println("   Note: HTTP over Tailscale (network-level encryption)")
-		
-		log.Println("\n✅ AVAILABLE CONNECTION URLs:")
-		log.Printf("   📱 Android (via Tailscale): %s", sm.ServerURL)
-		log.Printf("   🌐 Local network: http://%s:%d", localIP, sm.Port)
+		// HTTP over Tailscale (network-level encryption)
+		logger.Info("Server ready for music streaming", "url", sm.ServerURL, "local_url", localURL, "tailscale", true)
 	} else {
-		log.Println("\n🌐 LOCAL NETWORK CONFIGURATION:")
-		log.Printf("   Server URL: %s", sm.ServerURL)
-		log.Println("   Protocol: HTTP only (no Tailscale)")
-		
-		log.Println("\n✅ AVAILABLE CONNECTION URLs:")
-		log.Printf("   📱 Android (local): %s", sm.ServerURL)
-		log.Printf("   🖥️ Browser test: %s/health", sm.ServerURL)
+		logger.Info("Server ready for music streaming", "url", sm.ServerURL, "tailscale", false)
 	}
-	
-	log.Println("\n🎵 Server is ready for music streaming!")
-	log.Println("📱 Generate a QR code to pair devices")
-	log.Println("🔍 Watching for incoming connections...")
 }
 
 // getLocalIPAddress gets the local network IP address
@@ -225,13 +241,13 @@ func (sm *ServerManager) TrackDeviceConnection(token, ipAddress, userAgent strin
 	sm.devicesMutex.Lock()
 	defer sm.devicesMutex.Unlock()
 	
-	deviceName := sm.parseDeviceName(userAgent)
+	deviceName := pairing.DeviceNameFromUserAgent(userAgent)
 	
 	// Check if device already exists (update last seen)
 	for i, device := range sm.connectedDevices {
 		if device.Token == token {
 			sm.connectedDevices[i].LastSeenAt = time.Now()
-			log.Printf("📱 Updated device activity: %s", sm.connectedDevices[i].DeviceName)
+			logger.Debug("Device activity", "device", sm.connectedDevices[i].DeviceName)
 			return
 		}
 	}
@@ -248,7 +264,7 @@ func (sm *ServerManager) TrackDeviceConnection(token, ipAddress, userAgent strin
 	}
 	
 	sm.connectedDevices = append(sm.connectedDevices, device)
-	log.Printf("📱 New device connected: %s (%s)", device.DeviceName, device.IPAddress)
+	logger.Info("Device connected", "device", device.DeviceName, "ip", device.IPAddress)
 	
 	// Clean up inactive devices
 	sm.cleanupInactiveDevices()
@@ -263,7 +279,7 @@ func (sm *ServerManager) DisconnectDevice(token string) bool {
 		if device.Token == token {
 			// Remove device
 			sm.connectedDevices = append(sm.connectedDevices[:i], sm.connectedDevices[i+1:]...)
-			log.Printf("📱 Device disconnected: %s (%s)", device.DeviceName, device.IPAddress)
+			logger.Info("Device disconnected", "device", device.DeviceName, "ip", device.IPAddress)
 			
 			// Revoke token
 			sm.revokePairingToken(token)
@@ -290,7 +306,7 @@ func (sm *ServerManager) clearConnectedDevices() {
 	defer sm.devicesMutex.Unlock()
 	
 	sm.connectedDevices = []models.ConnectedDevice{}
-	log.Println("📱 All devices disconnected")
+	logger.Debug("All devices disconnected")
 }
 
 // cleanupInactiveDevices removes devices that haven't been seen recently
@@ -307,97 +323,30 @@ func (sm *ServerManager) cleanupInactiveDevices() {
 	if len(activeDevices) != len(sm.connectedDevices) {
 		removed := len(sm.connectedDevices) - len(activeDevices)
 		sm.connectedDevices = activeDevices
-		log.Printf("📱 Cleaned up %d inactive devices", removed)
+		logger.Info("Removed inactive devices", "count", removed)
 	}
 }
 
-// parseDeviceName extracts device info from user agent
-func (sm *ServerManager) parseDeviceName(userAgent string) string {
-	if userAgent == "" {
-		return "Unknown Device"
-	}
-	
-	// Simple parsing to extract device info from user agent
-	switch {
-	case contains(userAgent, "Android"):
-		return "Android Device"
-	case contains(userAgent, "iPhone"):
-		return "iPhone"
-	case contains(userAgent, "iPad"):
-		return "iPad"
-	case contains(userAgent, "Mac"):
-		return "Mac"
-	case contains(userAgent, "BMA"):
-		return "BMA App"
-	default:
-		return "Unknown Device"
-	}
-}
-
-// Helper function for string contains check
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && 
-		   (s == substr || 
-		    (len(s) > len(substr) && 
-		     (s[:len(substr)] == substr || 
-		      s[len(s)-len(substr):] == substr || 
-		      containsAtIndex(s, substr))))
-}
-
-func containsAtIndex(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
-	}
-	return false
-}
-
 // Token management methods
 
 // GeneratePairingToken creates a new pairing token with expiration
-func (sm *ServerManager) GeneratePairingToken(expiresInMinutes int) string {
+func (sm *ServerManager) GeneratePairingToken(validFor time.Duration) (string, time.Time, error) {
 	sm.tokensMutex.Lock()
 	defer sm.tokensMutex.Unlock()
 	
-	token := uuid.New().String()
-	expiration := time.Now().Add(time.Duration(expiresInMinutes) * time.Minute)
-	
-	sm.pairingTokens[token] = expiration
-	sm.currentPairingToken = token
-	
-	// Clean up expired tokens
-	sm.cleanupExpiredTokensUnsafe()
+	device, err := sm.pairingTokens.IssueToken(validFor)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	sm.currentPairingToken = device.Token
 	
-	log.Printf("🔑 Generated pairing token: %s... (expires in %d minutes)", token[:8], expiresInMinutes)
-	return token
+	logger.Info("Pairing token issued", "token", device.Token[:8]+"...", "valid_for", validFor)
+	return device.Token, device.ExpiresAt, nil
 }
 
 // IsValidToken checks if a token is valid and not expired
 func (sm *ServerManager) IsValidToken(token string) bool {
-	sm.tokensMutex.RLock()
-	defer sm.tokensMutex.RUnlock()
-	
-	expiration, exists := sm.pairingTokens[token]
-	if !exists {
-		return false
-	}
-	
-	// Check if token is expired
-	if time.Now().After(expiration) {
-		// Remove expired token (but need to upgrade to write lock)
-		sm.tokensMutex.RUnlock()
-		sm.tokensMutex.Lock()
-		delete(sm.pairingTokens, token)
-		if sm.currentPairingToken == token {
-			sm.currentPairingToken = ""
-		}
-		sm.tokensMutex.Unlock()
-		sm.tokensMutex.RLock()
-		return false
-	}
-	
-	return true
+	return sm.pairingTokens.IsValidToken(token)
 }
 
 // revokePairingToken removes a specific token
@@ -405,34 +354,21 @@ func (sm *ServerManager) revokePairingToken(token string) {
 	sm.tokensMutex.Lock()
 	defer sm.tokensMutex.Unlock()
 	
-	delete(sm.pairingTokens, token)
+	sm.pairingTokens.Revoke(token)
 	if sm.currentPairingToken == token {
 		sm.currentPairingToken = ""
 	}
-	log.Printf("🔒 Revoked pairing token: %s...", token[:8])
+	logger.Info("Pairing token revoked", "token", token[:8]+"...")
 }
 
-// revokeAllTokens removes all tokens
-func (sm *ServerManager) revokeAllTokens() {
+// clearCurrentPairingToken forgets the token shown in the QR code. Paired devices keep their
+// tokens, so they still work after the server or the app restarts.
+func (sm *ServerManager) clearCurrentPairingToken() {
 	sm.tokensMutex.Lock()
 	defer sm.tokensMutex.Unlock()
 	
-	sm.pairingTokens = make(map[string]time.Time)
 	sm.currentPairingToken = ""
-	log.Println("🔒 All pairing tokens revoked")
-}
-
-// cleanupExpiredTokensUnsafe removes expired tokens (assumes write lock held)
-func (sm *ServerManager) cleanupExpiredTokensUnsafe() {
-	now := time.Now()
-	for token, expiration := range sm.pairingTokens {
-		if now.After(expiration) {
-			delete(sm.pairingTokens, token)
-			if sm.currentPairingToken == token {
-				sm.currentPairingToken = ""
-			}
-		}
-	}
+	logger.Debug("Current pairing token cleared")
 }
 
 // GetCurrentPairingToken returns the current pairing token
@@ -448,13 +384,8 @@ func (sm *ServerManager) GetCurrentPairingToken() string {
 func (sm *ServerManager) setupRouter() {
 	sm.router = mux.NewRouter()
 	
-	// Add request logging middleware
-	sm.router.Use(sm.requestLoggingMiddleware)
-	
-	// Setup all routes
+	// Setup all routes, the API logs the requests
 	sm.setupRoutes()
-	
-	log.Println("✅ HTTP router configured with all endpoints")
 }
 
 // GetRouter returns the HTTP router (for use in UI)
@@ -470,59 +401,6 @@ func (sm *ServerManager) GetServerURL() string {
 	return fmt.Sprintf("http://%s:%d", sm.getLocalIPAddress(), sm.Port)
 }
 
-// Middleware
-
-// requestLoggingMiddleware logs all HTTP requests
-func (sm *ServerManager) requestLoggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		
-		// Extract client info
-		clientIP := r.RemoteAddr
-		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-			clientIP = forwarded
-		}
-		userAgent := r.Header.Get("User-Agent")
-		if userAgent == "" {
-			userAgent = "unknown"
-		}
-		
-		// Extract auth info
-		auth := "none"
-		if authHeader := r.Header.Get("Authorization"); authHeader != "" {
-			if len(authHeader) > 15 { // "Bearer " + some token
-				auth = authHeader[7:15] + "..." // Show first 8 chars after "Bearer "
-			}
-		}
-		
-		log.Printf("📥 [REQUEST] %s %s", r.Method, r.URL.Path)
-		log.Printf("   └─ From: %s", clientIP)
-		log.Printf("   └─ User-Agent: %s", userAgent)
-		log.Printf("   └─ Auth: %s", auth)
-		
-		// Wrap ResponseWriter to capture status code
-		wrapped := &responseWriter{ResponseWriter: w, statusCode: 200}
-		
-		// Call next handler
-		next.ServeHTTP(wrapped, r)
-		
-		// Log response
-		duration := time.Since(start)
-		log.Printf("📤 [RESPONSE] %d (%s)", wrapped.statusCode, duration)
-	})
-}
-
-// responseWriter wraps http.ResponseWriter to capture status code
-type responseWriter struct {
-	http.ResponseWriter
-	statusCode int
-}
-
-func (rw *responseWriter) WriteHeader(code int) {
-	rw.statusCode = code
-	rw.ResponseWriter.WriteHeader(code)
-}
-
 // QR Code Generation Methods
 
 // GenerateQRCode creates a QR code for device pairing
@@ -532,25 +410,23 @@ func (sm *ServerManager) GenerateQRCode() ([]byte, string, error) {
 	}
 	
 	// Generate a new pairing token (60 minutes expiration)
-	token := sm.GeneratePairingToken(60)
-	expiresAt := time.Now().Add(60 * time.Minute)
+	token, expiresAt, err := sm.GeneratePairingToken(60 * time.Minute)
+	if err != nil {
+		return nil, "", err
+	}
 	serverURL := sm.GetPreferredURL()
 	
-	log.Printf("🔑 Generating QR code for URL: %s", serverURL)
-	log.Printf("🔑 Token: %s... (expires in 60 minutes)", token[:8])
+	logger.Debug("Generating pairing QR code", "url", serverURL)
 	
 	// Create QR generator and generate code
-	qrGen := models.NewQRCodeGenerator()
+	qrGen := pairing.NewQRCodeGenerator()
 	qrBytes, err := qrGen.GeneratePairingQR(serverURL, token, expiresAt)
 	if err != nil {
-		log.Printf("❌ QR code generation failed: %v", err)
+		logger.Error("Failed to generate QR code", "err", err)
 		return nil, "", err
 	}
 	
-	// Also get JSON for logging/debugging
+	// The JSON is shown next to the QR code for manual pairing
 	jsonData, _ := qrGen.GetPairingDataJSON(serverURL, token, expiresAt)
-	log.Printf("📋 QR Code contains:\n%s", jsonData)
-	
-	log.Printf("✅ QR code generated successfully (%d bytes)", len(qrBytes))
 	return qrBytes, jsonData, nil
 }
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bma-core/metrics"
+	"bma-core/pairing"
+)
+
+// newTestServerManager creates a server manager on a free port, keeping its devices in a temporary home
+func newTestServerManager(t *testing.T) *ServerManager {
+	t.Helper()
+	store, err := pairing.LoadDeviceStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return &ServerManager{
+		Port:          0,
+		pairingTokens: store,
+		metrics:       metrics.NewRegistry(),
+		metricsAddr:   "127.0.0.1:0",
+		ctx:           ctx,
+		cancelFunc:    cancel,
+	}
+}
+
+func TestPairingsSurviveRestart(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	sm := newTestServerManager(t)
+	if err := sm.StartServer(); err != nil {
+		t.Fatal(err)
+	}
+	used, _, err := sm.GeneratePairingToken(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.Authenticate(used, "192.168.1.20", "BMA Android"); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	unused, _, err := sm.GeneratePairingToken(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sm.StopServer(); err != nil {
+		t.Fatal(err)
+	}
+	if token := sm.GetCurrentPairingToken(); token != "" {
+		t.Errorf("current pairing token = %q after stop, want none", token)
+	}
+	if devices := sm.GetConnectedDevices(); len(devices) != 0 {
+		t.Errorf("%d connected devices after stop, want none", len(devices))
+	}
+
+	// The next start of the app reads the same devices.json
+	restarted := newTestServerManager(t)
+	for _, token := range []string{used, unused} {
+		if err := restarted.pairingTokens.ValidateToken(token); err != nil {
+			t.Errorf("token %s... after restart: %v", token[:8], err)
+		}
+	}
+}
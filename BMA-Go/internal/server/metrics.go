@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"bma-core/metrics"
+)
+
+// registerMetrics adds the device and Tailscale gauges. The API handler adds the request,
+// streaming and library metrics to the same registry.
+func (sm *ServerManager) registerMetrics() {
+	sm.metrics.GaugeFunc("bma_paired_devices", "Devices with a pairing token that has not expired", func() float64 {
+		paired := 0
+		for _, device := range sm.pairingTokens.List() {
+			if !device.IsExpired() {
+				paired++
+			}
+		}
+		return float64(paired)
+	})
+	sm.metrics.GaugeFunc("bma_connected_devices", "Paired devices that made a request in the last 10 minutes", func() float64 {
+		return float64(len(sm.GetConnectedDevices()))
+	})
+	sm.metrics.GaugeFunc("bma_tailscale_up", "1 if the server is reachable over Tailscale", func() float64 {
+		return metrics.Bool(sm.IsTailscaleConfigured())
+	})
+}
+
+// startMetricsServer serves /metrics on the configured metrics address, apart from the API
+func (sm *ServerManager) startMetricsServer() {
+	router := http.NewServeMux()
+	router.Handle("/metrics", sm.metrics.Handler())
+	sm.metricsServer = &http.Server{
+		Addr:    sm.metricsAddr,
+		Handler: router,
+	}
+
+	go func(server *http.Server) {
+		logger.Info("Metrics server listening", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("Metrics server failed", "addr", server.Addr, "err", err)
+		}
+	}(sm.metricsServer)
+}
+
+// stopMetricsServer stops the metrics listener, if one was started
+func (sm *ServerManager) stopMetricsServer(ctx context.Context) {
+	if sm.metricsServer == nil {
+		return
+	}
+	if err := sm.metricsServer.Shutdown(ctx); err != nil {
+		logger.Warn("Failed to stop the metrics server", "err", err)
+	}
+	sm.metricsServer = nil
+}
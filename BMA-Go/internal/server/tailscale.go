@@ -3,12 +3,13 @@ package server
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
 	"os/user"
 	"strings"
 	"time"
+
+	"bma-core/logging"
 )
 
 // Tailscale Integration for BMA Go+Fyne
@@ -19,6 +20,9 @@ import (
 // - Shell command integration using os/exec
 // - Status monitoring and hostname resolution 
 
+// tailscaleLogger tags Tailscale's log lines with component=tailscale
+var tailscaleLogger = logging.Component("tailscale")
+
 // TailscaleManager handles Tailscale detection and integration
 type TailscaleManager struct {
 	isAvailable bool
@@ -37,7 +41,7 @@ func (sm *ServerManager) isRunningInFlatpak() bool {
 	cmd := exec.Command("sh", "-c", "echo $FLATPAK_ID")
 	output, err := cmd.Output()
 	if err == nil && strings.TrimSpace(string(output)) != "" {
-		log.Printf("🔍 [FLATPAK] Running in Flatpak: %s", strings.TrimSpace(string(output)))
+		tailscaleLogger.Debug("Running in Flatpak", "flatpak_id", strings.TrimSpace(string(output)))
 		return true
 	}
 	return false
@@ -48,7 +52,7 @@ func (sm *ServerManager) executeCommand(name string, args ...string) *exec.Cmd {
 	if sm.useFlatpakSpawn && (name == "tailscale" || strings.HasSuffix(name, "/tailscale")) {
 		// Prepend flatpak-spawn --host for tailscale commands (whether path or just "tailscale")
 		flatpakArgs := append([]string{"--host", "tailscale"}, args...)
-		log.Printf("🔧 [FLATPAK] Using flatpak-spawn: flatpak-spawn %v", flatpakArgs)
+		tailscaleLogger.Debug("Running tailscale through flatpak-spawn", "args", flatpakArgs)
 		return exec.Command("flatpak-spawn", flatpakArgs...)
 	}
 	return exec.Command(name, args...)
@@ -56,101 +60,92 @@ func (sm *ServerManager) executeCommand(name string, args ...string) *exec.Cmd {
 
 // debugFlatpakHostAccess performs comprehensive flatpak-spawn testing and diagnostics
 func (sm *ServerManager) debugFlatpakHostAccess() {
-	log.Println("🔧 [FLATPAK DEBUG] Testing flatpak-spawn host access capabilities...")
+	tailscaleLogger.Debug("Testing flatpak-spawn host access")
 	
 	// Test 1: Basic flatpak-spawn functionality
-	log.Println("🔧 [FLATPAK DEBUG] Test 1: Basic flatpak-spawn functionality")
 	testCmd := exec.Command("flatpak-spawn", "--host", "echo", "flatpak-spawn works")
 	if output, err := testCmd.CombinedOutput(); err == nil {
-		log.Printf("✅ [FLATPAK DEBUG] Basic test passed: %s", strings.TrimSpace(string(output)))
+		tailscaleLogger.Debug("flatpak-spawn works", "output", strings.TrimSpace(string(output)))
 	} else {
-		log.Printf("❌ [FLATPAK DEBUG] Basic test failed: %v", err)
-		log.Printf("❌ [FLATPAK DEBUG] Output: %s", string(output))
+		tailscaleLogger.Debug("flatpak-spawn failed", "err", err, "output", strings.TrimSpace(string(output)))
 		return // If basic functionality fails, no point continuing
 	}
 	
 	// Test 2: Host environment visibility
-	log.Println("🔧 [FLATPAK DEBUG] Test 2: Host environment analysis")
 	envCmd := exec.Command("flatpak-spawn", "--host", "env")
 	if output, err := envCmd.CombinedOutput(); err == nil {
-		log.Printf("✅ [FLATPAK DEBUG] Host environment accessible")
+		tailscaleLogger.Debug("Host environment accessible")
 		// Look for PATH in environment
 		envLines := strings.Split(string(output), "\n")
 		for _, line := range envLines {
 			if strings.HasPrefix(line, "PATH=") {
-				log.Printf("🔧 [FLATPAK DEBUG] Host PATH: %s", line)
+				tailscaleLogger.Debug("Host PATH", "path", line)
 				break
 			}
 		}
 	} else {
-		log.Printf("❌ [FLATPAK DEBUG] Host environment test failed: %v", err)
+		tailscaleLogger.Debug("Host environment not accessible", "err", err)
 	}
 	
 	// Test 3: Host user context
-	log.Println("🔧 [FLATPAK DEBUG] Test 3: Host user context")
 	whoamiCmd := exec.Command("flatpak-spawn", "--host", "whoami")
 	if output, err := whoamiCmd.CombinedOutput(); err == nil {
-		log.Printf("✅ [FLATPAK DEBUG] Host user: %s", strings.TrimSpace(string(output)))
+		tailscaleLogger.Debug("Host user", "user", strings.TrimSpace(string(output)))
 	} else {
-		log.Printf("❌ [FLATPAK DEBUG] whoami test failed: %v", err)
+		tailscaleLogger.Debug("Host whoami failed", "err", err)
 	}
 	
 	// Test 4: Try to find tailscale via different methods on host
-	log.Println("🔧 [FLATPAK DEBUG] Test 4: Tailscale discovery on host")
 	
 	// Try which command on host
 	whichCmd := exec.Command("flatpak-spawn", "--host", "which", "tailscale")
 	if output, err := whichCmd.CombinedOutput(); err == nil {
 		hostTailscalePath := strings.TrimSpace(string(output))
-		log.Printf("✅ [FLATPAK DEBUG] Host 'which tailscale': %s", hostTailscalePath)
+		tailscaleLogger.Debug("Host tailscale found", "path", hostTailscalePath)
 		
 		// Test the found path
 		versionCmd := exec.Command("flatpak-spawn", "--host", hostTailscalePath, "version")
 		if versionOutput, versionErr := versionCmd.CombinedOutput(); versionErr == nil {
-			log.Printf("✅ [FLATPAK DEBUG] Host tailscale version: %s", strings.TrimSpace(string(versionOutput)))
+			tailscaleLogger.Debug("Host tailscale version", "version", strings.TrimSpace(string(versionOutput)))
 			sm.useFlatpakSpawn = true
 			return
 		} else {
-			log.Printf("❌ [FLATPAK DEBUG] Host tailscale version failed: %v", versionErr)
-			log.Printf("❌ [FLATPAK DEBUG] Version output: %s", string(versionOutput))
+			tailscaleLogger.Debug("Host tailscale version failed", "err", versionErr, "output", strings.TrimSpace(string(versionOutput)))
 		}
 	} else {
-		log.Printf("❌ [FLATPAK DEBUG] Host 'which tailscale' failed: %v", err)
-		log.Printf("❌ [FLATPAK DEBUG] Which output: %s", string(output))
+		tailscaleLogger.Debug("Host which tailscale failed", "err", err, "output", strings.TrimSpace(string(output)))
 	}
 	
 	// Try locate command on host
 	locateCmd := exec.Command("flatpak-spawn", "--host", "locate", "tailscale")
 	if output, err := locateCmd.CombinedOutput(); err == nil && strings.TrimSpace(string(output)) != "" {
-		log.Printf("✅ [FLATPAK DEBUG] Host 'locate tailscale':\n%s", string(output))
+		tailscaleLogger.Debug("Host locate tailscale", "output", strings.TrimSpace(string(output)))
 	} else {
-		log.Printf("❌ [FLATPAK DEBUG] Host 'locate tailscale' failed: %v", err)
+		tailscaleLogger.Debug("Host locate tailscale failed", "err", err)
 	}
 	
 	// Try find command on host (search /usr/bin specifically)
 	findCmd := exec.Command("flatpak-spawn", "--host", "find", "/usr/bin", "-name", "*tailscale*", "-type", "f")
 	if output, err := findCmd.CombinedOutput(); err == nil && strings.TrimSpace(string(output)) != "" {
-		log.Printf("✅ [FLATPAK DEBUG] Host find results:\n%s", string(output))
+		tailscaleLogger.Debug("Host find tailscale", "output", strings.TrimSpace(string(output)))
 	} else {
-		log.Printf("❌ [FLATPAK DEBUG] Host find failed: %v", err)
+		tailscaleLogger.Debug("Host find tailscale failed", "err", err)
 	}
 	
 	// Test 5: Try direct tailscale command with full error capture
-	log.Println("🔧 [FLATPAK DEBUG] Test 5: Direct tailscale command test")
 	directCmd := exec.Command("flatpak-spawn", "--host", "tailscale", "version")
 	if output, err := directCmd.CombinedOutput(); err == nil {
-		log.Printf("✅ [FLATPAK DEBUG] Direct 'tailscale version' works: %s", strings.TrimSpace(string(output)))
+		tailscaleLogger.Debug("Host tailscale version works", "version", strings.TrimSpace(string(output)))
 		sm.useFlatpakSpawn = true
 	} else {
-		log.Printf("❌ [FLATPAK DEBUG] Direct 'tailscale version' failed: %v", err)
-		log.Printf("❌ [FLATPAK DEBUG] Full output: %s", string(output))
+		tailscaleLogger.Debug("Host tailscale version failed", "err", err, "output", strings.TrimSpace(string(output)))
 		sm.useFlatpakSpawn = false
 	}
 	
 	if sm.useFlatpakSpawn {
-		log.Println("✅ [FLATPAK DEBUG] Flatpak-spawn mode enabled - Tailscale accessible on host")
+		tailscaleLogger.Info("Tailscale is reachable on the host through flatpak-spawn")
 	} else {
-		log.Println("❌ [FLATPAK DEBUG] Flatpak-spawn mode disabled - falling back to sandbox detection")
+		tailscaleLogger.Info("Tailscale is not reachable through flatpak-spawn, falling back to sandbox detection")
 	}
 }
 
@@ -193,52 +188,47 @@ func (sm *ServerManager) expandSystemPath(currentPath string) string {
 
 // debugEnvironment shows what the Go app can see for debugging
 func (sm *ServerManager) debugEnvironment() {
-	log.Println("🔧 [DEBUG] Environment Analysis:")
+	tailscaleLogger.Debug("Analysing the environment")
 	
 	// Show PATH
 	originalPath := os.Getenv("PATH")
 	if originalPath != "" {
-		log.Printf("🔧 [DEBUG] Original PATH: %s", originalPath)
-	} else {
-		log.Println("🔧 [DEBUG] Original PATH: (empty)")
+		tailscaleLogger.Debug("Original PATH", "path", originalPath)
 	}
 	
 	// Expand PATH to include common system directories
 	expandedPath := sm.expandSystemPath(originalPath)
-	log.Printf("🔧 [DEBUG] Expanded PATH: %s", expandedPath)
+	tailscaleLogger.Debug("Expanded PATH", "path", expandedPath)
 	os.Setenv("PATH", expandedPath)
 	
 	// Show current user
 	if usr, err := user.Current(); err == nil {
-		log.Printf("🔧 [DEBUG] User: %s (UID: %s, GID: %s, Home: %s)", usr.Username, usr.Uid, usr.Gid, usr.HomeDir)
+		tailscaleLogger.Debug("Current user", "user", usr.Username, "uid", usr.Uid, "gid", usr.Gid, "home", usr.HomeDir)
 	}
 	
 	// Test direct tailscale command (after PATH expansion)
-	log.Println("🔧 [DEBUG] Testing direct 'tailscale status' command (with expanded PATH):")
 	cmd := sm.executeCommand("tailscale", "status")
 	output, err := cmd.Output()
 	if err != nil {
-		log.Printf("🔧 [DEBUG] 'tailscale status' still failed: %v", err)
+		tailscaleLogger.Debug("tailscale status failed, trying absolute paths", "err", err)
 		
 		// Try with absolute paths
-		log.Println("🔧 [DEBUG] Trying absolute paths for tailscale:")
 		absolutePaths := []string{"/usr/bin/tailscale", "/bin/tailscale", "/usr/sbin/tailscale", "/sbin/tailscale", "/usr/local/bin/tailscale", "/snap/bin/tailscale"}
 		for _, path := range absolutePaths {
 			cmd = exec.Command(path, "status")
 			output, err = cmd.Output()
 			if err == nil {
-				log.Printf("🔧 [DEBUG] SUCCESS with '%s': %s", path, string(output))
+				tailscaleLogger.Debug("tailscale status works", "path", path, "output", strings.TrimSpace(string(output)))
 				break
 			} else {
-				log.Printf("🔧 [DEBUG] Failed with '%s': %v", path, err)
+				tailscaleLogger.Debug("tailscale status failed", "path", path, "err", err)
 			}
 		}
 	} else {
-		log.Printf("🔧 [DEBUG] SUCCESS - 'tailscale status' output: %s", string(output))
+		tailscaleLogger.Debug("tailscale status works", "output", strings.TrimSpace(string(output)))
 	}
 	
 	// Show all network interfaces
-	log.Println("🔧 [DEBUG] Network interfaces:")
 	
 	// Try ip command with absolute paths
 	ipPaths := []string{"/usr/bin/ip", "/bin/ip", "/sbin/ip", "/usr/sbin/ip"}
@@ -247,102 +237,97 @@ func (sm *ServerManager) debugEnvironment() {
 		cmd = exec.Command(ipPath, "addr", "show")
 		output, err = cmd.Output()
 		if err == nil {
-			log.Printf("🔧 [DEBUG] SUCCESS with '%s addr show':\n%s", ipPath, string(output))
+			tailscaleLogger.Debug("Network interfaces", "command", ipPath+" addr show", "output", strings.TrimSpace(string(output)))
 			ipWorked = true
 			break
 		}
 	}
 	
 	if !ipWorked {
-		log.Println("🔧 [DEBUG] All 'ip' paths failed, trying 'ifconfig':")
+		tailscaleLogger.Debug("ip not found, trying ifconfig")
 		// Try ifconfig with absolute paths
 		ifconfigPaths := []string{"/usr/bin/ifconfig", "/bin/ifconfig", "/sbin/ifconfig", "/usr/sbin/ifconfig"}
 		for _, ifconfigPath := range ifconfigPaths {
 			cmd = exec.Command(ifconfigPath)
 			output, err = cmd.Output()
 			if err == nil {
-				log.Printf("🔧 [DEBUG] SUCCESS with '%s':\n%s", ifconfigPath, string(output))
+				tailscaleLogger.Debug("Network interfaces", "command", ifconfigPath, "output", strings.TrimSpace(string(output)))
 				break
 			}
 		}
 	}
 	
 	// Show which/whereis results
-	log.Println("🔧 [DEBUG] Binary location tests:")
 	for _, cmd := range []string{"which tailscale", "whereis tailscale", "type tailscale"} {
 		parts := strings.Fields(cmd)
 		execCmd := exec.Command(parts[0], parts[1:]...)
 		output, err := execCmd.Output()
 		if err != nil {
-			log.Printf("🔧 [DEBUG] '%s' failed: %v", cmd, err)
+			tailscaleLogger.Debug("Binary lookup failed", "command", cmd, "err", err)
 		} else {
-			log.Printf("🔧 [DEBUG] '%s' output: %s", cmd, strings.TrimSpace(string(output)))
+			tailscaleLogger.Debug("Binary lookup", "command", cmd, "output", strings.TrimSpace(string(output)))
 		}
 	}
 	
 	// Aggressive system-wide search for tailscale
-	log.Println("🔧 [DEBUG] Performing aggressive system-wide tailscale search:")
 	sm.aggressiveTailscaleSearch()
 	
-	log.Println("🔧 [DEBUG] Environment analysis complete")
+	tailscaleLogger.Debug("Environment analysis complete")
 }
 
 // aggressiveTailscaleSearch performs system-wide search for tailscale binary
 func (sm *ServerManager) aggressiveTailscaleSearch() {
-	log.Println("🔧 [DEBUG] Starting aggressive search...")
+	tailscaleLogger.Debug("Searching the filesystem for tailscale")
 	
 	// Try find command on entire filesystem
-	log.Println("🔧 [DEBUG] Searching entire filesystem with find:")
 	cmd := exec.Command("sh", "-c", "find / -name '*tailscale*' -type f -executable 2>/dev/null | head -20")
 	output, err := cmd.Output()
 	if err == nil && strings.TrimSpace(string(output)) != "" {
-		log.Printf("🔧 [DEBUG] Found tailscale-related files:\n%s", string(output))
+		tailscaleLogger.Debug("Found tailscale related files", "files", strings.TrimSpace(string(output)))
 		
 		// Test each found binary
 		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 		for _, line := range lines {
 			line = strings.TrimSpace(line)
 			if line != "" && strings.Contains(line, "tailscale") && !strings.Contains(line, ".") {
-				log.Printf("🔧 [DEBUG] Testing found binary: %s", line)
+				tailscaleLogger.Debug("Testing binary", "path", line)
 				testCmd := exec.Command(line, "version")
 				testOutput, testErr := testCmd.Output()
 				if testErr == nil && strings.Contains(strings.ToLower(string(testOutput)), "tailscale") {
-					log.Printf("🔧 [DEBUG] SUCCESS! Working tailscale found at: %s", line)
-					log.Printf("🔧 [DEBUG] Version output: %s", string(testOutput))
+					tailscaleLogger.Debug("Working tailscale found", "path", line, "version", strings.TrimSpace(string(testOutput)))
 					
 					// Try status command
 					statusCmd := exec.Command(line, "status")
 					statusOutput, statusErr := statusCmd.Output()
 					if statusErr == nil {
-						log.Printf("🔧 [DEBUG] Status command works: %s", string(statusOutput))
+						tailscaleLogger.Debug("tailscale status works", "output", strings.TrimSpace(string(statusOutput)))
 					} else {
-						log.Printf("🔧 [DEBUG] Status command failed: %v", statusErr)
+						tailscaleLogger.Debug("tailscale status failed", "err", statusErr)
 					}
 					return
 				}
 			}
 		}
 	} else {
-		log.Printf("🔧 [DEBUG] Find command failed or no results: %v", err)
+		tailscaleLogger.Debug("Filesystem search found nothing", "err", err)
 	}
 	
 	// Try alternative search methods
-	log.Println("🔧 [DEBUG] Trying alternative search methods:")
 	
 	// Check if running from flatpak
 	cmd = exec.Command("sh", "-c", "echo $FLATPAK_ID")
 	output, err = cmd.Output()
 	if err == nil && strings.TrimSpace(string(output)) != "" {
-		log.Printf("🔧 [DEBUG] Running in Flatpak: %s", strings.TrimSpace(string(output)))
+		tailscaleLogger.Debug("Running in Flatpak", "flatpak_id", strings.TrimSpace(string(output)))
 		
 		// Try flatpak-spawn for host access
 		cmd = exec.Command("flatpak-spawn", "--host", "tailscale", "status")
 		output, err = cmd.Output()
 		if err == nil {
-			log.Printf("🔧 [DEBUG] SUCCESS via flatpak-spawn: %s", string(output))
+			tailscaleLogger.Debug("tailscale status works through flatpak-spawn", "output", strings.TrimSpace(string(output)))
 			return
 		} else {
-			log.Printf("🔧 [DEBUG] flatpak-spawn failed: %v", err)
+			tailscaleLogger.Debug("flatpak-spawn failed", "err", err)
 		}
 	}
 	
@@ -350,26 +335,32 @@ func (sm *ServerManager) aggressiveTailscaleSearch() {
 	cmd = exec.Command("sh", "-c", "cat /proc/1/cgroup 2>/dev/null | grep -q docker && echo 'docker' || echo 'not-docker'")
 	output, err = cmd.Output()
 	if err == nil && strings.Contains(string(output), "docker") {
-		log.Println("🔧 [DEBUG] Running in Docker container")
+		tailscaleLogger.Debug("Running in a Docker container")
 	}
 	
-	log.Println("🔧 [DEBUG] Aggressive search complete")
+	tailscaleLogger.Debug("Filesystem search complete")
 }
 
 // checkTailscaleStatus detects Tailscale installation and status
 func (sm *ServerManager) checkTailscaleStatus() {
-	log.Println("🔍 Starting Tailscale detection...")
+	tailscaleLogger.Debug("Detecting Tailscale")
+	
+	// The embedded node replaces all host binary detection
+	sm.tailscaleMutex.Lock()
+	embeddedMode := sm.embeddedMode
+	sm.tailscaleMutex.Unlock()
+	if embeddedMode {
+		sm.checkEmbeddedTailscaleStatus()
+		return
+	}
 	
 	// Check for Flatpak environment first
 	if sm.isRunningInFlatpak() {
-		log.Println("🔍 [FLATPAK] Flatpak environment detected, running comprehensive host access tests")
+		tailscaleLogger.Info("Flatpak detected, testing host access")
 		sm.debugFlatpakHostAccess()
 		
 		// Skip old debugging if Flatpak detection succeeded
-		if sm.useFlatpakSpawn {
-			log.Println("🔍 [FLATPAK] Skipping sandbox debugging - flatpak-spawn working")
-		} else {
-			log.Println("🔍 [FLATPAK] Flatpak-spawn failed, falling back to sandbox debugging")
+		if !sm.useFlatpakSpawn {
 			sm.debugEnvironment()
 		}
 	} else {
@@ -382,41 +373,41 @@ func (sm *ServerManager) checkTailscaleStatus() {
 	
 	// If binary not found, try service/daemon detection
 	if tailscalePath == "" {
-		log.Println("⚠️ Tailscale binary not found, trying service/daemon detection...")
+		tailscaleLogger.Debug("Tailscale binary not found, checking for the service")
 		if sm.detectTailscaleService() {
 			// Service is running, try to find binary again with fallback methods
 			if fallbackPath := sm.findTailscaleWithFallbacks(); fallbackPath != "" {
 				tailscalePath = fallbackPath
-				log.Printf("✅ Found Tailscale via service detection: %s", tailscalePath)
+				tailscaleLogger.Debug("Found Tailscale through the service", "path", tailscalePath)
 			} else {
-				log.Println("❌ Tailscale service detected but no usable binary found")
+				tailscaleLogger.Warn("Tailscale service is running but no usable binary was found")
 				sm.HasTailscale = false
 				sm.TailscaleURL = ""
 				return
 			}
 		} else {
-			log.Println("⚠️ Tailscale service not detected, trying network interface detection...")
+			tailscaleLogger.Debug("Tailscale service not found, checking the network interfaces")
 			if sm.detectTailscaleNetwork() {
 				// Network interface detected, try to find ANY tailscale binary as final attempt
 				if networkPath := sm.findAnyTailscaleBinary(); networkPath != "" {
 					tailscalePath = networkPath
-					log.Printf("✅ Found Tailscale via network detection: %s", tailscalePath)
+					tailscaleLogger.Debug("Found Tailscale through the network", "path", tailscalePath)
 				} else {
 					// Even without binary, we can try to use Tailscale via network
-					log.Println("⚠️ Tailscale network detected but no binary found - will try IP-based connection")
+					tailscaleLogger.Debug("Tailscale network found without a binary, trying its IP")
 					if tailscaleIP := sm.getTailscaleIPFromNetwork(); tailscaleIP != "" {
 						sm.HasTailscale = true
 						sm.TailscaleURL = fmt.Sprintf("http://%s", tailscaleIP)
-						log.Printf("✅ Tailscale configured via network IP: %s", sm.TailscaleURL)
+						tailscaleLogger.Info("Tailscale configured from the network IP", "url", sm.TailscaleURL)
 						return
 					}
-					log.Println("❌ Could not determine Tailscale IP from network")
+					tailscaleLogger.Warn("Could not determine the Tailscale IP from the network")
 					sm.HasTailscale = false
 					sm.TailscaleURL = ""
 					return
 				}
 			} else {
-				log.Println("❌ Tailscale not detected - binary, service, and network not found")
+				tailscaleLogger.Info("Tailscale not detected")
 				sm.HasTailscale = false
 				sm.TailscaleURL = ""
 				return
@@ -424,7 +415,7 @@ func (sm *ServerManager) checkTailscaleStatus() {
 		}
 	}
 	
-	log.Printf("✅ Found Tailscale binary at: %s", tailscalePath)
+	tailscaleLogger.Debug("Found Tailscale binary", "path", tailscalePath)
 	
 	// Check if Tailscale is actually connected
 	if sm.checkTailscaleConnection(tailscalePath) {
@@ -432,14 +423,14 @@ func (sm *ServerManager) checkTailscaleStatus() {
 		if hostname := sm.getTailscaleHostname(tailscalePath); hostname != "" {
 			sm.HasTailscale = true
 			sm.TailscaleURL = fmt.Sprintf("http://%s", hostname)
-			log.Printf("✅ Tailscale configured: %s", sm.TailscaleURL)
+			tailscaleLogger.Info("Tailscale configured", "url", sm.TailscaleURL)
 		} else {
-			log.Println("❌ Failed to get Tailscale hostname")
+			tailscaleLogger.Warn("Failed to get the Tailscale hostname")
 			sm.HasTailscale = false
 			sm.TailscaleURL = ""
 		}
 	} else {
-		log.Println("❌ Tailscale is not in Running state")
+		tailscaleLogger.Info("Tailscale is not connected")
 		sm.HasTailscale = false
 		sm.TailscaleURL = ""
 	}
@@ -447,7 +438,7 @@ func (sm *ServerManager) checkTailscaleStatus() {
 
 // findTailscaleInPath uses which/where command to find tailscale in PATH
 func (sm *ServerManager) findTailscaleInPath() string {
-	log.Println("🔍 [TAILSCALE DEBUG] Checking PATH for tailscale binary...")
+	tailscaleLogger.Debug("Looking for tailscale in PATH")
 	
 	// Try 'which' command (Unix/Linux/macOS)
 	cmd := exec.Command("which", "tailscale")
@@ -455,7 +446,7 @@ func (sm *ServerManager) findTailscaleInPath() string {
 	if err == nil {
 		path := strings.TrimSpace(string(output))
 		if path != "" && sm.isTailscaleBinaryValid(path) {
-			log.Printf("✅ [TAILSCALE DEBUG] Found tailscale in PATH via 'which': %s", path)
+			tailscaleLogger.Debug("Found tailscale in PATH", "path", path, "via", "which")
 			return path
 		}
 	}
@@ -468,7 +459,7 @@ func (sm *ServerManager) findTailscaleInPath() string {
 		for _, path := range lines {
 			path = strings.TrimSpace(path)
 			if path != "" && sm.isTailscaleBinaryValid(path) {
-				log.Printf("✅ [TAILSCALE DEBUG] Found tailscale in PATH via 'where': %s", path)
+				tailscaleLogger.Debug("Found tailscale in PATH", "path", path, "via", "where")
 				return path
 			}
 		}
@@ -477,12 +468,12 @@ func (sm *ServerManager) findTailscaleInPath() string {
 	// Try direct PATH lookup
 	if path, err := exec.LookPath("tailscale"); err == nil {
 		if sm.isTailscaleBinaryValid(path) {
-			log.Printf("✅ [TAILSCALE DEBUG] Found tailscale via LookPath: %s", path)
+			tailscaleLogger.Debug("Found tailscale in PATH", "path", path, "via", "LookPath")
 			return path
 		}
 	}
 	
-	log.Println("❌ [TAILSCALE DEBUG] Tailscale not found in PATH")
+	tailscaleLogger.Debug("tailscale not found in PATH")
 	return ""
 }
 
@@ -505,14 +496,13 @@ func (sm *ServerManager) expandUserPaths(paths []string) []string {
 		}
 	}
 	
-	log.Printf("🔍 [TAILSCALE DEBUG] Using home directory: %s", homeDir)
+	tailscaleLogger.Debug("Using home directory", "home", homeDir)
 	
 	for _, path := range paths {
 		if strings.HasPrefix(path, "~/") && homeDir != "" {
 			// Expand ~ to home directory
 			expandedPath := strings.Replace(path, "~", homeDir, 1)
 			expandedPaths = append(expandedPaths, expandedPath)
-			log.Printf("🔍 [TAILSCALE DEBUG] Expanded %s -> %s", path, expandedPath)
 		} else {
 			// Keep original path
 			expandedPaths = append(expandedPaths, path)
@@ -524,7 +514,7 @@ func (sm *ServerManager) expandUserPaths(paths []string) []string {
 
 // detectTailscaleService checks if Tailscale service/daemon is running
 func (sm *ServerManager) detectTailscaleService() bool {
-	log.Println("🔍 [SERVICE DEBUG] Checking for Tailscale service/daemon...")
+	tailscaleLogger.Debug("Checking for the Tailscale service")
 	
 	// Check systemd service (Linux)
 	if sm.checkSystemdService() {
@@ -546,19 +536,17 @@ func (sm *ServerManager) detectTailscaleService() bool {
 		return true
 	}
 	
-	log.Println("❌ [SERVICE DEBUG] No Tailscale service detected")
+	tailscaleLogger.Debug("No Tailscale service detected")
 	return false
 }
 
 // checkSystemdService checks Linux systemd service
 func (sm *ServerManager) checkSystemdService() bool {
-	log.Println("🔍 [SERVICE DEBUG] Checking systemd service...")
-	
 	// Check if tailscaled service is active
 	cmd := exec.Command("systemctl", "is-active", "tailscaled")
 	output, err := cmd.Output()
 	if err == nil && strings.TrimSpace(string(output)) == "active" {
-		log.Println("✅ [SERVICE DEBUG] tailscaled systemd service is active")
+		tailscaleLogger.Debug("tailscaled systemd service is active")
 		return true
 	}
 	
@@ -566,23 +554,20 @@ func (sm *ServerManager) checkSystemdService() bool {
 	cmd = exec.Command("systemctl", "status", "tailscaled")
 	err = cmd.Run()
 	if err == nil {
-		log.Println("✅ [SERVICE DEBUG] tailscaled systemd service exists")
+		tailscaleLogger.Debug("tailscaled systemd service exists")
 		return true
 	}
 	
-	log.Println("❌ [SERVICE DEBUG] No systemd tailscaled service found")
 	return false
 }
 
 // checkTailscaleProcess checks if tailscaled process is running
 func (sm *ServerManager) checkTailscaleProcess() bool {
-	log.Println("🔍 [SERVICE DEBUG] Checking for tailscaled process...")
-	
 	// Try pgrep (Unix/Linux/macOS)
 	cmd := exec.Command("pgrep", "tailscaled")
 	output, err := cmd.Output()
 	if err == nil && strings.TrimSpace(string(output)) != "" {
-		log.Printf("✅ [SERVICE DEBUG] Found tailscaled process: %s", strings.TrimSpace(string(output)))
+		tailscaleLogger.Debug("Found tailscaled process", "pid", strings.TrimSpace(string(output)))
 		return true
 	}
 	
@@ -590,7 +575,7 @@ func (sm *ServerManager) checkTailscaleProcess() bool {
 	cmd = exec.Command("sh", "-c", "ps aux | grep tailscaled | grep -v grep")
 	output, err = cmd.Output()
 	if err == nil && strings.TrimSpace(string(output)) != "" {
-		log.Println("✅ [SERVICE DEBUG] Found tailscaled process via ps")
+		tailscaleLogger.Debug("Found tailscaled process", "via", "ps")
 		return true
 	}
 	
@@ -598,47 +583,40 @@ func (sm *ServerManager) checkTailscaleProcess() bool {
 	cmd = exec.Command("pidof", "tailscaled")
 	output, err = cmd.Output()
 	if err == nil && strings.TrimSpace(string(output)) != "" {
-		log.Printf("✅ [SERVICE DEBUG] Found tailscaled via pidof: %s", strings.TrimSpace(string(output)))
+		tailscaleLogger.Debug("Found tailscaled process", "pid", strings.TrimSpace(string(output)), "via", "pidof")
 		return true
 	}
 	
-	log.Println("❌ [SERVICE DEBUG] No tailscaled process found")
 	return false
 }
 
 // checkLaunchdService checks macOS launchd service
 func (sm *ServerManager) checkLaunchdService() bool {
-	log.Println("🔍 [SERVICE DEBUG] Checking launchd service...")
-	
 	cmd := exec.Command("launchctl", "list", "com.tailscale.ipnextension")
 	err := cmd.Run()
 	if err == nil {
-		log.Println("✅ [SERVICE DEBUG] Tailscale launchd service found")
+		tailscaleLogger.Debug("Tailscale launchd service found")
 		return true
 	}
 	
-	log.Println("❌ [SERVICE DEBUG] No Tailscale launchd service found")
 	return false
 }
 
 // checkWindowsService checks Windows service
 func (sm *ServerManager) checkWindowsService() bool {
-	log.Println("🔍 [SERVICE DEBUG] Checking Windows service...")
-	
 	cmd := exec.Command("sc", "query", "Tailscale")
 	output, err := cmd.Output()
 	if err == nil && strings.Contains(string(output), "RUNNING") {
-		log.Println("✅ [SERVICE DEBUG] Tailscale Windows service is running")
+		tailscaleLogger.Debug("Tailscale Windows service is running")
 		return true
 	}
 	
-	log.Println("❌ [SERVICE DEBUG] No Tailscale Windows service found")
 	return false
 }
 
 // findTailscaleWithFallbacks tries aggressive methods to find tailscale binary
 func (sm *ServerManager) findTailscaleWithFallbacks() string {
-	log.Println("🔍 [FALLBACK DEBUG] Trying aggressive binary detection...")
+	tailscaleLogger.Debug("Searching for the tailscale binary")
 	
 	// Try to find the binary via running process
 	if path := sm.findBinaryFromProcess(); path != "" {
@@ -660,14 +638,12 @@ func (sm *ServerManager) findTailscaleWithFallbacks() string {
 		return path
 	}
 	
-	log.Println("❌ [FALLBACK DEBUG] All fallback methods failed")
+	tailscaleLogger.Debug("tailscale binary search failed")
 	return ""
 }
 
 // findBinaryFromProcess tries to find binary path from running process
 func (sm *ServerManager) findBinaryFromProcess() string {
-	log.Println("🔍 [FALLBACK DEBUG] Finding binary from running process...")
-	
 	// Try to get process info and extract binary path
 	cmd := exec.Command("sh", "-c", "ps aux | grep tailscaled | grep -v grep | awk '{print $11}'")
 	output, err := cmd.Output()
@@ -681,7 +657,7 @@ func (sm *ServerManager) findBinaryFromProcess() string {
 					dir := strings.TrimSuffix(line, "/tailscaled")
 					tailscalePath := dir + "/tailscale"
 					if sm.isTailscaleBinaryValid(tailscalePath) {
-						log.Printf("✅ [FALLBACK DEBUG] Found tailscale via process: %s", tailscalePath)
+						tailscaleLogger.Debug("Found tailscale", "path", tailscalePath, "via", "process")
 						return tailscalePath
 					}
 				}
@@ -689,14 +665,11 @@ func (sm *ServerManager) findBinaryFromProcess() string {
 		}
 	}
 	
-	log.Println("❌ [FALLBACK DEBUG] Could not find binary from process")
 	return ""
 }
 
 // findBinaryViaPackageManager queries package managers for tailscale location
 func (sm *ServerManager) findBinaryViaPackageManager() string {
-	log.Println("🔍 [FALLBACK DEBUG] Checking package managers...")
-	
 	// Try dpkg (Debian/Ubuntu)
 	cmd := exec.Command("dpkg", "-L", "tailscale")
 	output, err := cmd.Output()
@@ -705,7 +678,7 @@ func (sm *ServerManager) findBinaryViaPackageManager() string {
 		for _, line := range lines {
 			line = strings.TrimSpace(line)
 			if strings.HasSuffix(line, "/tailscale") && sm.isTailscaleBinaryValid(line) {
-				log.Printf("✅ [FALLBACK DEBUG] Found tailscale via dpkg: %s", line)
+				tailscaleLogger.Debug("Found tailscale", "path", line, "via", "dpkg")
 				return line
 			}
 		}
@@ -719,20 +692,17 @@ func (sm *ServerManager) findBinaryViaPackageManager() string {
 		for _, line := range lines {
 			line = strings.TrimSpace(line)
 			if strings.HasSuffix(line, "/tailscale") && sm.isTailscaleBinaryValid(line) {
-				log.Printf("✅ [FALLBACK DEBUG] Found tailscale via rpm: %s", line)
+				tailscaleLogger.Debug("Found tailscale", "path", line, "via", "rpm")
 				return line
 			}
 		}
 	}
 	
-	log.Println("❌ [FALLBACK DEBUG] Package managers didn't help")
 	return ""
 }
 
 // findBinaryViaLocate uses locate command to find tailscale
 func (sm *ServerManager) findBinaryViaLocate() string {
-	log.Println("🔍 [FALLBACK DEBUG] Trying locate command...")
-	
 	cmd := exec.Command("locate", "tailscale")
 	output, err := cmd.Output()
 	if err == nil {
@@ -740,20 +710,17 @@ func (sm *ServerManager) findBinaryViaLocate() string {
 		for _, line := range lines {
 			line = strings.TrimSpace(line)
 			if strings.HasSuffix(line, "/tailscale") && !strings.Contains(line, ".") && sm.isTailscaleBinaryValid(line) {
-				log.Printf("✅ [FALLBACK DEBUG] Found tailscale via locate: %s", line)
+				tailscaleLogger.Debug("Found tailscale", "path", line, "via", "locate")
 				return line
 			}
 		}
 	}
 	
-	log.Println("❌ [FALLBACK DEBUG] locate command didn't help")
 	return ""
 }
 
 // findBinaryViaFind uses find command as last resort
 func (sm *ServerManager) findBinaryViaFind() string {
-	log.Println("🔍 [FALLBACK DEBUG] Trying find command (last resort)...")
-	
 	// Search common directories with find
 	searchDirs := []string{"/usr", "/opt", "/snap", "/home"}
 	
@@ -765,20 +732,19 @@ func (sm *ServerManager) findBinaryViaFind() string {
 			for _, line := range lines {
 				line = strings.TrimSpace(line)
 				if line != "" && sm.isTailscaleBinaryValid(line) {
-					log.Printf("✅ [FALLBACK DEBUG] Found tailscale via find: %s", line)
+					tailscaleLogger.Debug("Found tailscale", "path", line, "via", "find")
 					return line
 				}
 			}
 		}
 	}
 	
-	log.Println("❌ [FALLBACK DEBUG] find command didn't help")
 	return ""
 }
 
 // detectTailscaleNetwork checks if Tailscale network interface exists
 func (sm *ServerManager) detectTailscaleNetwork() bool {
-	log.Println("🔍 [NETWORK DEBUG] Checking for Tailscale network interface...")
+	tailscaleLogger.Debug("Checking for a Tailscale network interface")
 	
 	// Check for tailscale0 interface
 	if sm.checkTailscaleInterface() {
@@ -790,19 +756,17 @@ func (sm *ServerManager) detectTailscaleNetwork() bool {
 		return true
 	}
 	
-	log.Println("❌ [NETWORK DEBUG] No Tailscale network detected")
+	tailscaleLogger.Debug("No Tailscale network detected")
 	return false
 }
 
 // checkTailscaleInterface checks for tailscale0 network interface
 func (sm *ServerManager) checkTailscaleInterface() bool {
-	log.Println("🔍 [NETWORK DEBUG] Checking for tailscale0 interface...")
-	
 	// Use ip command (Linux)
 	cmd := exec.Command("ip", "link", "show", "tailscale0")
 	err := cmd.Run()
 	if err == nil {
-		log.Println("✅ [NETWORK DEBUG] Found tailscale0 interface")
+		tailscaleLogger.Debug("Found tailscale0 interface")
 		return true
 	}
 	
@@ -810,7 +774,7 @@ func (sm *ServerManager) checkTailscaleInterface() bool {
 	cmd = exec.Command("ifconfig", "tailscale0")
 	err = cmd.Run()
 	if err == nil {
-		log.Println("✅ [NETWORK DEBUG] Found tailscale0 interface via ifconfig")
+		tailscaleLogger.Debug("Found tailscale0 interface", "via", "ifconfig")
 		return true
 	}
 	
@@ -819,19 +783,16 @@ func (sm *ServerManager) checkTailscaleInterface() bool {
 	output, err := cmd.Output()
 	if err == nil {
 		if strings.Contains(string(output), "tailscale") {
-			log.Println("✅ [NETWORK DEBUG] Found tailscale-related interface")
+			tailscaleLogger.Debug("Found Tailscale related interface")
 			return true
 		}
 	}
 	
-	log.Println("❌ [NETWORK DEBUG] No tailscale interface found")
 	return false
 }
 
 // checkTailscaleIPRanges checks for Tailscale IP addresses (100.x.x.x)
 func (sm *ServerManager) checkTailscaleIPRanges() bool {
-	log.Println("🔍 [NETWORK DEBUG] Checking for Tailscale IP ranges...")
-	
 	// Use ip addr show to get all IP addresses
 	cmd := exec.Command("ip", "addr", "show")
 	output, err := cmd.Output()
@@ -839,7 +800,7 @@ func (sm *ServerManager) checkTailscaleIPRanges() bool {
 		lines := strings.Split(string(output), "\n")
 		for _, line := range lines {
 			if strings.Contains(line, "inet 100.") {
-				log.Printf("✅ [NETWORK DEBUG] Found Tailscale IP range: %s", strings.TrimSpace(line))
+				tailscaleLogger.Debug("Found Tailscale IP range", "line", strings.TrimSpace(line))
 				return true
 			}
 		}
@@ -850,19 +811,16 @@ func (sm *ServerManager) checkTailscaleIPRanges() bool {
 	output, err = cmd.Output()
 	if err == nil {
 		if strings.Contains(string(output), "100.") {
-			log.Println("✅ [NETWORK DEBUG] Found potential Tailscale IP via ifconfig")
+			tailscaleLogger.Debug("Found possible Tailscale IP", "via", "ifconfig")
 			return true
 		}
 	}
 	
-	log.Println("❌ [NETWORK DEBUG] No Tailscale IP ranges found")
 	return false
 }
 
 // getTailscaleIPFromNetwork extracts Tailscale IP from network interfaces
 func (sm *ServerManager) getTailscaleIPFromNetwork() string {
-	log.Println("🔍 [NETWORK DEBUG] Extracting Tailscale IP from network...")
-	
 	// Use ip addr show to get IP addresses
 	cmd := exec.Command("ip", "addr", "show")
 	output, err := cmd.Output()
@@ -875,7 +833,7 @@ func (sm *ServerManager) getTailscaleIPFromNetwork() string {
 				for _, part := range parts {
 					if strings.HasPrefix(part, "100.") {
 						ip := strings.Split(part, "/")[0] // Remove CIDR notation
-						log.Printf("✅ [NETWORK DEBUG] Extracted Tailscale IP: %s", ip)
+						tailscaleLogger.Debug("Found Tailscale IP", "ip", ip)
 						return ip
 					}
 				}
@@ -890,20 +848,18 @@ func (sm *ServerManager) getTailscaleIPFromNetwork() string {
 		ips := strings.Fields(string(output))
 		for _, ip := range ips {
 			if strings.HasPrefix(ip, "100.") {
-				log.Printf("✅ [NETWORK DEBUG] Found Tailscale IP via hostname: %s", ip)
+				tailscaleLogger.Debug("Found Tailscale IP", "ip", ip, "via", "hostname")
 				return ip
 			}
 		}
 	}
 	
-	log.Println("❌ [NETWORK DEBUG] Could not extract Tailscale IP")
+	tailscaleLogger.Debug("Could not find the Tailscale IP")
 	return ""
 }
 
 // findAnyTailscaleBinary tries to find tailscale binary by any means necessary
 func (sm *ServerManager) findAnyTailscaleBinary() string {
-	log.Println("🔍 [NETWORK DEBUG] Final attempt to find ANY tailscale binary...")
-	
 	// Try all our existing methods one more time
 	if path := sm.findTailscaleInPath(); path != "" {
 		return path
@@ -917,12 +873,11 @@ func (sm *ServerManager) findAnyTailscaleBinary() string {
 	commonCommands := []string{"tailscale", "/usr/bin/tailscale", "/bin/tailscale"}
 	for _, cmd := range commonCommands {
 		if sm.isTailscaleBinaryValid(cmd) {
-			log.Printf("✅ [NETWORK DEBUG] Found working tailscale: %s", cmd)
+			tailscaleLogger.Debug("Found working tailscale", "path", cmd)
 			return cmd
 		}
 	}
 	
-	log.Println("❌ [NETWORK DEBUG] No tailscale binary found")
 	return ""
 }
 
@@ -930,7 +885,7 @@ func (sm *ServerManager) findAnyTailscaleBinary() string {
 func (sm *ServerManager) detectTailscaleBinary() string {
 	// If we're in Flatpak mode and flatpak-spawn works, use "tailscale" 
 	if sm.useFlatpakSpawn {
-		log.Println("✅ [FLATPAK] Using flatpak-spawn for tailscale access")
+		tailscaleLogger.Debug("Using flatpak-spawn for tailscale")
 		return "tailscale"
 	}
 	
@@ -968,31 +923,25 @@ func (sm *ServerManager) detectTailscaleBinary() string {
 	// Expand user home directory paths
 	expandedPaths := sm.expandUserPaths(possiblePaths)
 	
-	log.Printf("🔍 [TAILSCALE DEBUG] Checking %d possible paths...", len(expandedPaths))
+	tailscaleLogger.Debug("Checking tailscale install locations", "count", len(expandedPaths))
 	
-	for i, path := range expandedPaths {
-		log.Printf("🔍 [TAILSCALE DEBUG] Checking path %d/%d: %s", i+1, len(expandedPaths), path)
-		
+	for _, path := range expandedPaths {
 		if sm.isTailscaleBinaryValid(path) {
-			log.Printf("✅ [TAILSCALE DEBUG] Found working Tailscale at: %s", path)
+			tailscaleLogger.Debug("Found working tailscale", "path", path)
 			return path
-		} else {
-			log.Printf("❌ [TAILSCALE DEBUG] Path failed validation: %s", path)
 		}
 	}
 	
-	log.Println("❌ [TAILSCALE DEBUG] Tailscale binary not found in any expected locations")
+	tailscaleLogger.Debug("tailscale binary not found in any expected location")
 	return ""
 }
 
 // isTailscaleBinaryValid checks if a path contains a valid Tailscale binary
 func (sm *ServerManager) isTailscaleBinaryValid(path string) bool {
-	log.Printf("🔍 [TAILSCALE DEBUG] Validating binary at: %s", path)
-	
 	// First, check if the file exists and is executable
 	if path != "tailscale" { // Skip file check for PATH lookup
 		if _, err := exec.LookPath(path); err != nil {
-			log.Printf("❌ [TAILSCALE DEBUG] Binary not found or not executable: %v", err)
+			tailscaleLogger.Debug("Binary not found or not executable", "path", path, "err", err)
 			return false
 		}
 	}
@@ -1001,16 +950,15 @@ func (sm *ServerManager) isTailscaleBinaryValid(path string) bool {
 	cmd := sm.executeCommand(path, "version")
 	output, err := cmd.Output()
 	if err != nil {
-		log.Printf("❌ [TAILSCALE DEBUG] 'version' command failed: %v", err)
+		tailscaleLogger.Debug("tailscale version failed, trying --help", "path", path, "err", err)
 		
 		// If version fails, try a simple help command
 		cmd = sm.executeCommand(path, "--help")
 		err = cmd.Run()
 		if err != nil {
-			log.Printf("❌ [TAILSCALE DEBUG] '--help' command also failed: %v", err)
+			tailscaleLogger.Debug("tailscale --help failed", "path", path, "err", err)
 			return false
 		} else {
-			log.Printf("✅ [TAILSCALE DEBUG] '--help' command succeeded, binary is valid")
 			return true
 		}
 	}
@@ -1018,87 +966,82 @@ func (sm *ServerManager) isTailscaleBinaryValid(path string) bool {
 	// Check if output contains "tailscale" to verify it's actually the right binary
 	outputStr := string(output)
 	if strings.Contains(strings.ToLower(outputStr), "tailscale") {
-		log.Printf("✅ [TAILSCALE DEBUG] 'version' command succeeded: %s", strings.TrimSpace(outputStr))
+		tailscaleLogger.Debug("Valid tailscale binary", "path", path, "version", strings.TrimSpace(outputStr))
 		return true
 	}
 	
-	log.Printf("❌ [TAILSCALE DEBUG] Binary exists but doesn't appear to be Tailscale: %s", outputStr)
+	tailscaleLogger.Debug("Binary does not appear to be tailscale", "path", path, "output", strings.TrimSpace(outputStr))
 	return false
 }
 
 // checkTailscaleConnection verifies that Tailscale is connected and running
 func (sm *ServerManager) checkTailscaleConnection(tailscalePath string) bool {
-	log.Println("🔍 Checking Tailscale connection status...")
+	tailscaleLogger.Debug("Checking the Tailscale connection")
 	
 	// Try to get JSON status first
 	cmd := sm.executeCommand(tailscalePath, "status", "--json")
 	output, err := cmd.Output()
 	if err != nil {
-		log.Printf("⚠️ Failed to get Tailscale JSON status: %v", err)
+		tailscaleLogger.Debug("tailscale status --json failed", "err", err)
 		
 		// If JSON status fails, try plain status (might work with different permissions)
 		cmd = sm.executeCommand(tailscalePath, "status")
 		output, err = cmd.Output()
 		if err != nil {
-			log.Printf("⚠️ Failed to get Tailscale plain status: %v", err)
+			tailscaleLogger.Debug("tailscale status failed", "err", err)
 			
 			// If status commands fail, try to get IP addresses as a last resort
 			cmd = sm.executeCommand(tailscalePath, "ip")
 			output, err = cmd.Output()
 			if err != nil {
-				log.Printf("❌ All Tailscale status commands failed: %v", err)
+				tailscaleLogger.Warn("All tailscale status commands failed", "err", err)
 				return false
 			}
 			
 			// If we got IP output, assume Tailscale is working
 			outputStr := strings.TrimSpace(string(output))
 			if outputStr != "" {
-				log.Printf("✅ Tailscale appears to be working (got IP: %s)", outputStr)
+				tailscaleLogger.Debug("Tailscale appears to be working", "ip", outputStr)
 				return true
 			}
 			
-			log.Println("❌ Tailscale IP command returned empty result")
+			tailscaleLogger.Warn("tailscale ip returned nothing")
 			return false
 		}
 		
 		// Check plain status output for connectivity indicators
 		outputStr := strings.ToLower(string(output))
 		if strings.Contains(outputStr, "logged in") || strings.Contains(outputStr, "online") || strings.Contains(outputStr, "connected") {
-			log.Printf("✅ Tailscale appears to be connected (plain status)")
+			tailscaleLogger.Debug("Tailscale appears to be connected")
 			return true
 		}
 		
-		log.Printf("❌ Tailscale status doesn't indicate connection: %s", string(output))
+		tailscaleLogger.Warn("Tailscale status does not show a connection", "output", strings.TrimSpace(string(output)))
 		return false
 	}
 	
 	// Parse JSON output
 	var status map[string]interface{}
 	if err := json.Unmarshal(output, &status); err != nil {
-		log.Printf("❌ Failed to parse Tailscale status JSON: %v", err)
+		tailscaleLogger.Warn("Failed to parse the Tailscale status, assuming it works", "err", err)
 		// Even if JSON parsing fails, if we got output, Tailscale is probably working
-		log.Println("✅ Assuming Tailscale is working since we got status output")
 		return true
 	}
 	
 	// Check BackendState
 	backendState, ok := status["BackendState"].(string)
 	if !ok {
-		log.Println("⚠️ Could not extract BackendState from Tailscale status")
+		tailscaleLogger.Warn("Tailscale status has no BackendState, assuming it works")
 		// If we can't get state but got JSON, assume it's working
-		log.Println("✅ Assuming Tailscale is working since we got JSON status")
 		return true
 	}
 	
-	log.Printf("🔍 Tailscale status: %s", backendState)
+	tailscaleLogger.Debug("Tailscale status", "state", backendState)
 	
 	isRunning := backendState == "Running"
-	if isRunning {
-		log.Println("✅ Tailscale is running and connected")
-	} else {
-		log.Printf("⚠️ Tailscale is not in Running state: %s", backendState)
+	if !isRunning {
+		tailscaleLogger.Warn("Tailscale is not in the Running state, continuing anyway", "state", backendState)
 		// Even if not "Running", it might still be usable
-		log.Println("✅ Continuing anyway - Tailscale binary is available")
 	}
 	
 	return true // Be more permissive - if we got this far, Tailscale is probably usable
@@ -1106,19 +1049,19 @@ func (sm *ServerManager) checkTailscaleConnection(tailscalePath string) bool {
 
 // getTailscaleHostname gets the Tailscale hostname for this machine
 func (sm *ServerManager) getTailscaleHostname(tailscalePath string) string {
-	log.Println("🔍 Getting Tailscale hostname...")
+	tailscaleLogger.Debug("Getting the Tailscale hostname")
 	
 	// Try JSON status first
 	cmd := sm.executeCommand(tailscalePath, "status", "--json")
 	output, err := cmd.Output()
 	if err != nil {
-		log.Printf("⚠️ Failed to get Tailscale status for hostname: %v", err)
+		tailscaleLogger.Debug("tailscale status --json failed, trying tailscale ip", "err", err)
 		
 		// Try to get IP and create a basic hostname
 		cmd = sm.executeCommand(tailscalePath, "ip")
 		output, err = cmd.Output()
 		if err != nil {
-			log.Printf("❌ Failed to get Tailscale IP: %v", err)
+			tailscaleLogger.Warn("Failed to get the Tailscale IP", "err", err)
 			return ""
 		}
 		
@@ -1126,18 +1069,18 @@ func (sm *ServerManager) getTailscaleHostname(tailscalePath string) string {
 		ips := strings.Fields(strings.TrimSpace(string(output)))
 		if len(ips) > 0 {
 			ip := ips[0]
-			log.Printf("✅ Using Tailscale IP as hostname: %s", ip)
+			tailscaleLogger.Debug("Using the Tailscale IP as hostname", "ip", ip)
 			return ip
 		}
 		
-		log.Println("❌ No Tailscale IPs found")
+		tailscaleLogger.Warn("No Tailscale IPs found")
 		return ""
 	}
 	
 	// Parse JSON output
 	var status map[string]interface{}
 	if err := json.Unmarshal(output, &status); err != nil {
-		log.Printf("⚠️ Failed to parse Tailscale hostname JSON: %v", err)
+		tailscaleLogger.Warn("Failed to parse the Tailscale status", "err", err)
 		
 		// Fallback: try to get IP
 		cmd = sm.executeCommand(tailscalePath, "ip")
@@ -1146,7 +1089,7 @@ func (sm *ServerManager) getTailscaleHostname(tailscalePath string) string {
 			ips := strings.Fields(strings.TrimSpace(string(output)))
 			if len(ips) > 0 {
 				ip := ips[0]
-				log.Printf("✅ Using Tailscale IP as hostname (JSON failed): %s", ip)
+				tailscaleLogger.Debug("Using the Tailscale IP as hostname", "ip", ip)
 				return ip
 			}
 		}
@@ -1157,7 +1100,7 @@ func (sm *ServerManager) getTailscaleHostname(tailscalePath string) string {
 	// Extract Self information
 	self, ok := status["Self"].(map[string]interface{})
 	if !ok {
-		log.Println("⚠️ Could not extract Self info from Tailscale status")
+		tailscaleLogger.Warn("Tailscale status has no Self entry")
 		
 		// Try to find any peer that might be ourselves
 		peers, ok := status["Peers"].(map[string]interface{})
@@ -1166,7 +1109,7 @@ func (sm *ServerManager) getTailscaleHostname(tailscalePath string) string {
 				if peerMap, ok := peer.(map[string]interface{}); ok {
 					if dnsName, ok := peerMap["DNSName"].(string); ok && dnsName != "" {
 						cleanedName := strings.TrimSuffix(dnsName, ".")
-						log.Printf("✅ Using peer DNSName as hostname: %s", cleanedName)
+						tailscaleLogger.Debug("Using the peer DNS name as hostname", "hostname", cleanedName)
 						return cleanedName
 					}
 				}
@@ -1180,7 +1123,7 @@ func (sm *ServerManager) getTailscaleHostname(tailscalePath string) string {
 			ips := strings.Fields(strings.TrimSpace(string(output)))
 			if len(ips) > 0 {
 				ip := ips[0]
-				log.Printf("✅ Using Tailscale IP as hostname (no Self): %s", ip)
+				tailscaleLogger.Debug("Using the Tailscale IP as hostname", "ip", ip)
 				return ip
 			}
 		}
@@ -1191,12 +1134,12 @@ func (sm *ServerManager) getTailscaleHostname(tailscalePath string) string {
 	// Get DNS name
 	dnsName, ok := self["DNSName"].(string)
 	if !ok {
-		log.Println("⚠️ Could not extract DNSName from Tailscale Self info")
+		tailscaleLogger.Warn("Tailscale status has no DNS name")
 		
 		// Try to get IP from Self info
 		if ips, ok := self["TailscaleIPs"].([]interface{}); ok && len(ips) > 0 {
 			if ip, ok := ips[0].(string); ok {
-				log.Printf("✅ Using Tailscale IP from Self: %s", ip)
+				tailscaleLogger.Debug("Using the Tailscale IP as hostname", "ip", ip)
 				return ip
 			}
 		}
@@ -1208,7 +1151,7 @@ func (sm *ServerManager) getTailscaleHostname(tailscalePath string) string {
 			ips := strings.Fields(strings.TrimSpace(string(output)))
 			if len(ips) > 0 {
 				ip := ips[0]
-				log.Printf("✅ Using Tailscale IP as hostname (no DNSName): %s", ip)
+				tailscaleLogger.Debug("Using the Tailscale IP as hostname", "ip", ip)
 				return ip
 			}
 		}
@@ -1219,13 +1162,13 @@ func (sm *ServerManager) getTailscaleHostname(tailscalePath string) string {
 	// Clean up DNS name (remove trailing dots)
 	cleanedName := strings.TrimSuffix(dnsName, ".")
 	
-	log.Printf("✅ Found Tailscale hostname: %s", cleanedName)
+	tailscaleLogger.Debug("Found Tailscale hostname", "hostname", cleanedName)
 	return cleanedName
 }
 
 // RefreshTailscaleStatus re-checks Tailscale status (for UI refresh)
 func (sm *ServerManager) RefreshTailscaleStatus() {
-	log.Println("🔄 Refreshing Tailscale status...")
+	tailscaleLogger.Debug("Refreshing Tailscale status")
 	go sm.checkTailscaleStatus()
 }
 
@@ -1296,7 +1239,7 @@ func (sm *ServerManager) MonitorTailscaleStatus(interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	
-	log.Printf("🔍 Starting Tailscale monitoring (every %v)", interval)
+	tailscaleLogger.Debug("Monitoring Tailscale", "interval", interval)
 	
 	for {
 		select {
@@ -1308,14 +1251,14 @@ func (sm *ServerManager) MonitorTailscaleStatus(interval time.Duration) {
 			// Log status changes
 			if prevStatus != sm.HasTailscale {
 				if sm.HasTailscale {
-					log.Println("✅ Tailscale became available")
+					tailscaleLogger.Info("Tailscale became available")
 				} else {
-					log.Println("❌ Tailscale became unavailable")
+					tailscaleLogger.Info("Tailscale became unavailable")
 				}
 			}
 			
 		case <-sm.ctx.Done():
-			log.Println("🔍 Stopping Tailscale monitoring")
+			tailscaleLogger.Debug("Stopped monitoring Tailscale")
 			return
 		}
 	}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"bma-core/settings"
+)
+
+// Embedded Tailscale mode for BMA Go+Fyne
+// Features:
+// - Userspace tailnet node (tsnet) with state in the config dir
+// - No dependency on a host tailscaled or flatpak-spawn
+// - Node hostname used directly in the pairing QR code
+//
+// The tsnet node is only compiled in with `-tags tsnet` (see Makefile).
+
+// ErrEmbeddedTailscaleUnavailable is returned when BMA was built without tsnet support
+var ErrEmbeddedTailscaleUnavailable = errors.New("embedded Tailscale is not compiled into this build (rebuild with -tags tsnet)")
+
+// Backend states reported by the embedded node
+const (
+	EmbeddedStateNoState    = "NoState"
+	EmbeddedStateStarting   = "Starting"
+	EmbeddedStateNeedsLogin = "NeedsLogin"
+	EmbeddedStateRunning    = "Running"
+)
+
+// EmbeddedTailscaleStatus describes the state of the embedded tailnet node
+type EmbeddedTailscaleStatus struct {
+	BackendState string   `json:"backendState"`
+	AuthURL      string   `json:"authUrl,omitempty"`
+	DNSName      string   `json:"dnsName,omitempty"`
+	TailscaleIPs []string `json:"tailscaleIps,omitempty"`
+}
+
+// IsRunning returns true once the node is authenticated and connected
+func (s EmbeddedTailscaleStatus) IsRunning() bool {
+	return s.BackendState == EmbeddedStateRunning
+}
+
+// NeedsLogin returns true while the node waits for interactive authentication
+func (s EmbeddedTailscaleStatus) NeedsLogin() bool {
+	return s.BackendState == EmbeddedStateNeedsLogin
+}
+
+// Host returns the address other tailnet devices should use to reach the node
+func (s EmbeddedTailscaleStatus) Host() string {
+	if s.DNSName != "" {
+		return s.DNSName
+	}
+	if len(s.TailscaleIPs) > 0 {
+		return s.TailscaleIPs[0]
+	}
+	return ""
+}
+
+// EmbeddedTailscaleSupported reports whether this build includes the tsnet node
+func EmbeddedTailscaleSupported() bool {
+	return embeddedTailscaleSupported
+}
+
+// UseEmbeddedTailscale switches the server to the embedded tailnet node and
+// blocks until the node reports its state
+func (sm *ServerManager) UseEmbeddedTailscale(hostname string) {
+	sm.tailscaleMutex.Lock()
+	sm.embeddedMode = true
+	sm.embeddedHostname = hostname
+	sm.tailscaleMutex.Unlock()
+
+	tailscaleLogger.Info("Embedded Tailscale mode enabled", "hostname", hostname)
+	sm.checkTailscaleStatus()
+}
+
+// checkEmbeddedTailscaleStatus starts the embedded node if needed and refreshes the Tailscale URL
+func (sm *ServerManager) checkEmbeddedTailscaleStatus() {
+	node, err := sm.ensureEmbeddedNode()
+	if err != nil {
+		tailscaleLogger.Error("Embedded Tailscale unavailable", "err", err)
+		sm.HasTailscale = false
+		sm.TailscaleURL = ""
+		return
+	}
+
+	status, err := sm.waitForEmbeddedNode(node, 15*time.Second)
+	if err != nil {
+		tailscaleLogger.Error("Failed to get embedded node status", "err", err)
+		sm.HasTailscale = false
+		sm.TailscaleURL = ""
+		return
+	}
+
+	if !status.IsRunning() || status.Host() == "" {
+		tailscaleLogger.Warn("Embedded node not connected yet", "state", status.BackendState)
+		if status.NeedsLogin() {
+			tailscaleLogger.Warn("Re-run the setup wizard's Tailscale step to authenticate this node")
+		}
+		sm.HasTailscale = false
+		sm.TailscaleURL = ""
+		return
+	}
+
+	sm.HasTailscale = true
+	sm.TailscaleURL = fmt.Sprintf("http://%s", status.Host())
+	tailscaleLogger.Info("Embedded Tailscale configured", "url", sm.TailscaleURL)
+
+	// Server may have started before the node came up
+	sm.attachEmbeddedListener()
+}
+
+// waitForEmbeddedNode polls the node until it leaves its startup states
+func (sm *ServerManager) waitForEmbeddedNode(node *EmbeddedTailscale, timeout time.Duration) (EmbeddedTailscaleStatus, error) {
+	ctx, cancel := context.WithTimeout(sm.ctx, timeout)
+	defer cancel()
+
+	for {
+		status, err := node.Status(ctx)
+		if err != nil {
+			return status, err
+		}
+		if status.BackendState != EmbeddedStateNoState && status.BackendState != EmbeddedStateStarting {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, nil
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// ensureEmbeddedNode starts the embedded node on first use
+func (sm *ServerManager) ensureEmbeddedNode() (*EmbeddedTailscale, error) {
+	sm.tailscaleMutex.Lock()
+	defer sm.tailscaleMutex.Unlock()
+
+	if sm.embeddedNode != nil {
+		return sm.embeddedNode, nil
+	}
+
+	stateDir, err := settings.GetTailscaleStateDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tsnet state dir: %w", err)
+	}
+
+	node := NewEmbeddedTailscale(stateDir, sm.embeddedHostname)
+	if err := node.Start(); err != nil {
+		return nil, err
+	}
+
+	sm.embeddedNode = node
+	tailscaleLogger.Info("Embedded node started", "state_dir", stateDir)
+	return node, nil
+}
+
+// attachEmbeddedListener serves the router on the embedded node's tailnet address
+func (sm *ServerManager) attachEmbeddedListener() {
+	sm.tailscaleMutex.Lock()
+	defer sm.tailscaleMutex.Unlock()
+
+	if !sm.IsRunning || sm.server == nil || sm.embeddedNode == nil || sm.embeddedListening {
+		return
+	}
+
+	listener, err := sm.embeddedNode.Listen(fmt.Sprintf(":%d", sm.Port))
+	if err != nil {
+		tailscaleLogger.Error("Failed to listen on the tailnet", "err", err)
+		return
+	}
+
+	sm.embeddedListening = true
+	server := sm.server
+	go func() {
+		logger.Info("HTTP server listening on the tailnet", "port", sm.Port)
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			tailscaleLogger.Error("Tailnet listener failed", "err", err)
+		}
+
+		sm.tailscaleMutex.Lock()
+		sm.embeddedListening = false
+		sm.tailscaleMutex.Unlock()
+	}()
+}
+
+// closeEmbeddedNode shuts down the embedded node
+func (sm *ServerManager) closeEmbeddedNode() {
+	sm.tailscaleMutex.Lock()
+	defer sm.tailscaleMutex.Unlock()
+
+	if sm.embeddedNode == nil {
+		return
+	}
+
+	if err := sm.embeddedNode.Close(); err != nil {
+		tailscaleLogger.Warn("Failed to close embedded node", "err", err)
+	}
+	sm.embeddedNode = nil
+	tailscaleLogger.Info("Embedded node stopped")
+}
@@ -0,0 +1,85 @@
+//go:build tsnet
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+
+	"tailscale.com/tsnet"
+)
+
+const embeddedTailscaleSupported = true
+
+// EmbeddedTailscale wraps a userspace tsnet node owned by BMA
+type EmbeddedTailscale struct {
+	node *tsnet.Server
+}
+
+// NewEmbeddedTailscale creates an embedded node storing its state in stateDir
+func NewEmbeddedTailscale(stateDir, hostname string) *EmbeddedTailscale {
+	return &EmbeddedTailscale{
+		node: &tsnet.Server{
+			Dir:      stateDir,
+			Hostname: hostname,
+			// Backend logs are very chatty, they only show at debug level
+			Logf: func(format string, args ...any) {
+				if tailscaleLogger.Enabled(context.Background(), slog.LevelDebug) {
+					tailscaleLogger.Debug(fmt.Sprintf(format, args...), "source", "tsnet")
+				}
+			},
+			UserLogf: func(format string, args ...any) {
+				tailscaleLogger.Info(fmt.Sprintf(format, args...), "source", "tsnet")
+			},
+		},
+	}
+}
+
+// Start brings the node up without waiting for authentication
+func (et *EmbeddedTailscale) Start() error {
+	if err := et.node.Start(); err != nil {
+		return fmt.Errorf("failed to start tsnet node: %w", err)
+	}
+	return nil
+}
+
+// Status returns the current backend state, auth URL and tailnet address
+func (et *EmbeddedTailscale) Status(ctx context.Context) (EmbeddedTailscaleStatus, error) {
+	client, err := et.node.LocalClient()
+	if err != nil {
+		return EmbeddedTailscaleStatus{}, fmt.Errorf("failed to get tsnet local client: %w", err)
+	}
+
+	st, err := client.Status(ctx)
+	if err != nil {
+		return EmbeddedTailscaleStatus{}, fmt.Errorf("failed to get tsnet status: %w", err)
+	}
+
+	status := EmbeddedTailscaleStatus{
+		BackendState: st.BackendState,
+		AuthURL:      st.AuthURL,
+	}
+	if st.Self != nil {
+		status.DNSName = strings.TrimSuffix(st.Self.DNSName, ".")
+	}
+	for _, ip := range st.TailscaleIPs {
+		if ip.Is4() {
+			status.TailscaleIPs = append(status.TailscaleIPs, ip.String())
+		}
+	}
+
+	return status, nil
+}
+
+// Listen opens a TCP listener on the node's tailnet addresses
+func (et *EmbeddedTailscale) Listen(addr string) (net.Listener, error) {
+	return et.node.Listen("tcp", addr)
+}
+
+// Close shuts the node down, keeping its state for the next start
+func (et *EmbeddedTailscale) Close() error {
+	return et.node.Close()
+}
@@ -0,0 +1,38 @@
+//go:build !tsnet
+
+package server
+
+import (
+	"context"
+	"net"
+)
+
+const embeddedTailscaleSupported = false
+
+// EmbeddedTailscale is a placeholder used when BMA is built without tsnet
+type EmbeddedTailscale struct{}
+
+// NewEmbeddedTailscale creates a placeholder node that always fails to start
+func NewEmbeddedTailscale(stateDir, hostname string) *EmbeddedTailscale {
+	return &EmbeddedTailscale{}
+}
+
+// Start always fails in builds without tsnet
+func (et *EmbeddedTailscale) Start() error {
+	return ErrEmbeddedTailscaleUnavailable
+}
+
+// Status always fails in builds without tsnet
+func (et *EmbeddedTailscale) Status(ctx context.Context) (EmbeddedTailscaleStatus, error) {
+	return EmbeddedTailscaleStatus{}, ErrEmbeddedTailscaleUnavailable
+}
+
+// Listen always fails in builds without tsnet
+func (et *EmbeddedTailscale) Listen(addr string) (net.Listener, error) {
+	return nil, ErrEmbeddedTailscaleUnavailable
+}
+
+// Close is a no-op in builds without tsnet
+func (et *EmbeddedTailscale) Close() error {
+	return nil
+}
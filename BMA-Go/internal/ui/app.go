@@ -1,24 +1,28 @@
 package ui
 
 import (
-	"log"
-	
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 
-	"bma-go/internal/models"
+	"bma-core/library"
+	"bma-core/logging"
+	"bma-core/settings"
 	"bma-go/internal/server"
 )
 
+// logger tags the desktop UI's log lines with component=ui
+var logger = logging.Component("ui")
+
 // MainUI represents the main application UI, equivalent to ContentView in Swift
 type MainUI struct {
 	serverManager *server.ServerManager
-	musicLibrary  *models.MusicLibrary
+	musicLibrary  *library.MusicLibrary
 	serverStatus  *ServerStatusBar
 	songList      *SongListView
 	libraryStatus *LibraryStatusBar
 	content       *fyne.Container
+	stopMonitor   func() // Stops the library root monitor
 }
 
 // NewMainUI creates a new main UI instance
@@ -34,7 +38,7 @@ func (ui *MainUI) initialize() {
 	ui.serverManager = server.NewServerManager()
 	
 	// Create a MusicLibrary instance
-	ui.musicLibrary = models.NewMusicLibrary()
+	ui.musicLibrary = library.NewMusicLibrary()
 	
 	// Connect the MusicLibrary to the ServerManager
 	ui.serverManager.SetMusicLibrary(ui.musicLibrary)
@@ -70,41 +74,56 @@ func (ui *MainUI) initialize() {
 // LoadMusicLibrary loads the music library from the configured folder
 func (ui *MainUI) LoadMusicLibrary() {
 	// Load config to get music folder path
-	config, err := models.LoadConfig()
+	config, err := settings.LoadConfig()
 	if err != nil {
-		log.Printf("❌ Failed to load config for music library: %v", err)
+		logger.Error("Failed to load config for the music library", "err", err)
 		return
 	}
 	
-	// Check if music folder is configured
-	if config.MusicFolder == "" {
-		log.Println("⚠️ No music folder configured")
+	// Check if any music folder is configured
+	roots := config.EnabledLibraryRoots()
+	if len(roots) == 0 {
+		logger.Warn("No music folder configured")
 		return
 	}
 	
-	log.Printf("🎵 Loading music library from: %s", config.MusicFolder)
+	for _, root := range roots {
+		logger.Info("Loading music library", "root", root.Path, "name", root.DisplayName())
+	}
+	
+	ui.musicLibrary.SetScanOptions(config.GetScanOptions())
 	
-	// Use SelectFolder which sets the path and scans the library
-	go ui.musicLibrary.SelectFolder(config.MusicFolder)
+	// Use SelectRoots which sets the roots and scans them into one library
+	go ui.musicLibrary.SelectRoots(roots)
+	
+	// Rescan automatically when a drive is unplugged or comes back
+	if ui.stopMonitor != nil {
+		ui.stopMonitor()
+	}
+	ui.stopMonitor = ui.musicLibrary.StartRootMonitor(library.RootMonitorInterval)
 	
 	// Automatically start the server after music library loading
-	go ui.AutoStartServer()
+	go func() {
+		// Bring the embedded tailnet node up first so the QR code carries its hostname
+		if config.UsesEmbeddedTailscale() {
+			ui.serverManager.UseEmbeddedTailscale(config.EmbeddedTailscaleHostname())
+		}
+		ui.AutoStartServer()
+	}()
 }
 
 // AutoStartServer automatically starts the server and generates QR code for seamless UX
 func (ui *MainUI) AutoStartServer() {
 	// Wait a moment for music library to start loading
-	log.Println("🚀 Auto-starting server for seamless experience...")
+	logger.Debug("Starting the server automatically")
 	
 	// Start the server automatically
 	err := ui.serverManager.StartServer()
 	if err != nil {
-		log.Printf("❌ Auto-start server failed: %v", err)
+		logger.Error("Failed to start the server automatically", "err", err)
 		return
 	}
 	
-	log.Println("✅ Server auto-started successfully!")
-	
 	// Automatically generate and show QR code
 	ui.serverStatus.AutoGenerateQR()
 }
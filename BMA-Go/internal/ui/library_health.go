@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"bma-core/library"
+)
+
+// maxIssuesPerKind limits how many issues of one kind are listed in the health window
+const maxIssuesPerKind = 200
+
+// showLibraryHealthWindow opens a window listing the problems found by the last scan
+func showLibraryHealthWindow(musicLibrary *library.MusicLibrary) {
+	logger.Debug("Opening the library health window")
+
+	window := fyne.CurrentApp().NewWindow("Library Health")
+
+	report := musicLibrary.GetHealthReport()
+	if report == nil {
+		window.SetContent(container.NewPadded(widget.NewLabel("The library has not been scanned yet.")))
+		window.Resize(fyne.NewSize(400, 120))
+		window.Show()
+		return
+	}
+
+	summary := widget.NewLabel(fmt.Sprintf("%d songs in %d albums • %d issues • scanned %s",
+		report.SongCount, report.AlbumCount, len(report.Issues), report.GeneratedAt.Format("2 Jan 15:04")))
+
+	// One accordion section per issue kind, empty kinds are left out
+	accordion := widget.NewAccordion()
+	for _, kind := range library.IssueKinds {
+		issues := report.IssuesOfKind(kind)
+		if len(issues) == 0 {
+			continue
+		}
+		title := fmt.Sprintf("%s (%d)", kind.Description(), len(issues))
+		accordion.Append(widget.NewAccordionItem(title, issueList(issues)))
+	}
+
+	var body fyne.CanvasObject = accordion
+	if len(accordion.Items) == 0 {
+		body = widget.NewLabel("✅ No problems found.")
+	}
+
+	closeButton := widget.NewButton("Close", func() {
+		window.Close()
+	})
+
+	window.SetContent(container.NewBorder(
+		container.NewPadded(summary),
+		container.NewHBox(closeButton),
+		nil, nil,
+		container.NewVScroll(body),
+	))
+	window.Resize(fyne.NewSize(640, 480))
+	window.Show()
+}
+
+// issueList renders issues as selectable text, one per line
+func issueList(issues []library.LibraryIssue) fyne.CanvasObject {
+	lines := make([]string, 0, len(issues))
+	for i, issue := range issues {
+		if i == maxIssuesPerKind {
+			lines = append(lines, fmt.Sprintf("… and %d more", len(issues)-maxIssuesPerKind))
+			break
+		}
+		lines = append(lines, issueLine(issue))
+	}
+
+	text := widget.NewMultiLineEntry()
+	text.SetText(strings.Join(lines, "\n"))
+	text.Wrapping = fyne.TextWrapOff
+	text.SetMinRowsVisible(min(len(lines), 10))
+	return text
+}
+
+// issueLine formats one issue for display
+func issueLine(issue library.LibraryIssue) string {
+	var parts []string
+	if issue.Album != "" {
+		parts = append(parts, "💿 "+issue.Album)
+	}
+	if issue.Path != "" {
+		parts = append(parts, issue.Path)
+	}
+	if issue.Detail != "" {
+		parts = append(parts, "("+issue.Detail+")")
+	}
+	return strings.Join(parts, "  ")
+}
@@ -2,29 +2,33 @@ package ui
 
 import (
 	"fmt"
-	"log"
+	"path/filepath"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 	
-	"bma-go/internal/models"
+	"bma-core/library"
 	"bma-go/internal/server"
 )
 
 // LibraryStatusBar displays library statistics and connected devices
 type LibraryStatusBar struct {
-	musicLibrary   *models.MusicLibrary
+	musicLibrary   *library.MusicLibrary
 	serverManager  *server.ServerManager
 	content        *fyne.Container
 	libraryLabel   *widget.Label
 	devicesLabel   *widget.Label
 	scanProgress   *widget.ProgressBar
+	scanDetails    *widget.Label
+	cancelButton   *widget.Button
+	healthButton   *widget.Button
+	lastProgress   library.ScanProgress
 }
 
 // NewLibraryStatusBar creates a new library status bar
-func NewLibraryStatusBar(musicLibrary *models.MusicLibrary, serverManager *server.ServerManager) *LibraryStatusBar {
+func NewLibraryStatusBar(musicLibrary *library.MusicLibrary, serverManager *server.ServerManager) *LibraryStatusBar {
 	lsb := &LibraryStatusBar{
 		musicLibrary:  musicLibrary,
 		serverManager: serverManager,
@@ -40,19 +44,40 @@ func (lsb *LibraryStatusBar) initialize() {
 	// Library statistics label
 	lsb.libraryLabel = widget.NewLabel("No music library loaded")
 
+	// Opens the scan report of the last scan
+	lsb.healthButton = widget.NewButton("Library Health", func() {
+		showLibraryHealthWindow(lsb.musicLibrary)
+	})
+	
 	// Connected devices label
 	lsb.devicesLabel = widget.NewLabel("Connected devices: 0")
 
 	// Scanning progress bar (hidden by default)
 	lsb.scanProgress = widget.NewProgressBar()
+	lsb.scanProgress.TextFormatter = func() string {
+		return fmt.Sprintf("%d / %d files", lsb.lastProgress.Processed, lsb.lastProgress.Discovered)
+	}
 	lsb.scanProgress.Hide()
+	
+	// Current file and cancel button, shown while scanning
+	lsb.scanDetails = widget.NewLabel("")
+	lsb.scanDetails.Truncation = fyne.TextTruncateEllipsis
+	lsb.scanDetails.Hide()
+	lsb.cancelButton = widget.NewButton("Cancel Scan", func() {
+		lsb.cancelButton.Disable()
+		go lsb.musicLibrary.CancelScan()
+	})
+	lsb.cancelButton.Hide()
 
 	// Layout components horizontally
 	lsb.content = container.NewHBox(
 		lsb.libraryLabel,
+		lsb.healthButton,
 		widget.NewSeparator(),
 		lsb.devicesLabel,
 		container.NewMax(lsb.scanProgress), // Max container for progress bar
+		lsb.cancelButton,
+		lsb.scanDetails,
 	)
 }
 
@@ -77,8 +102,13 @@ func (lsb *LibraryStatusBar) UpdateDeviceCount(count int) {
 
 // ShowScanProgress shows the scanning progress bar
 func (lsb *LibraryStatusBar) ShowScanProgress() {
+	lsb.lastProgress = library.ScanProgress{}
 	lsb.scanProgress.Show()
 	lsb.scanProgress.SetValue(0)
+	lsb.scanDetails.SetText("")
+	lsb.scanDetails.Show()
+	lsb.cancelButton.Enable()
+	lsb.cancelButton.Show()
 }
 
 // UpdateScanProgress updates the scanning progress
@@ -86,92 +116,112 @@ func (lsb *LibraryStatusBar) UpdateScanProgress(progress float64) {
 	lsb.scanProgress.SetValue(progress)
 }
 
+// UpdateScanDetails shows the file counts and current file of a running scan
+func (lsb *LibraryStatusBar) UpdateScanDetails(progress library.ScanProgress) {
+	lsb.lastProgress = progress
+	lsb.UpdateScanProgress(progress.Fraction())
+	
+	details := filepath.Base(progress.CurrentPath)
+	if progress.Failed > 0 {
+		details = fmt.Sprintf("%s (%d failed)", details, progress.Failed)
+	}
+	lsb.scanDetails.SetText(details)
+}
+
 // HideScanProgress hides the scanning progress bar
 func (lsb *LibraryStatusBar) HideScanProgress() {
 	lsb.scanProgress.Hide()
+	lsb.scanDetails.Hide()
+	lsb.cancelButton.Hide()
 }
 
 // setupCallbacks sets up the MusicLibrary callbacks to update the UI
 func (lsb *LibraryStatusBar) setupCallbacks() {
-	log.Println("📊 [DEBUG] Setting up LibraryStatusBar callbacks")
-	
 	// Set up scanning state change callback - keep it simple to avoid deadlocks
 	lsb.musicLibrary.SetScanningChangedCallback(func(isScanning bool) {
-		log.Printf("📊 [DEBUG] LibraryStatusBar: scanning changed to %v", isScanning)
+		logger.Debug("Library scanning changed", "scanning", isScanning)
 		
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("🔥 [CRASH] Panic in scanning callback: %v", r)
+				logger.Error("Panic in the scanning callback", "panic", r)
 			}
 		}()
 		
 		if isScanning {
-			log.Println("📊 [DEBUG] LibraryStatusBar: showing scan progress")
 			lsb.ShowScanProgress()
 		} else {
-			log.Println("📊 [DEBUG] LibraryStatusBar: hiding scan progress")
 			lsb.HideScanProgress()
 			// Don't call refreshLibraryStats here - let the library changed callback handle it
 		}
 	})
 	
+	// Set up scan progress callback - called at most every 100ms while scanning
+	lsb.musicLibrary.SetScanProgressCallback(func(progress library.ScanProgress) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("Panic in the scan progress callback", "panic", r)
+			}
+		}()
+		
+		if progress.Scanning {
+			lsb.UpdateScanDetails(progress)
+		} else if progress.Cancelled {
+			logger.Debug("Library scan cancelled")
+		}
+	})
+	
 	// Set up library change callback - this will be called after scanning completes
 	lsb.musicLibrary.SetLibraryChangedCallback(func() {
-		log.Println("📊 [DEBUG] LibraryStatusBar: library changed callback")
-		
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("🔥 [CRASH] Panic in library changed callback: %v", r)
+				logger.Error("Panic in the library changed callback", "panic", r)
 			}
 		}()
 		
 		lsb.refreshLibraryStats()
 	})
 	
-	log.Println("📊 [DEBUG] LibraryStatusBar: calling initial refresh")
-	
 	// Initial refresh
 	lsb.refreshLibraryStats()
-	
-	log.Println("📊 [DEBUG] LibraryStatusBar callbacks setup complete")
 }
 
 // refreshLibraryStats updates the library statistics from the actual MusicLibrary
 func (lsb *LibraryStatusBar) refreshLibraryStats() {
-	log.Println("📊 [DEBUG] refreshLibraryStats called")
-	
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("🔥 [CRASH] Panic in refreshLibraryStats: %v", r)
+			logger.Error("Panic while refreshing library stats", "panic", r)
 		}
 	}()
 	
 	if lsb.musicLibrary == nil {
-		log.Println("❌ [DEBUG] musicLibrary is nil in refreshLibraryStats")
+		logger.Warn("No music library to refresh the stats from")
 		return
 	}
 	
 	albumCount := lsb.musicLibrary.GetAlbumCount()
 	songCount := lsb.musicLibrary.GetSongCount()
 	
-	log.Printf("📊 [DEBUG] Retrieved counts: %d albums, %d songs", albumCount, songCount)
+	logger.Debug("Library stats refreshed", "albums", albumCount, "songs", songCount)
 	
 	lsb.UpdateLibraryStats(albumCount, songCount)
 	
-	log.Println("📊 [DEBUG] refreshLibraryStats completed")
+	// Show the number of problems found by the last scan on the health button
+	if report := lsb.musicLibrary.GetHealthReport(); report != nil && len(report.Issues) > 0 {
+		lsb.healthButton.SetText(fmt.Sprintf("Library Health (%d)", len(report.Issues)))
+	} else {
+		lsb.healthButton.SetText("Library Health")
+	}
 }
 
 // startDeviceMonitoring starts monitoring connected devices
 func (lsb *LibraryStatusBar) startDeviceMonitoring() {
-	log.Println("📊 [DEBUG] Starting device monitoring")
-	
 	go func() {
 		for {
 			time.Sleep(5 * time.Second) // Wait between checks
 			
 			defer func() {
 				if r := recover(); r != nil {
-					log.Printf("🔥 [CRASH] Panic in device monitoring: %v", r)
+					logger.Error("Panic in device monitoring", "panic", r)
 				}
 			}()
 			
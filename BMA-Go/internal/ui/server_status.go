@@ -2,7 +2,6 @@ package ui
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"time"
 
@@ -112,20 +111,19 @@ func (bar *ServerStatusBar) generateQR() {
 		return
 	}
 
-	log.Println("🔑 Generating QR code for device pairing...")
+	logger.Debug("Generating pairing QR code")
 
 	// Generate QR code using ServerManager
 	qrBytes, jsonData, err := bar.serverManager.GenerateQRCode()
 	if err != nil {
-		log.Printf("❌ QR generation failed: %v", err)
+		logger.Error("QR code generation failed", "err", err)
 		bar.showErrorDialog(fmt.Sprintf("QR Generation Error: %v", err))
 		return
 	}
 
 	// Debug: Check if we actually have QR code data
-	log.Printf("🔍 QR code generated: %d bytes", len(qrBytes))
 	if len(qrBytes) == 0 {
-		log.Println("❌ QR code is empty!")
+		logger.Error("QR code is empty")
 		bar.showErrorDialog("QR code generation returned empty data")
 		return
 	}
@@ -169,15 +167,15 @@ func (bar *ServerStatusBar) showQRCodeDialog(qrBytes []byte, jsonData string) {
 	// Buttons in a compact row
 	copyBtn := widget.NewButton("Copy JSON", func() {
 		qrWindow.Clipboard().SetContent(jsonData)
-		log.Println("🔑 QR code JSON copied to clipboard")
+		logger.Debug("QR code JSON copied to the clipboard")
 	})
 	
 	saveBtn := widget.NewButton("Save PNG", func() {
 		err := os.WriteFile("qr_code.png", qrBytes, 0644)
 		if err != nil {
-			log.Printf("❌ Failed to save QR: %v", err)
+			logger.Error("Failed to save the QR code", "err", err)
 		} else {
-			log.Println("✅ QR code saved as qr_code.png")
+			logger.Info("QR code saved", "path", "qr_code.png")
 		}
 	})
 	
@@ -222,8 +220,6 @@ func (bar *ServerStatusBar) showQRCodeDialog(qrBytes []byte, jsonData string) {
 	qrWindow.Resize(fyne.NewSize(380, 350)) // Smaller window for compact QR code
 	qrWindow.SetFixedSize(true)             // Fixed size like Mac app
 	qrWindow.Show()
-	
-	log.Println("✅ Mac-style QR code window displayed")
 }
 
 // showErrorDialog displays error messages
@@ -313,38 +309,33 @@ func (bar *ServerStatusBar) AutoGenerateQR() {
 	time.Sleep(1 * time.Second)
 	
 	if !bar.serverManager.IsRunning {
-		log.Println("⚠️ Cannot auto-generate QR: server not running")
+		logger.Warn("Cannot show the pairing QR code, the server is not running")
 		return
 	}
 
-	log.Println("🔑 Auto-generating QR code for seamless device pairing...")
-
 	// Update UI to reflect server running state
 	bar.updateServerStatus()
 
 	// Generate QR code using ServerManager
 	qrBytes, jsonData, err := bar.serverManager.GenerateQRCode()
 	if err != nil {
-		log.Printf("❌ Auto QR generation failed: %v", err)
+		logger.Error("QR code generation failed", "err", err)
 		return
 	}
 
 	// Debug: Check if we actually have QR code data
-	log.Printf("🔍 Auto-generated QR code: %d bytes", len(qrBytes))
 	if len(qrBytes) == 0 {
-		log.Println("❌ Auto-generated QR code is empty!")
+		logger.Error("QR code is empty")
 		return
 	}
 
 	// Automatically show QR code dialog
 	bar.showQRCodeDialog(qrBytes, jsonData)
-	log.Println("✅ QR code auto-displayed - ready for device pairing!")
+	logger.Info("Pairing QR code shown")
 }
 
 // startDeviceMonitoring monitors for device connections to auto-hide QR codes
 func (bar *ServerStatusBar) startDeviceMonitoring() {
-	log.Println("📱 [DEBUG] Starting device monitoring for QR auto-hide")
-	
 	go func() {
 		var lastDeviceCount int
 		
@@ -357,7 +348,7 @@ func (bar *ServerStatusBar) startDeviceMonitoring() {
 			
 			// If device count increased (new device connected) and QR window is open
 			if currentDeviceCount > lastDeviceCount && bar.qrWindow != nil {
-				log.Printf("📱 [QR AUTO-HIDE] Device connected! Closing QR code window (devices: %d → %d)", lastDeviceCount, currentDeviceCount)
+				logger.Info("Device connected, closing the QR code window", "devices", currentDeviceCount)
 				
 				// Close QR window on UI thread
 				go func() {
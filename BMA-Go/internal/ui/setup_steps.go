@@ -2,12 +2,14 @@ package ui
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"image"
 	_ "image/png"
-	"log"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -15,7 +17,9 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
-	"bma-go/internal/models"
+	"bma-core/pairing"
+	"bma-core/settings"
+	"bma-go/internal/server"
 )
 
 // WelcomeStep - Step 1: Welcome screen
@@ -67,15 +71,22 @@ func (s *WelcomeStep) GetNextAction() func() { return nil }
 // TailscaleStep - Step 2: Tailscale installation
 type TailscaleStep struct {
 	content        fyne.CanvasObject
+	config         *settings.Config
 	isInstalled    bool
 	statusLabel    *widget.Label
 	downloadButton *widget.Button
 	recheckButton  *widget.Button
+	embeddedButton *widget.Button
+	authContainer  *fyne.Container
+	authURLLabel   *widget.Label
+	authQRImage    *canvas.Image
 	onStateChange  func() // Callback when installation state changes
 }
 
-func NewTailscaleStep() *TailscaleStep {
-	return &TailscaleStep{}
+func NewTailscaleStep(config *settings.Config) *TailscaleStep {
+	return &TailscaleStep{
+		config: config,
+	}
 }
 
 func (s *TailscaleStep) GetContent() fyne.CanvasObject {
@@ -99,13 +110,34 @@ func (s *TailscaleStep) GetContent() fyne.CanvasObject {
 			s.checkTailscaleInstallation()
 		})
 		
+		// Built-in node avoids needing tailscaled on the host (e.g. inside Flatpak)
+		s.embeddedButton = widget.NewButton("Use Built-in Tailscale", func() {
+			s.startEmbeddedTailscale()
+		})
+		if !server.EmbeddedTailscaleSupported() {
+			s.embeddedButton.Hide()
+		}
+		
+		s.authURLLabel = widget.NewLabel("")
+		s.authURLLabel.Wrapping = fyne.TextWrapBreak
+		s.authQRImage = canvas.NewImageFromImage(nil)
+		s.authQRImage.FillMode = canvas.ImageFillOriginal
+		s.authQRImage.SetMinSize(fyne.NewSize(200, 200))
+		s.authContainer = container.NewVBox(
+			widget.NewLabel("Scan to add BMA to your tailnet:"),
+			container.NewCenter(s.authQRImage),
+			s.authURLLabel,
+		)
+		s.authContainer.Hide()
+		
 		s.content = container.NewVBox(
 			widget.NewCard("", "", container.NewVBox(
 				title,
 				description,
 				widget.NewSeparator(),
 				s.statusLabel,
-				container.NewHBox(s.downloadButton, s.recheckButton),
+				container.NewHBox(s.downloadButton, s.recheckButton, s.embeddedButton),
+				s.authContainer,
 			)),
 		)
 	}
@@ -113,6 +145,19 @@ func (s *TailscaleStep) GetContent() fyne.CanvasObject {
 }
 
 func (s *TailscaleStep) checkTailscaleInstallation() {
+	// Built-in node was already authenticated on a previous visit
+	if s.config.UsesEmbeddedTailscale() {
+		s.isInstalled = true
+		s.statusLabel.SetText("✅ Built-in Tailscale is connected!")
+		s.downloadButton.Hide()
+		s.recheckButton.Hide()
+		s.embeddedButton.Hide()
+		if s.onStateChange != nil {
+			s.onStateChange()
+		}
+		return
+	}
+	
 	// Check multiple ways Tailscale might be installed
 	s.isInstalled = s.isTailscaleInstalled()
 	
@@ -134,6 +179,83 @@ func (s *TailscaleStep) checkTailscaleInstallation() {
 	}
 }
 
+// startEmbeddedTailscale starts BMA's own tailnet node and walks the user through authentication
+func (s *TailscaleStep) startEmbeddedTailscale() {
+	stateDir, err := settings.GetTailscaleStateDir()
+	if err != nil {
+		s.statusLabel.SetText(fmt.Sprintf("❌ Cannot create Tailscale state folder: %v", err))
+		return
+	}
+	
+	s.embeddedButton.Disable()
+	s.statusLabel.SetText("Starting built-in Tailscale...")
+	
+	go func() {
+		defer s.embeddedButton.Enable()
+		
+		node := server.NewEmbeddedTailscale(stateDir, s.config.EmbeddedTailscaleHostname())
+		if err := node.Start(); err != nil {
+			logger.Error("Failed to start embedded Tailscale", "err", err)
+			s.statusLabel.SetText(fmt.Sprintf("❌ Built-in Tailscale failed: %v", err))
+			return
+		}
+		// The server starts its own node from the same state after setup
+		defer node.Close()
+		
+		deadline := time.Now().Add(10 * time.Minute)
+		shownAuthURL := ""
+		for time.Now().Before(deadline) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			status, err := node.Status(ctx)
+			cancel()
+			
+			if err != nil {
+				logger.Warn("Embedded Tailscale status check failed", "err", err)
+			} else if status.IsRunning() {
+				s.onEmbeddedTailscaleReady(status)
+				return
+			} else if status.NeedsLogin() && status.AuthURL != "" && status.AuthURL != shownAuthURL {
+				shownAuthURL = status.AuthURL
+				s.showEmbeddedAuthURL(status.AuthURL)
+			}
+			
+			time.Sleep(2 * time.Second)
+		}
+		
+		s.statusLabel.SetText("❌ Timed out waiting for Tailscale login. Please try again.")
+	}()
+}
+
+// showEmbeddedAuthURL displays the login URL of the built-in node as text and QR code
+func (s *TailscaleStep) showEmbeddedAuthURL(authURL string) {
+	s.statusLabel.SetText("🔑 Log in to Tailscale to add this computer to your tailnet")
+	s.authURLLabel.SetText(authURL)
+	
+	if qrBytes, err := pairing.GenerateSimpleQR(authURL, 200); err == nil {
+		if img, _, err := image.Decode(bytes.NewReader(qrBytes)); err == nil {
+			s.authQRImage.Image = img
+			s.authQRImage.Refresh()
+		}
+	}
+	
+	s.authContainer.Show()
+}
+
+// onEmbeddedTailscaleReady records the built-in node in the config once it is authenticated
+func (s *TailscaleStep) onEmbeddedTailscaleReady(status server.EmbeddedTailscaleStatus) {
+	logger.Info("Embedded Tailscale authenticated", "host", status.Host())
+	
+	if err := s.config.SetTailscaleMode(settings.TailscaleModeEmbedded); err != nil {
+		logger.Error("Failed to save the Tailscale mode", "err", err)
+		s.statusLabel.SetText("❌ Failed to save Tailscale settings")
+		return
+	}
+	
+	s.authContainer.Hide()
+	s.checkTailscaleInstallation()
+	s.statusLabel.SetText(fmt.Sprintf("✅ Built-in Tailscale connected as %s", status.Host()))
+}
+
 // isTailscaleInstalled checks for Tailscale installation in multiple ways
 func (s *TailscaleStep) isTailscaleInstalled() bool {
 	// Method 1: Check if running in Flatpak and use flatpak-spawn
@@ -266,16 +388,16 @@ func (s *AndroidAppStep) GetContent() fyne.CanvasObject {
 
 func (s *AndroidAppStep) createGitHubQRCode() fyne.CanvasObject {
 	// Generate QR code for GitHub repository
-	qrBytes, err := models.GenerateSimpleQR("https://github.com/picccassso/BasicMusicStreamingApp", 200)
+	qrBytes, err := pairing.GenerateSimpleQR("https://github.com/picccassso/BasicMusicStreamingApp", 200)
 	if err != nil {
-		log.Printf("Error generating QR code: %v", err)
+		logger.Error("Failed to generate the QR code", "err", err)
 		return widget.NewLabel("📱 QR Code Generation Failed\n(Visit GitHub manually)")
 	}
 	
 	// Convert bytes to image
 	img, _, err := image.Decode(bytes.NewReader(qrBytes))
 	if err != nil {
-		log.Printf("Error decoding QR image: %v", err)
+		logger.Error("Failed to decode the QR code", "err", err)
 		return widget.NewLabel("📱 QR Code Display Failed\n(Visit GitHub manually)")
 	}
 	
@@ -340,16 +462,16 @@ func (s *PairingStep) createPairingQRCode() fyne.CanvasObject {
 	// In real implementation, this would generate actual pairing data
 	placeholderData := `{"serverUrl": "http://localhost:8008", "token": "setup-placeholder", "expiresAt": "2024-12-31T23:59:59Z"}`
 	
-	qrBytes, err := models.GenerateSimpleQR(placeholderData, 200)
+	qrBytes, err := pairing.GenerateSimpleQR(placeholderData, 200)
 	if err != nil {
-		log.Printf("Error generating pairing QR code: %v", err)
+		logger.Error("Failed to generate the pairing QR code", "err", err)
 		return widget.NewLabel("🔗 Pairing QR Code\n(Will be generated when server starts)")
 	}
 	
 	// Convert bytes to image
 	img, _, err := image.Decode(bytes.NewReader(qrBytes))
 	if err != nil {
-		log.Printf("Error decoding pairing QR image: %v", err)
+		logger.Error("Failed to decode the pairing QR code", "err", err)
 		return widget.NewLabel("🔗 Pairing QR Display Failed\n(Manual pairing available)")
 	}
 	
@@ -370,15 +492,15 @@ func (s *PairingStep) GetNextAction() func() { return nil }
 // MusicLibraryStep - Step 5: Music folder selection
 type MusicLibraryStep struct {
 	content      fyne.CanvasObject
-	config       *models.Config
-	folderPath   string
-	pathLabel    *widget.Label
+	config       *settings.Config
+	rootsBox     *fyne.Container
+	labelEntry   *widget.Entry
 	selectButton *widget.Button
 	window       fyne.Window
 	onStateChange func() // Callback when folder selection changes
 }
 
-func NewMusicLibraryStep(config *models.Config) *MusicLibraryStep {
+func NewMusicLibraryStep(config *settings.Config) *MusicLibraryStep {
 	return &MusicLibraryStep{
 		config: config,
 	}
@@ -389,14 +511,17 @@ func (s *MusicLibraryStep) GetContent() fyne.CanvasObject {
 		title := widget.NewLabelWithStyle("Select Music Library", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
 		
 		description := widget.NewLabelWithStyle(
-			"Choose the folder containing your music files.\nThe app will scan for MP3 files in this folder and subfolders.",
+			"Choose the folders containing your music files.\nAdd as many as you like (internal drive, USB drive, NAS mount);\nthey are scanned for MP3 files into one library.",
 			fyne.TextAlignCenter,
 			fyne.TextStyle{},
 		)
 		
-		s.pathLabel = widget.NewLabel("No folder selected")
+		s.rootsBox = container.NewVBox()
 		
-		s.selectButton = widget.NewButton("Select Music Folder", func() {
+		s.labelEntry = widget.NewEntry()
+		s.labelEntry.SetPlaceHolder("Label (optional, e.g. USB Drive)")
+		
+		s.selectButton = widget.NewButton("Add Music Folder", func() {
 			s.showFolderDialog()
 		})
 		
@@ -405,14 +530,52 @@ func (s *MusicLibraryStep) GetContent() fyne.CanvasObject {
 				title,
 				description,
 				widget.NewSeparator(),
-				s.pathLabel,
+				s.rootsBox,
+				s.labelEntry,
 				s.selectButton,
 			)),
 		)
+		s.refreshRoots()
 	}
 	return s.content
 }
 
+// refreshRoots rebuilds the list of configured library roots
+func (s *MusicLibraryStep) refreshRoots() {
+	s.rootsBox.Objects = nil
+	
+	roots := s.config.GetLibraryRoots()
+	if len(roots) == 0 {
+		s.rootsBox.Add(widget.NewLabel("No folder selected"))
+	}
+	
+	for _, root := range roots {
+		root := root
+		
+		enabledCheck := widget.NewCheck("", func(enabled bool) {
+			if err := s.config.SetLibraryRootEnabled(root.Path, enabled); err != nil {
+				logger.Error("Failed to update the library root", "path", root.Path, "err", err)
+			}
+			s.notifyStateChange()
+		})
+		enabledCheck.SetChecked(root.Enabled)
+		
+		removeButton := widget.NewButton("Remove", func() {
+			if _, err := s.config.RemoveLibraryRoot(root.Path); err != nil {
+				logger.Error("Failed to remove the library root", "path", root.Path, "err", err)
+			}
+			s.refreshRoots()
+			s.notifyStateChange()
+		})
+		
+		s.rootsBox.Add(container.NewBorder(nil, nil, enabledCheck, removeButton,
+			widget.NewLabel(fmt.Sprintf("%s — %s", root.DisplayName(), root.Path)),
+		))
+	}
+	
+	s.rootsBox.Refresh()
+}
+
 func (s *MusicLibraryStep) showFolderDialog() {
 	if s.window == nil {
 		return
@@ -423,19 +586,25 @@ func (s *MusicLibraryStep) showFolderDialog() {
 			return
 		}
 		
-		s.folderPath = folder.Path()
-		s.pathLabel.SetText("Selected: " + s.folderPath)
-		
 		// Save to config
-		s.config.SetMusicFolder(s.folderPath)
-		
-		// Notify wizard that state has changed
-		if s.onStateChange != nil {
-			s.onStateChange()
+		if err := s.config.AddLibraryRoot(folder.Path(), strings.TrimSpace(s.labelEntry.Text)); err != nil {
+			dialog.ShowError(err, s.window)
+			return
 		}
+		
+		s.labelEntry.SetText("")
+		s.refreshRoots()
+		s.notifyStateChange()
 	}, s.window)
 }
 
+// notifyStateChange tells the wizard that the folder selection has changed
+func (s *MusicLibraryStep) notifyStateChange() {
+	if s.onStateChange != nil {
+		s.onStateChange()
+	}
+}
+
 func (s *MusicLibraryStep) SetWindow(window fyne.Window) {
 	s.window = window
 }
@@ -448,7 +617,7 @@ func (s *MusicLibraryStep) SetStateChangeCallback(callback func()) {
 func (s *MusicLibraryStep) GetTitle() string { return "Music Library" }
 func (s *MusicLibraryStep) OnEnter()         {}
 func (s *MusicLibraryStep) OnExit()          {}
-func (s *MusicLibraryStep) CanContinue() bool { return s.folderPath != "" }
+func (s *MusicLibraryStep) CanContinue() bool { return len(s.config.EnabledLibraryRoots()) > 0 }
 func (s *MusicLibraryStep) GetNextAction() func() { return nil }
 
 // SetupCompleteStep - Step 6: Setup completion
@@ -1,20 +1,19 @@
 package ui
 
 import (
-	"log"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 
-	"bma-go/internal/models"
+	"bma-core/settings"
 )
 
 // SetupWizard represents the setup wizard controller
 type SetupWizard struct {
 	window         fyne.Window
-	config         *models.Config
+	config         *settings.Config
 	contentContainer *fyne.Container
 	currentStep    int
 	steps          []SetupStep
@@ -34,7 +33,7 @@ type SetupStep interface {
 }
 
 // NewSetupWizard creates a new setup wizard
-func NewSetupWizard(config *models.Config, onComplete func()) *SetupWizard {
+func NewSetupWizard(config *settings.Config, onComplete func()) *SetupWizard {
 	wizard := &SetupWizard{
 		config:      config,
 		currentStep: 0,
@@ -62,7 +61,7 @@ func (sw *SetupWizard) SetWindow(window fyne.Window) {
 // initializeSteps creates all the setup steps
 func (sw *SetupWizard) initializeSteps() {
 	// Create Tailscale step with state change callback
-	tailscaleStep := NewTailscaleStep()
+	tailscaleStep := NewTailscaleStep(sw.config)
 	tailscaleStep.SetStateChangeCallback(func() {
 		sw.updateButtonStates()
 	})
@@ -246,19 +245,16 @@ func (sw *SetupWizard) updateStepContent(newContent fyne.CanvasObject, newStep i
 
 // completeSetup finalizes the setup process
 func (sw *SetupWizard) completeSetup() {
-	log.Println("🔧 [DEBUG] completeSetup() called - Start Streaming clicked")
+	logger.Debug("Completing setup")
 	
 	// Mark setup as complete in config
 	if err := sw.config.MarkSetupComplete(); err != nil {
-		log.Printf("🔧 [DEBUG] MarkSetupComplete failed: %v", err)
+		logger.Error("Failed to mark setup complete", "err", err)
 		return
 	}
-	log.Println("🔧 [DEBUG] MarkSetupComplete succeeded")
 	
 	// Call completion callback
 	if sw.onComplete != nil {
-		log.Println("🔧 [DEBUG] Calling onComplete callback")
 		sw.onComplete()
-		log.Println("🔧 [DEBUG] onComplete callback finished")
 	}
 } 
\ No newline at end of file
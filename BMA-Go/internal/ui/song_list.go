@@ -1,16 +1,19 @@
 package ui
 
 import (
+	"os"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	
-	"bma-go/internal/models"
+	"bma-core/library"
 )
 
 // SongListView displays the music library with album folder organization
 type SongListView struct {
-	musicLibrary  *models.MusicLibrary
+	musicLibrary  *library.MusicLibrary
 	content       *fyne.Container
 	folderButton  *widget.Button
 	songList      *widget.List
@@ -19,7 +22,7 @@ type SongListView struct {
 }
 
 // NewSongListView creates a new song list view
-func NewSongListView(musicLibrary *models.MusicLibrary) *SongListView {
+func NewSongListView(musicLibrary *library.MusicLibrary) *SongListView {
 	slv := &SongListView{
 		musicLibrary: musicLibrary,
 	}
@@ -85,8 +88,59 @@ func (slv *SongListView) onSelectFolder() {
 		return
 	}
 	
-	// Open the folder selection dialog using the MusicLibrary
-	slv.musicLibrary.ShowFolderSelectionDialog(slv.parentWindow)
+	slv.showFolderSelectionDialog()
+}
+
+// showFolderSelectionDialog opens a folder selection dialog and scans the chosen folder (equivalent to selectFolder() in Swift)
+func (slv *SongListView) showFolderSelectionDialog() {
+	logger.Debug("Opening the folder selection dialog")
+	
+	// Create folder open dialog
+	folderDialog := dialog.NewFolderOpen(func(folder fyne.ListableURI, err error) {
+		if err != nil {
+			logger.Error("Folder selection failed", "err", err)
+			return
+		}
+		
+		if folder == nil {
+			logger.Debug("Folder selection cancelled")
+			return
+		}
+		
+		// Get the folder path
+		folderPath := folder.Path()
+		logger.Info("Folder selected", "path", folderPath)
+		
+		// Validate the folder path
+		if folderPath == "" {
+			logger.Warn("Folder selection returned an empty path")
+			return
+		}
+		
+		// Check if folder exists and is accessible
+		if _, err := os.Stat(folderPath); err != nil {
+			logger.Error("Cannot access the selected folder", "path", folderPath, "err", err)
+			return
+		}
+		
+		// Start scanning the selected folder in a goroutine to avoid blocking UI
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("Panic while scanning the folder", "panic", r)
+				}
+			}()
+			
+			slv.musicLibrary.SelectFolder(folderPath)
+		}()
+		
+	}, slv.parentWindow)
+	
+	// Configure dialog
+	folderDialog.Resize(fyne.NewSize(800, 600))        // Reasonable dialog size
+	
+	// Show the dialog
+	folderDialog.Show()
 }
 
 // LoadMusicLibrary loads and displays the music library
@@ -1,39 +1,45 @@
 package main
 
 import (
-	"log"
-
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 
-	"bma-go/internal/models"
+	"bma-core/logging"
+	"bma-core/settings"
 	"bma-go/internal/ui"
 )
 
-func main() {
-	log.Println("🚀 Starting BMA (Basic Music App) - Go+Fyne Edition")
+// logger tags the app's log lines with component=app
+var logger = logging.Component("app")
 
+func main() {
 	// Load configuration
-	config, err := models.LoadConfig()
+	config, err := settings.LoadConfig()
 	if err != nil {
-		log.Printf("⚠️ Error loading config: %v", err)
-		config = &models.Config{SetupComplete: false}
+		logger.Warn("Failed to load config, using defaults", "err", err)
+		config = &settings.Config{SetupComplete: false}
 	}
 
+	// Log level and format come from the config file, BMA_LOG_LEVEL and BMA_LOG_FORMAT
+	if err := logging.Setup(config.GetLogOptions()); err != nil {
+		logger.Warn("Invalid log settings, using the defaults", "err", err)
+	}
+	logger.Info("Starting BMA (Basic Music App) - Go+Fyne Edition")
+
 	// Create Fyne application
 	fyneApp := app.New()
 
 	// Check if setup is complete
 	if !config.SetupComplete {
-		log.Println("🔧 First run detected - starting setup wizard")
+		logger.Info("First run detected, starting the setup wizard")
 		showSetupWizard(fyneApp, config)
 	} else {
-		log.Println("✅ Setup complete - starting main application")
+		logger.Info("Setup complete, starting the main application")
 		showMainApplication(fyneApp, config)
 	}
 }
 
-func showSetupWizard(fyneApp fyne.App, config *models.Config) {
+func showSetupWizard(fyneApp fyne.App, config *settings.Config) {
 	// Create setup window
 	setupWindow := fyneApp.NewWindow("BMA Setup")
 	setupWindow.Resize(fyne.NewSize(600, 500))
@@ -51,28 +57,24 @@ func showSetupWizard(fyneApp fyne.App, config *models.Config) {
 	
 	// Handle app termination cleanup
 	mainWindow.SetCloseIntercept(func() {
-		log.Println("🛑 App terminating - ensuring server shutdown...")
+		logger.Info("App terminating, stopping the server")
 		mainUI.Cleanup()
-		log.Println("✅ App termination cleanup completed")
+		logger.Info("App termination cleanup completed")
 		fyneApp.Quit()
 	})
 	
 	// Create setup wizard with transition callback
 	wizard := ui.NewSetupWizard(config, func() {
 		// On setup completion, hide setup window and show main app
-		log.Println("✅ Setup completed - transitioning to main application")
-		log.Println("🔧 [DEBUG] About to call LoadMusicLibrary()")
+		logger.Info("Setup completed, switching to the main application")
 		
 		// Load the music library now that setup is complete
 		mainUI.LoadMusicLibrary()
 		
-		log.Println("🔧 [DEBUG] LoadMusicLibrary() call returned")
-		log.Println("🔧 [DEBUG] About to hide setup window")
-		
 		setupWindow.Hide()
 		mainWindow.Show()
 		
-		log.Println("🔧 [DEBUG] Windows switched - transition complete")
+		logger.Debug("Windows switched")
 	})
 	
 	wizard.SetWindow(setupWindow)
@@ -82,7 +84,7 @@ func showSetupWizard(fyneApp fyne.App, config *models.Config) {
 	setupWindow.ShowAndRun()
 }
 
-func showMainApplication(fyneApp fyne.App, config *models.Config) {
+func showMainApplication(fyneApp fyne.App, config *settings.Config) {
 	// Create and show main window
 	mainWindow := fyneApp.NewWindow("BMA - Basic Music App")
 	mainWindow.Resize(fyne.NewSize(450, 320))  // More compact size
@@ -101,9 +103,9 @@ func showMainApplication(fyneApp fyne.App, config *models.Config) {
 
 	// Handle app termination cleanup
 	mainWindow.SetCloseIntercept(func() {
-		log.Println("🛑 App terminating - ensuring server shutdown...")
+		logger.Info("App terminating, stopping the server")
 		mainUI.Cleanup()
-		log.Println("✅ App termination cleanup completed")
+		logger.Info("App termination cleanup completed")
 		fyneApp.Quit()
 	})
 
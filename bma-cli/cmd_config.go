@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"bma-core/logging"
+	"bma-core/settings"
+)
+
+// configKeys lists the keys accepted by `bma-cli config get/set`
+var configKeys = []string{"setupComplete", "musicFolder", "tailscaleIP", "port", "logLevel", "logFormat", "metricsAddr"}
+
+// runConfig implements `bma-cli config`
+func runConfig(args []string) int {
+	if len(args) == 0 {
+		printConfigUsage(os.Stderr)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "get":
+		if len(args) > 2 {
+			printConfigUsage(os.Stderr)
+			return exitUsage
+		}
+		return runConfigGet(args[1:])
+	case "set":
+		if len(args) != 3 {
+			printConfigUsage(os.Stderr)
+			return exitUsage
+		}
+		return runConfigSet(args[1], args[2])
+	case "path":
+		configPath, err := settings.GetConfigPath()
+		if err != nil {
+			return fail("Failed to locate config: %v", err)
+		}
+		fmt.Println(configPath)
+		return exitOK
+	case "help", "-h", "-help", "--help":
+		printConfigUsage(os.Stdout)
+		return exitOK
+	default:
+		fmt.Fprintf(os.Stderr, "bma-cli config: unknown subcommand %q\n", args[0])
+		printConfigUsage(os.Stderr)
+		return exitUsage
+	}
+}
+
+// printConfigUsage writes the help text for `bma-cli config`
+func printConfigUsage(w *os.File) {
+	fmt.Fprintln(w, "Usage: bma-cli config get [key]")
+	fmt.Fprintln(w, "       bma-cli config set <key> <value>")
+	fmt.Fprintln(w, "       bma-cli config path")
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "Keys: %v\n", configKeys)
+}
+
+// runConfigGet prints one value or the whole configuration
+func runConfigGet(args []string) int {
+	config, err := settings.LoadConfig()
+	if err != nil {
+		return fail("Failed to load config: %v", err)
+	}
+
+	if len(args) == 0 {
+		for _, key := range configKeys {
+			value, _ := configValue(config, key)
+			fmt.Printf("%s = %s\n", key, value)
+		}
+		return exitOK
+	}
+
+	value, ok := configValue(config, args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "bma-cli config: unknown key %q (valid keys: %v)\n", args[0], configKeys)
+		return exitUsage
+	}
+	fmt.Println(value)
+	return exitOK
+}
+
+// runConfigSet validates and stores a single value
+func runConfigSet(key, value string) int {
+	config, err := settings.LoadConfig()
+	if err != nil {
+		return fail("Failed to load config: %v", err)
+	}
+
+	switch key {
+	case "setupComplete":
+		complete, err := strconv.ParseBool(value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bma-cli config: %s must be true or false\n", key)
+			return exitUsage
+		}
+		config.SetupComplete = complete
+	case "musicFolder":
+		// Setting it replaces all roots, which is only what the user means with a single one
+		if roots := config.GetLibraryRoots(); len(roots) > 1 {
+			fmt.Fprintf(os.Stderr, "bma-cli config: %s can't be set with %d library roots, use 'bma-cli roots add' and 'bma-cli roots remove'\n", key, len(roots))
+			return exitUsage
+		}
+		if value != "" {
+			value = expandPath(value)
+			if info, err := os.Stat(value); err != nil || !info.IsDir() {
+				return fail("Music folder not found: %s", value)
+			}
+		}
+		config.ResetLibraryRoots(value)
+	case "tailscaleIP":
+		config.TailscaleIP = value
+	case "port":
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 0 || port > 65535 {
+			fmt.Fprintf(os.Stderr, "bma-cli config: %s must be a number between 0 and 65535 (0 = default)\n", key)
+			return exitUsage
+		}
+		config.Port = port
+	case "logLevel":
+		level, err := logging.ParseLevel(value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bma-cli config: %v\n", err)
+			return exitUsage
+		}
+		config.LogLevel = strings.ToLower(level.String())
+	case "logFormat":
+		format, err := logging.ParseFormat(value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bma-cli config: %v\n", err)
+			return exitUsage
+		}
+		config.LogFormat = format
+	case "metricsAddr":
+		if value != "" {
+			if _, _, err := net.SplitHostPort(value); err != nil {
+				fmt.Fprintf(os.Stderr, "bma-cli config: %s must be host:port, e.g. :9108, or empty for %s\n", key, settings.DefaultMetricsAddr)
+				return exitUsage
+			}
+		}
+		config.MetricsAddr = value
+	default:
+		fmt.Fprintf(os.Stderr, "bma-cli config: unknown key %q (valid keys: %v)\n", key, configKeys)
+		return exitUsage
+	}
+
+	if err := config.SaveConfig(); err != nil {
+		return fail("Failed to save config: %v", err)
+	}
+
+	newValue, _ := configValue(config, key)
+	fmt.Printf("✅ %s = %s\n", key, newValue)
+	return exitOK
+}
+
+// configValue returns the string form of a config key
+func configValue(config *settings.Config, key string) (string, bool) {
+	switch key {
+	case "setupComplete":
+		return strconv.FormatBool(config.SetupComplete), true
+	case "musicFolder":
+		return config.MusicFolder, true
+	case "tailscaleIP":
+		return config.TailscaleIP, true
+	case "port":
+		return strconv.Itoa(config.GetPort()), true
+	case "logLevel":
+		level, _ := logging.ParseLevel(config.LogLevel)
+		return strings.ToLower(level.String()), true
+	case "logFormat":
+		format, _ := logging.ParseFormat(config.LogFormat)
+		return format, true
+	case "metricsAddr":
+		return config.GetMetricsAddr(), true
+	default:
+		return "", false
+	}
+}
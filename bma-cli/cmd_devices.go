@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"bma-cli/internal/server"
+	"bma-core/pairing"
+)
+
+// runPair implements `bma-cli pair`
+func runPair(args []string) int {
+	fs := newFlagSet("pair", "[flags]")
+	asJSON := fs.Bool("json", false, "print only the pairing JSON")
+	verbose := fs.Bool("verbose", false, "show server debug logging")
+	qr := addQRFlags(fs)
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "pair takes no arguments")
+	}
+	style, err := qr.parse()
+	if qr.enabled() && err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	quietLogs(*verbose)
+	config := loadConfig()
+
+	// The token is written to the shared device store, so a running server accepts it
+	pairingData, err := server.NewMusicServer(config, nil).PairingData()
+	if err != nil {
+		return fail("Failed to create pairing token: %v", err)
+	}
+
+	if *asJSON {
+		fmt.Println(pairingData)
+		return exitOK
+	}
+
+	var info struct {
+		ServerURL string `json:"serverUrl"`
+		Token     string `json:"token"`
+		ExpiresAt string `json:"expiresAt"`
+	}
+	json.Unmarshal([]byte(pairingData), &info)
+
+	if qr.enabled() {
+		qrText, err := server.RenderTerminalQR(pairingData, style, qr.invert)
+		if err != nil {
+			return fail("Failed to generate QR code: %v", err)
+		}
+		fmt.Println("📱 Scan with the BMA app to pair this device:")
+		fmt.Print(qrText)
+		fmt.Println("")
+	}
+
+	fmt.Println("📱 Pairing token created")
+	fmt.Printf("Server URL: %s\n", info.ServerURL)
+	fmt.Printf("Token:      %s\n", info.Token)
+	fmt.Printf("Expires:    %s\n", info.ExpiresAt)
+	fmt.Println("")
+	fmt.Println("Pairing data:")
+	fmt.Println(pairingData)
+	if !qr.enabled() {
+		fmt.Println("")
+		fmt.Printf("Or scan the QR code at %s/qr while the server is running.\n", info.ServerURL)
+	}
+	return exitOK
+}
+
+// runDevices implements `bma-cli devices`
+func runDevices(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: bma-cli devices list [--json]")
+		fmt.Fprintln(os.Stderr, "       bma-cli devices revoke <device-id-prefix> | --all")
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		return runDevicesList(args[1:])
+	case "revoke", "rm":
+		return runDevicesRevoke(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Println("Usage: bma-cli devices list [--json]")
+		fmt.Println("       bma-cli devices revoke <device-id-prefix> | --all")
+		return exitOK
+	default:
+		fmt.Fprintf(os.Stderr, "bma-cli devices: unknown subcommand %q\n", args[0])
+		return exitUsage
+	}
+}
+
+// runDevicesList prints all paired devices
+func runDevicesList(args []string) int {
+	fs := newFlagSet("devices list", "[flags]")
+	asJSON := fs.Bool("json", false, "print devices as JSON")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	store, err := pairing.LoadDeviceStore()
+	if err != nil {
+		return fail("Failed to load paired devices: %v", err)
+	}
+	devices := store.List()
+
+	if *asJSON {
+		// Never print full tokens, they grant access to the library
+		type deviceJSON struct {
+			ID         string     `json:"id"`
+			DeviceName string     `json:"deviceName,omitempty"`
+			IPAddress  string     `json:"ipAddress,omitempty"`
+			PairedAt   time.Time  `json:"pairedAt"`
+			ExpiresAt  time.Time  `json:"expiresAt"`
+			LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+			Expired    bool       `json:"expired"`
+		}
+		result := make([]deviceJSON, 0, len(devices))
+		for _, device := range devices {
+			entry := deviceJSON{
+				ID:         device.ID.String(),
+				DeviceName: device.DeviceName,
+				IPAddress:  device.IPAddress,
+				PairedAt:   device.PairedAt,
+				ExpiresAt:  device.ExpiresAt,
+				Expired:    device.IsExpired(),
+			}
+			if !device.LastSeenAt.IsZero() {
+				lastSeen := device.LastSeenAt
+				entry.LastSeenAt = &lastSeen
+			}
+			result = append(result, entry)
+		}
+		printJSON(result)
+		return exitOK
+	}
+
+	if len(devices) == 0 {
+		fmt.Println("No paired devices. Run 'bma-cli pair' to pair one.")
+		return exitOK
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDEVICE\tIP\tPAIRED\tLAST SEEN\tSTATUS")
+	for _, device := range devices {
+		name := device.DeviceName
+		if name == "" {
+			name = "-"
+		}
+		ip := device.IPAddress
+		if ip == "" {
+			ip = "-"
+		}
+		lastSeen := "never"
+		if !device.LastSeenAt.IsZero() {
+			lastSeen = formatAge(device.LastSeenAt)
+		}
+		status := "active"
+		if device.IsExpired() {
+			status = "expired"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			device.ID.String()[:8], name, ip, formatAge(device.PairedAt), lastSeen, status)
+	}
+	w.Flush()
+	return exitOK
+}
+
+// runDevicesRevoke removes one device or all devices from the store
+func runDevicesRevoke(args []string) int {
+	fs := newFlagSet("devices revoke", "<device-id-prefix> | --all")
+	all := fs.Bool("all", false, "revoke every paired device")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	if *all == (fs.NArg() == 1) || fs.NArg() > 1 {
+		return usageError(fs, "give exactly one device ID prefix or --all")
+	}
+
+	store, err := pairing.LoadDeviceStore()
+	if err != nil {
+		return fail("Failed to load paired devices: %v", err)
+	}
+
+	if *all {
+		count, err := store.RevokeAll()
+		if err != nil {
+			return fail("Failed to revoke devices: %v", err)
+		}
+		fmt.Printf("🗑️ Revoked %d devices\n", count)
+		return exitOK
+	}
+
+	device, err := store.Revoke(fs.Arg(0))
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	name := device.DeviceName
+	if name == "" {
+		name = "unused token"
+	}
+	fmt.Printf("🗑️ Revoked %s (%s)\n", device.ID.String()[:8], name)
+	return exitOK
+}
+
+// formatAge renders a timestamp as a short relative age
+func formatAge(t time.Time) string {
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
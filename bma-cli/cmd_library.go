@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"bma-core/library"
+)
+
+// scanAlbum is the JSON form of an album in `bma-cli scan --json`
+type scanAlbum struct {
+	Name   string   `json:"name"`
+	Artist string   `json:"artist,omitempty"`
+	Year   int      `json:"year,omitempty"`
+	Tracks []string `json:"tracks"`
+}
+
+// runScan implements `bma-cli scan`
+func runScan(args []string) int {
+	flags := newFlagSet("scan", "[flags]")
+	dir := flags.String("dir", "", "music folder to scan (default: all enabled library roots)")
+	dryRun := flags.Bool("dry-run", false, "only list the files that would be scanned, without reading tags")
+	asJSON := flags.Bool("json", false, "print the result as JSON")
+	verbose := flags.Bool("verbose", false, "show scanner debug logging")
+	report := flags.Bool("report", false, "list unreadable files and metadata problems found by the scan")
+	workers := flags.Int("workers", 0, "number of files whose tags are read in parallel (default: automatic)")
+	if code := parseFlags(flags, args); code >= 0 {
+		return code
+	}
+	if flags.NArg() > 0 {
+		return usageError(flags, "scan takes no arguments")
+	}
+
+	quietLogs(*verbose)
+	config := loadConfig()
+
+	if *dir != "" {
+		musicDir := expandPath(*dir)
+		if info, err := os.Stat(musicDir); err != nil || !info.IsDir() {
+			return fail("Music folder not found: %s", musicDir)
+		}
+		config.ResetLibraryRoots(musicDir)
+	}
+
+	roots := config.EnabledLibraryRoots()
+	if len(roots) == 0 {
+		return fail("No library roots configured. Pass --dir, run 'bma-cli roots add <dir>' or 'bma-cli setup'.")
+	}
+
+	if *dryRun {
+		return scanDryRun(roots, config.GetScanOptions(), *asJSON)
+	}
+
+	options := config.GetScanOptions()
+	if *workers > 0 {
+		options.Workers = *workers
+	}
+
+	musicLibrary := library.NewMusicLibrary()
+	musicLibrary.SetScanOptions(options)
+	musicLibrary.SelectRoots(roots)
+
+	albums := musicLibrary.GetAlbums()
+	statuses := musicLibrary.GetRootStatuses()
+	stats := musicLibrary.GetLastScanStats()
+	if *asJSON {
+		result := struct {
+			Roots  []library.RootStatus  `json:"roots"`
+			Songs  int                   `json:"songs"`
+			Albums []scanAlbum           `json:"albums"`
+			Stats  library.ScanStats     `json:"stats"`
+			Health *library.HealthReport `json:"health,omitempty"`
+		}{statuses, musicLibrary.GetSongCount(), make([]scanAlbum, 0, len(albums)), stats, nil}
+		if *report {
+			result.Health = musicLibrary.GetHealthReport()
+		}
+
+		for _, album := range albums {
+			entry := scanAlbum{Name: album.Name, Artist: album.Artist, Year: album.Year}
+			for _, song := range album.Songs {
+				entry.Tracks = append(entry.Tracks, song.DisplayTitle())
+			}
+			result.Albums = append(result.Albums, entry)
+		}
+		printJSON(result)
+	} else {
+		for _, status := range statuses {
+			switch {
+			case status.Offline:
+				fmt.Printf("🔌 %s: offline (%s)\n", status.Path, status.Error)
+			case status.Error != "":
+				fmt.Printf("❌ %s: %s\n", status.Path, status.Error)
+			default:
+				fmt.Printf("📁 %s: %d songs\n", status.Path, status.SongCount)
+			}
+		}
+		for _, album := range albums {
+			artist := album.Artist
+			if artist == "" {
+				artist = "Unknown Artist"
+			}
+			name := album.Name
+			if album.Year > 0 {
+				name = fmt.Sprintf("%s (%d)", name, album.Year)
+			}
+			fmt.Printf("  💿 %s - %s (%d tracks)\n", name, artist, album.TrackCount())
+		}
+		fmt.Printf("🎵 %d songs in %d albums\n", musicLibrary.GetSongCount(), musicLibrary.GetAlbumCount())
+		fmt.Printf("⏱️ Read %d files in %v with %d workers (%.1f files/sec)\n",
+			stats.Files, stats.Duration.Round(time.Millisecond), stats.Workers, stats.FilesPerSecond)
+		if *report {
+			printHealthReport(musicLibrary.GetHealthReport())
+		}
+	}
+
+	if musicLibrary.GetSongCount() == 0 {
+		return fail("No MP3 files found in the library roots")
+	}
+	return exitOK
+}
+
+// printHealthReport lists the issues of a health report grouped by kind
+func printHealthReport(report *library.HealthReport) {
+	if report == nil || len(report.Issues) == 0 {
+		fmt.Println("🩺 No problems found")
+		return
+	}
+
+	fmt.Printf("🩺 %d problems found\n", len(report.Issues))
+	for _, kind := range library.IssueKinds {
+		issues := report.IssuesOfKind(kind)
+		if len(issues) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s (%d):\n", kind.Description(), len(issues))
+		for _, issue := range issues {
+			line := issue.Path
+			if issue.Album != "" && issue.Path == "" {
+				line = issue.Album
+			}
+			if issue.Detail != "" {
+				line += " (" + issue.Detail + ")"
+			}
+			fmt.Printf("  %s\n", line)
+		}
+	}
+}
+
+// scanDryRun lists the files a scan would read under each root without extracting metadata
+func scanDryRun(roots []library.LibraryRoot, options library.ScanOptions, asJSON bool) int {
+	type rootFiles struct {
+		Root  string   `json:"root"`
+		Files []string `json:"files"`
+		Error string   `json:"error,omitempty"`
+	}
+
+	var results []rootFiles
+	total := 0
+	for _, root := range roots {
+		result := rootFiles{Root: root.Path, Files: []string{}}
+		err := library.WalkMusicFiles(root.Path, options, func(path string) error {
+			result.Files = append(result.Files, path)
+			return nil
+		})
+		if err != nil {
+			result.Error = err.Error()
+		}
+		total += len(result.Files)
+		results = append(results, result)
+	}
+
+	if asJSON {
+		printJSON(results)
+	} else {
+		for _, result := range results {
+			if result.Error != "" {
+				fmt.Fprintf(os.Stderr, "❌ %s: %s\n", result.Root, result.Error)
+				continue
+			}
+			for _, file := range result.Files {
+				fmt.Println(file)
+			}
+			fmt.Printf("🔍 %d files would be scanned in %s\n", len(result.Files), result.Root)
+		}
+	}
+
+	if total == 0 {
+		return exitError
+	}
+	return exitOK
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"bma-cli/internal/server"
+)
+
+// printRootsUsage writes the help text for `bma-cli roots`
+func printRootsUsage(w *os.File) {
+	fmt.Fprintln(w, "Usage: bma-cli roots list [--json]")
+	fmt.Fprintln(w, "       bma-cli roots add <dir> [--label NAME]")
+	fmt.Fprintln(w, "       bma-cli roots remove <dir|label>")
+	fmt.Fprintln(w, "       bma-cli roots enable <dir|label>")
+	fmt.Fprintln(w, "       bma-cli roots disable <dir|label>")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Changes take effect the next time the server starts.")
+}
+
+// runRoots implements `bma-cli roots`
+func runRoots(args []string) int {
+	if len(args) == 0 {
+		printRootsUsage(os.Stderr)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		return runRootsList(args[1:])
+	case "add":
+		return runRootsAdd(args[1:])
+	case "remove", "rm":
+		return runRootsChange(args[1:], "remove")
+	case "enable":
+		return runRootsChange(args[1:], "enable")
+	case "disable":
+		return runRootsChange(args[1:], "disable")
+	case "help", "-h", "-help", "--help":
+		printRootsUsage(os.Stdout)
+		return exitOK
+	default:
+		fmt.Fprintf(os.Stderr, "bma-cli roots: unknown subcommand %q\n", args[0])
+		printRootsUsage(os.Stderr)
+		return exitUsage
+	}
+}
+
+// runRootsList prints all configured library roots
+func runRootsList(args []string) int {
+	fs := newFlagSet("roots list", "[flags]")
+	asJSON := fs.Bool("json", false, "print roots as JSON")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	roots := loadConfig().GetLibraryRoots()
+	if *asJSON {
+		printJSON(roots)
+		return exitOK
+	}
+
+	if len(roots) == 0 {
+		fmt.Println("No library roots. Add one with 'bma-cli roots add <dir>'.")
+		return exitOK
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LABEL\tPATH\tSTATUS")
+	for _, root := range roots {
+		status := "enabled"
+		if !root.Enabled {
+			status = "disabled"
+		}
+		if info, err := os.Stat(root.Path); err != nil || !info.IsDir() {
+			status += " (not found)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", root.DisplayName(), root.Path, status)
+	}
+	w.Flush()
+	return exitOK
+}
+
+// runRootsAdd validates a folder and adds it as a library root
+func runRootsAdd(args []string) int {
+	fs := newFlagSet("roots add", "<dir> [--label NAME]")
+	label := fs.String("label", "", "name shown for songs from this root")
+	force := fs.Bool("force", false, "add the folder even if it contains no music yet")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "give exactly one directory")
+	}
+
+	dir := expandPath(fs.Arg(0))
+	fileCount, err := server.ValidateMusicDirectory(dir)
+	if err != nil && !*force {
+		return fail("%s: %v", dir, err)
+	}
+
+	config := loadConfig()
+	if err := config.AddLibraryRoot(dir, *label); err != nil {
+		return fail("%v", err)
+	}
+
+	fmt.Printf("✅ Added %s (%d music files)\n", dir, fileCount)
+	return exitOK
+}
+
+// runRootsChange removes, enables or disables a root by path or label
+func runRootsChange(args []string, action string) int {
+	fs := newFlagSet("roots "+action, "<dir|label>")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "give exactly one directory or label")
+	}
+
+	target := fs.Arg(0)
+	if _, err := os.Stat(target); err == nil {
+		target = expandPath(target)
+	}
+
+	config := loadConfig()
+	if action == "remove" {
+		removed, err := config.RemoveLibraryRoot(target)
+		if err != nil {
+			return fail("%v", err)
+		}
+		fmt.Printf("🗑️ Removed %s (%s)\n", removed.DisplayName(), removed.Path)
+		return exitOK
+	}
+
+	if err := config.SetLibraryRootEnabled(target, action == "enable"); err != nil {
+		return fail("%v", err)
+	}
+	fmt.Printf("✅ %s: %sd\n", target, action)
+	return exitOK
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"bma-cli/internal/server"
+	"bma-core/library"
+	"bma-core/settings"
+)
+
+// runServe implements `bma-cli serve`
+func runServe(args []string) int {
+	fs := newFlagSet("serve", "[flags]")
+	port := fs.Int("port", 0, "HTTP port (default from config, 8080)")
+	musicDir := fs.String("music", "", "music folder to serve instead of the configured one")
+	metricsAddr := fs.String("metrics-addr", "", "serve /metrics on this address, e.g. :9108 (default from config, or "+settings.DefaultMetricsAddr+")")
+	qr := addQRFlags(fs)
+	logs := addLogFlags(fs)
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "serve takes no arguments")
+	}
+	if _, err := qr.parse(); qr.enabled() && err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	config := loadConfig()
+	if err := setupLogging(config, logs); err != nil {
+		return fail("%v", err)
+	}
+	if *port > 0 {
+		config.Port = *port
+	}
+	if *musicDir != "" {
+		config.ResetLibraryRoots(expandPath(*musicDir))
+	}
+	if *metricsAddr != "" {
+		config.MetricsAddr = *metricsAddr
+	}
+
+	if !config.SetupComplete && *musicDir == "" {
+		return fail("Setup is not complete. Run 'bma-cli setup' first or pass --music.")
+	}
+	if len(config.EnabledLibraryRoots()) == 0 {
+		return fail("No enabled library roots. Add one with 'bma-cli roots add <dir>'.")
+	}
+
+	return startMainServer(config, qr)
+}
+
+// runSetup implements `bma-cli setup`
+func runSetup(args []string) int {
+	fs := newFlagSet("setup", "[flags]")
+	mode := fs.String("mode", "web", "setup mode: web (browser wizard) or tty (interactive terminal)")
+	musicDir := fs.String("music", "", "music folder to use (tty mode, skips the prompt)")
+	port := fs.Int("port", 0, "HTTP port for the setup page and server (saved to config)")
+	allowAnyNetwork := fs.Bool("allow-any-network", false, "accept web setup requests from public addresses (default: localhost and private networks only)")
+	qr := addQRFlags(fs)
+	logs := addLogFlags(fs)
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "setup takes no arguments")
+	}
+	if _, err := qr.parse(); qr.enabled() && err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	config := loadConfig()
+	if err := setupLogging(config, logs); err != nil {
+		return fail("%v", err)
+	}
+	if *port > 0 {
+		config.Port = *port
+	}
+
+	switch *mode {
+	case "web":
+		return startSetupServer(config, qr, *allowAnyNetwork)
+	case "tty":
+		return runInteractiveSetup(config, *musicDir, os.Stdin)
+	default:
+		return usageError(fs, "unknown setup mode %q (use web or tty)", *mode)
+	}
+}
+
+// runInteractiveSetup configures the server through terminal prompts
+func runInteractiveSetup(config *settings.Config, musicDir string, input io.Reader) int {
+	reader := bufio.NewReader(input)
+
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("🎵 BMA CLI Setup")
+	fmt.Println(strings.Repeat("=", 60))
+
+	// Step 1: Tailscale
+	if tailscaleIP := server.DetectTailscaleIP(); tailscaleIP != "" {
+		fmt.Printf("✅ Tailscale detected: %s\n", tailscaleIP)
+		config.TailscaleIP = tailscaleIP
+	} else {
+		fmt.Println("⚠️ Tailscale not detected. You can continue, but remote access will be limited.")
+	}
+
+	// Step 2: Music directory
+	fromFlag := musicDir != ""
+	for {
+		if !fromFlag {
+			if config.MusicFolder != "" {
+				fmt.Printf("Music directory path [%s]: ", config.MusicFolder)
+			} else {
+				fmt.Print("Music directory path: ")
+			}
+
+			line, err := reader.ReadString('\n')
+			line = strings.TrimSpace(line)
+			if line == "" && config.MusicFolder != "" {
+				line = config.MusicFolder
+			}
+			if line == "" {
+				if err != nil {
+					fmt.Println()
+					return fail("Setup aborted: no music directory given")
+				}
+				continue
+			}
+			musicDir = line
+		}
+
+		musicDir = expandPath(musicDir)
+		fileCount, err := server.ValidateMusicDirectory(musicDir)
+		if err != nil {
+			if fromFlag {
+				return fail("%s: %v", musicDir, err)
+			}
+			fmt.Printf("❌ %v\n", err)
+			continue
+		}
+
+		fmt.Printf("✅ Valid music directory! Found %d music files.\n", fileCount)
+		break
+	}
+
+	// Step 3: Complete setup
+	config.ResetLibraryRoots(musicDir)
+	config.SetupComplete = true
+	if err := config.SaveConfig(); err != nil {
+		return fail("Failed to save configuration: %v", err)
+	}
+
+	fmt.Println("🎉 Setup complete! Start streaming with: bma-cli serve")
+	return exitOK
+}
+
+// startSetupServer runs the web setup wizard and then hands off to the music server
+func startSetupServer(config *settings.Config, qr *qrOptions, allowAnyNetwork bool) int {
+	logger.Info("Starting the setup web server", "url", fmt.Sprintf("http://localhost:%d/setup", config.GetPort()))
+
+	// Create setup server
+	setupServer := server.NewSetupServer(config)
+	if allowAnyNetwork {
+		logger.Warn("Accepting setup requests from any network")
+		setupServer.AllowAnyNetwork(true)
+	}
+
+	// Handle graceful shutdown
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("🎵 BMA CLI Setup")
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("Setup server is running on port %d\n", config.GetPort())
+	fmt.Println("")
+	fmt.Println("To access the setup page:")
+	fmt.Println("1. Find this device's IP address: hostname -I")
+	fmt.Println("2. Open web browser on any device (same WiFi)")
+	fmt.Printf("3. Go to: http://[YOUR-IP]:%d/setup\n", config.GetPort())
+	fmt.Println("")
+	fmt.Printf("Example: http://192.168.1.100:%d/setup\n", config.GetPort())
+	fmt.Println("")
+	fmt.Printf("🔑 Setup code: %s\n", setupServer.SetupCode())
+	fmt.Println("Enter this code when the setup page asks for it, or open:")
+	fmt.Printf("   http://[YOUR-IP]:%d/setup?code=%s\n", config.GetPort(), setupServer.SetupCode())
+	fmt.Println("")
+	fmt.Println("No browser? Run 'bma-cli setup --mode tty' instead.")
+	fmt.Println(strings.Repeat("=", 60) + "\n")
+
+	// Start the setup server in the background so setup completion can be awaited
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- setupServer.Start()
+	}()
+
+	select {
+	case <-c:
+		signal.Stop(c)
+		logger.Info("Received shutdown signal, stopping the setup server")
+		setupServer.Shutdown()
+		return exitOK
+	case err := <-serverErr:
+		signal.Stop(c)
+		if err != nil && err != http.ErrServerClosed {
+			logger.Error("Failed to start the setup server", "err", err)
+			return exitError
+		}
+		return exitOK
+	case <-setupServer.Completed():
+		signal.Stop(c)
+	}
+
+	// Release the port before the music server binds it
+	logger.Info("Setup complete, stopping the setup server")
+	if err := setupServer.Shutdown(); err != nil {
+		logger.Warn("Setup server did not shut down cleanly", "err", err)
+	}
+	<-serverErr
+
+	return startMainServer(config, qr)
+}
+
+func startMainServer(config *settings.Config, qr *qrOptions) int {
+	logger.Info("Starting the music server")
+
+	// Create music library
+	musicLibrary := library.NewMusicLibrary()
+	musicLibrary.SetScanOptions(config.GetScanOptions())
+
+	// Load music from all enabled library roots. The scan runs in the background so the
+	// server answers /health right away, large or network libraries can take minutes.
+	roots := config.EnabledLibraryRoots()
+	if len(roots) > 0 {
+		for _, root := range roots {
+			logger.Info("Loading music", "root", root.Path)
+		}
+		musicLibrary.SetRoots(roots)
+		if err := musicLibrary.StartScan(context.Background()); err != nil {
+			logger.Warn("Failed to start the library scan", "err", err)
+		}
+
+		// Rescan automatically when a drive is unplugged or comes back
+		stopMonitor := musicLibrary.StartRootMonitor(library.RootMonitorInterval)
+		defer stopMonitor()
+	}
+
+	// Create main server
+	mainServer := server.NewMusicServer(config, musicLibrary)
+
+	// Handle graceful shutdown
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-c
+		logger.Info("Received shutdown signal, stopping the music server")
+		mainServer.Shutdown()
+		os.Exit(exitOK)
+	}()
+
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("🎵 BMA CLI Music Server")
+	fmt.Println(strings.Repeat("=", 60))
+	for _, root := range roots {
+		fmt.Printf("Music Library: %s (%s)\n", root.Path, root.DisplayName())
+	}
+	fmt.Printf("Server running at: http://localhost:%d\n", config.GetPort())
+	if config.TailscaleIP != "" {
+		fmt.Printf("Tailscale access: http://%s:%d\n", config.TailscaleIP, config.GetPort())
+	}
+	if metricsAddr := config.GetMetricsAddr(); strings.HasPrefix(metricsAddr, ":") {
+		fmt.Printf("Metrics: http://localhost%s/metrics\n", metricsAddr)
+	} else {
+		fmt.Printf("Metrics: http://%s/metrics\n", metricsAddr)
+	}
+	fmt.Println("Ready for connections from BMA mobile apps")
+	fmt.Println(strings.Repeat("=", 60) + "\n")
+
+	// Print a pairing QR code so headless servers can pair without a browser
+	if qr.enabled() {
+		style, _ := qr.parse()
+		if err := mainServer.PrintPairingQR(os.Stdout, style, qr.invert); err != nil {
+			logger.Warn("Failed to print pairing QR code", "err", err)
+		}
+
+		if isInteractive(os.Stdin) {
+			fmt.Println("Press Enter to show a new pairing QR code")
+			go reprintQROnEnter(mainServer, style, qr.invert)
+		}
+		fmt.Println()
+	}
+
+	// Start the music server (this will block)
+	if err := mainServer.Start(); err != nil && err != http.ErrServerClosed {
+		logger.Error("Failed to start the music server", "err", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// reprintQROnEnter prints a fresh pairing QR code every time Enter is pressed
+func reprintQROnEnter(mainServer *server.MusicServer, style server.QRStyle, invert bool) {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		if _, err := reader.ReadString('\n'); err != nil {
+			return
+		}
+		if err := mainServer.PrintPairingQR(os.Stdout, style, invert); err != nil {
+			logger.Warn("Failed to print pairing QR code", "err", err)
+		}
+	}
+}
+
+// isInteractive returns true if f is a terminal rather than a pipe or /dev/null
+func isInteractive(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// runStatus implements `bma-cli status`
+func runStatus(args []string) int {
+	fs := newFlagSet("status", "[flags]")
+	addr := fs.String("addr", "", "server address (default http://localhost:<configured port>)")
+	asJSON := fs.Bool("json", false, "print the server info as JSON")
+	timeout := fs.Duration("timeout", 3*time.Second, "request timeout")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "status takes no arguments")
+	}
+
+	baseURL := *addr
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://localhost:%d", loadConfig().GetPort())
+	}
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	client := &http.Client{Timeout: *timeout}
+
+	var health map[string]interface{}
+	if err := getJSON(client, baseURL+"/health", &health); err != nil {
+		if *asJSON {
+			printJSON(map[string]interface{}{"url": baseURL, "reachable": false, "error": err.Error()})
+		} else {
+			fmt.Printf("❌ Server not reachable at %s: %v\n", baseURL, err)
+		}
+		return exitError
+	}
+
+	var info map[string]interface{}
+	if err := getJSON(client, baseURL+"/info", &info); err != nil {
+		return fail("Server at %s is up but /info failed: %v", baseURL, err)
+	}
+
+	healthy := health["status"] == "healthy"
+	if *asJSON {
+		printJSON(map[string]interface{}{"url": baseURL, "reachable": true, "health": health, "info": info})
+	} else {
+		fmt.Printf("✅ %v at %s (status: %v)\n", info["server"], baseURL, health["status"])
+		if library, ok := info["library"].(map[string]interface{}); ok {
+			fmt.Printf("📁 Music folder: %v\n", library["musicPath"])
+			fmt.Printf("🎵 %v songs in %v albums\n", library["songCount"], library["albumCount"])
+		}
+	}
+
+	if !healthy {
+		return exitError
+	}
+	return exitOK
+}
+
+// getJSON fetches url and decodes the JSON body into v
+func getJSON(client *http.Client, url string, v interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
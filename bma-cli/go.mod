@@ -1,10 +1,18 @@
 module bma-cli
 
-go 1.20
+go 1.21
 
 require (
-	github.com/dhowden/tag v0.0.0-20240417053706-3d75831295e8
-	github.com/google/uuid v1.6.0
+	bma-core v0.0.0-00010101000000-000000000000
+	github.com/dhowden/tag v0.0.0-20240417053706-3d75831295e8 // indirect
+	github.com/google/uuid v1.6.0 // indirect
 	github.com/gorilla/mux v1.8.1
 	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 )
+
+require (
+	github.com/klauspost/compress v1.17.11 // indirect
+	golang.org/x/text v0.13.0 // indirect
+)
+
+replace bma-core => ../bma-core
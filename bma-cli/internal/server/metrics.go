@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"bma-core/metrics"
+)
+
+// connectedDeviceWindow is how recently a device must have made a request to count as connected,
+// the same as in the BMA desktop app
+const connectedDeviceWindow = 10 * time.Minute
+
+// registerMetrics adds the device and Tailscale gauges. The API handler adds the request,
+// streaming and library metrics to the same registry.
+func (ms *MusicServer) registerMetrics() {
+	ms.metrics.GaugeFunc("bma_paired_devices", "Devices with a pairing token that has not expired", func() float64 {
+		paired := 0
+		for _, device := range ms.devices.List() {
+			if !device.IsExpired() {
+				paired++
+			}
+		}
+		return float64(paired)
+	})
+	ms.metrics.GaugeFunc("bma_connected_devices", "Paired devices that made a request in the last 10 minutes", func() float64 {
+		cutoff := time.Now().Add(-connectedDeviceWindow)
+		connected := 0
+		for _, device := range ms.devices.List() {
+			if !device.IsExpired() && device.LastSeenAt.After(cutoff) {
+				connected++
+			}
+		}
+		return float64(connected)
+	})
+	// Reads the cached Tailscale IP, a scrape runs the tailscale command at most once a minute
+	ms.metrics.GaugeFunc("bma_tailscale_up", "1 if the server is reachable over Tailscale", func() float64 {
+		return metrics.Bool(ms.getTailscaleURL() != "")
+	})
+}
+
+// startMetricsServer serves /metrics on the configured metrics address, apart from the API
+func (ms *MusicServer) startMetricsServer() {
+	router := http.NewServeMux()
+	router.Handle("/metrics", ms.metrics.Handler())
+	ms.metricsServer = &http.Server{
+		Addr:    ms.config.GetMetricsAddr(),
+		Handler: router,
+	}
+
+	go func() {
+		logger.Info("Metrics server starting", "addr", ms.metricsServer.Addr)
+		if err := ms.metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("Metrics server failed", "addr", ms.metricsServer.Addr, "err", err)
+		}
+	}()
+}
+
+// shutdownMetricsServer stops the metrics listener, if one was started
+func (ms *MusicServer) shutdownMetricsServer(ctx context.Context) error {
+	if ms.metricsServer == nil {
+		return nil
+	}
+	return ms.metricsServer.Shutdown(ctx)
+}
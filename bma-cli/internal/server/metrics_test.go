@@ -0,0 +1,52 @@
+package server
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"bma-core/metrics"
+	"bma-core/pairing"
+	"bma-core/settings"
+)
+
+func TestTailscaleGaugeCachesLookup(t *testing.T) {
+	lookups := 0
+	saved := lookupTailscaleIP
+	lookupTailscaleIP = func() string {
+		lookups++
+		return "100.64.0.5"
+	}
+	t.Cleanup(func() { lookupTailscaleIP = saved })
+
+	ms := &MusicServer{
+		config:  &settings.Config{},
+		devices: pairing.NewMemoryDeviceStore(),
+		metrics: metrics.NewRegistry(),
+	}
+	ms.registerMetrics()
+	scrape := func() {
+		if err := ms.metrics.Write(io.Discard); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		prepare     func()
+		wantLookups int
+	}{
+		{"first scrape looks the IP up", func() {}, 1},
+		{"scrapes within the TTL reuse it", func() { scrape(); ms.getTailscaleURL() }, 1},
+		{"scrape after the TTL looks it up again", func() { ms.tailscaleCheckedAt = time.Now().Add(-tailscaleIPCacheTTL) }, 2},
+		{"configured IP needs no lookup", func() { ms.config.TailscaleIP = "100.64.0.9"; ms.tailscaleCheckedAt = time.Time{} }, 2},
+	}
+
+	for _, tt := range tests {
+		tt.prepare()
+		scrape()
+		if lookups != tt.wantLookups {
+			t.Errorf("%s: %d lookups, want %d", tt.name, lookups, tt.wantLookups)
+		}
+	}
+}
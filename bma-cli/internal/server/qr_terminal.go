@@ -0,0 +1,114 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Terminal QR code rendering for headless servers
+// Features:
+// - Unicode half-blocks: two QR rows per terminal line, so the code fits an 80x24 console
+// - ASCII fallback for terminals without UTF-8 (serial consoles, old SSH clients)
+// - Light modules are drawn filled, which scans correctly on dark terminal themes
+
+// QRStyle selects how a QR code is drawn in the terminal
+type QRStyle int
+
+const (
+	QRStyleAuto    QRStyle = iota // Half-blocks if the locale supports UTF-8, ASCII otherwise
+	QRStyleUnicode                // Unicode half-block characters
+	QRStyleASCII                  // Plain "#" characters, two per module
+)
+
+// ParseQRStyle converts a command line value (auto, unicode, ascii) to a QRStyle
+func ParseQRStyle(value string) (QRStyle, error) {
+	switch strings.ToLower(value) {
+	case "", "auto":
+		return QRStyleAuto, nil
+	case "unicode", "utf8", "utf-8":
+		return QRStyleUnicode, nil
+	case "ascii":
+		return QRStyleASCII, nil
+	default:
+		return QRStyleAuto, fmt.Errorf("unknown QR style %q (use auto, unicode or ascii)", value)
+	}
+}
+
+// RenderTerminalQR encodes data as a QR code drawn with text characters
+func RenderTerminalQR(data string, style QRStyle, invert bool) (string, error) {
+	qr, err := qrcode.New(data, qrcode.Medium)
+	if err != nil {
+		return "", err
+	}
+
+	if style == QRStyleAuto {
+		style = QRStyleASCII
+		if terminalSupportsUnicode() {
+			style = QRStyleUnicode
+		}
+	}
+
+	if style == QRStyleUnicode {
+		return qr.ToSmallString(invert), nil
+	}
+	return renderASCIIQR(qr.Bitmap(), invert), nil
+}
+
+// renderASCIIQR draws each module as two characters so it stays roughly square
+func renderASCIIQR(bits [][]bool, invert bool) string {
+	var builder strings.Builder
+	for y := range bits {
+		for x := range bits[y] {
+			if bits[y][x] != invert {
+				builder.WriteString("  ")
+			} else {
+				builder.WriteString("##")
+			}
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// terminalSupportsUnicode guesses UTF-8 support from the locale environment
+func terminalSupportsUnicode() bool {
+	if os.Getenv("TERM") == "linux" {
+		// The Linux virtual console font usually lacks the half-block glyphs
+		return false
+	}
+
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+	return false
+}
+
+// PrintPairingQR issues a new pairing token and writes its QR code to w
+func (ms *MusicServer) PrintPairingQR(w io.Writer, style QRStyle, invert bool) error {
+	pairing, err := ms.api.Pair()
+	if err != nil {
+		return fmt.Errorf("failed to generate pairing data: %w", err)
+	}
+	pairingData, err := json.Marshal(pairing)
+	if err != nil {
+		return fmt.Errorf("failed to generate pairing data: %w", err)
+	}
+
+	qr, err := RenderTerminalQR(string(pairingData), style, invert)
+	if err != nil {
+		return fmt.Errorf("failed to generate QR code: %w", err)
+	}
+
+	fmt.Fprintln(w, "📱 Scan with the BMA app to pair this device:")
+	fmt.Fprint(w, qr)
+	fmt.Fprintf(w, "Server: %s (token valid until %s)\n", pairing.ServerURL, pairing.ExpiresAt.Local().Format("15:04"))
+	return nil
+}
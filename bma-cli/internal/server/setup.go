@@ -5,32 +5,48 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
-	"log"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
-	"bma-cli/internal/models"
+	"bma-core/api"
+	"bma-core/settings"
 	"github.com/gorilla/mux"
 	"github.com/skip2/go-qrcode"
 )
 
 // SetupServer handles the initial setup process
 type SetupServer struct {
-	config *models.Config
-	server *http.Server
-	router *mux.Router
+	config       *settings.Config
+	server       *http.Server
+	router       *mux.Router
+	completed    chan struct{}
+	completeOnce sync.Once
+	
+	// Setup page protection (see setup_auth.go)
+	codeMutex       sync.Mutex
+	setupCode       string
+	allowAnyNetwork bool
 }
 
 // NewSetupServer creates a new setup server
-func NewSetupServer(config *models.Config) *SetupServer {
+func NewSetupServer(config *settings.Config) *SetupServer {
 	ss := &SetupServer{
-		config: config,
+		config:    config,
+		completed: make(chan struct{}),
 	}
 	
+	setupCode, err := generateSetupCode()
+	if err != nil {
+		logger.Error("Failed to generate setup code", "err", err)
+		os.Exit(1)
+	}
+	ss.setupCode = setupCode
+	
 	ss.setupRoutes()
 	return ss
 }
@@ -39,6 +55,13 @@ func NewSetupServer(config *models.Config) *SetupServer {
 func (ss *SetupServer) setupRoutes() {
 	ss.router = mux.NewRouter()
 	
+	// Requests are logged and errors carry the request ID, like those of the music server
+	ss.router.Use(api.RequestIDMiddleware)
+	ss.router.Use(api.AccessLogMiddleware)
+	
+	// Only accept setup requests from the local network
+	ss.router.Use(ss.networkRestrictionMiddleware)
+	
 	// Static files for setup UI
 	ss.router.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir("web/static/"))))
 	
@@ -46,26 +69,36 @@ func (ss *SetupServer) setupRoutes() {
 	ss.router.HandleFunc("/setup", ss.handleSetupPage).Methods("GET")
 	ss.router.HandleFunc("/", ss.redirectToSetup).Methods("GET")
 	
-	// API endpoints
-	ss.router.HandleFunc("/api/tailscale/status", ss.handleTailscaleStatus).Methods("GET")
-	ss.router.HandleFunc("/api/tailscale/auth", ss.handleTailscaleAuth).Methods("POST")
-	ss.router.HandleFunc("/api/music/validate", ss.handleMusicDirectoryValidation).Methods("POST")
-	ss.router.HandleFunc("/api/setup/complete", ss.handleSetupComplete).Methods("POST")
-	
-	log.Println("✅ Setup routes configured")
+	// API endpoints (require the setup code printed to the console)
+	setupAPI := ss.router.PathPrefix("/api").Subrouter()
+	setupAPI.Use(ss.requireSetupCodeMiddleware)
+	setupAPI.HandleFunc("/tailscale/status", ss.handleTailscaleStatus).Methods("GET")
+	setupAPI.HandleFunc("/tailscale/auth", ss.handleTailscaleAuth).Methods("POST")
+	setupAPI.HandleFunc("/music/validate", ss.handleMusicDirectoryValidation).Methods("POST")
+	setupAPI.HandleFunc("/browse", ss.handleBrowse).Methods("GET")
+	setupAPI.HandleFunc("/browse/count", ss.handleBrowseCount).Methods("GET")
+	setupAPI.HandleFunc("/setup/complete", ss.handleSetupComplete).Methods("POST")
+	
+	logger.Debug("Setup routes configured")
 }
 
 // Start starts the setup server
 func (ss *SetupServer) Start() error {
+	addr := fmt.Sprintf(":%d", ss.config.GetPort())
 	ss.server = &http.Server{
-		Addr:    ":8080",
+		Addr:    addr,
 		Handler: ss.router,
 	}
 	
-	log.Println("🚀 Setup server starting on :8080")
+	logger.Info("Setup server starting", "addr", addr)
 	return ss.server.ListenAndServe()
 }
 
+// Completed is closed once setup finished and the music server can take over
+func (ss *SetupServer) Completed() <-chan struct{} {
+	return ss.completed
+}
+
 // Shutdown gracefully shuts down the server
 func (ss *SetupServer) Shutdown() error {
 	if ss.server == nil {
@@ -85,7 +118,11 @@ func (ss *SetupServer) redirectToSetup(w http.ResponseWriter, r *http.Request) {
 
 // handleSetupPage serves the main setup page
 func (ss *SetupServer) handleSetupPage(w http.ResponseWriter, r *http.Request) {
-	log.Println("📄 Setup page requested")
+	// Links printed to the console carry the setup code, keep it out of the address bar
+	if ss.storeSetupCodeCookie(w, r) {
+		http.Redirect(w, r, "/setup", http.StatusFound)
+		return
+	}
 	
 	tmpl := `<!DOCTYPE html>
 <html lang="en">
@@ -199,6 +236,50 @@ func (ss *SetupServer) handleSetupPage(w http.ResponseWriter, r *http.Request) {
             text-align: center;
             padding: 20px;
         }
+        .folder-picker {
+            margin: 20px 0;
+            border: 1px solid #ddd;
+            border-radius: 8px;
+            background: white;
+        }
+        .folder-picker-header {
+            display: flex;
+            align-items: center;
+            gap: 10px;
+            padding: 10px;
+            border-bottom: 1px solid #ddd;
+        }
+        .folder-picker-path {
+            flex: 1;
+            font-family: monospace;
+            overflow: hidden;
+            text-overflow: ellipsis;
+            white-space: nowrap;
+        }
+        .folder-list {
+            max-height: 300px;
+            overflow-y: auto;
+        }
+        .folder-item {
+            display: flex;
+            justify-content: space-between;
+            padding: 10px;
+            cursor: pointer;
+            border-bottom: 1px solid #f1f3f4;
+        }
+        .folder-item:hover {
+            background: #f8f9fa;
+        }
+        .folder-count {
+            color: #6c757d;
+            font-size: 14px;
+        }
+        button.secondary {
+            background: #6c757d;
+        }
+        button.secondary:hover {
+            background: #545b62;
+        }
     </style>
 </head>
 <body>
@@ -235,6 +316,16 @@ func (ss *SetupServer) handleSetupPage(w http.ResponseWriter, r *http.Request) {
             </div>
             
             <button onclick="validateMusicDirectory()">Validate Directory</button>
+            <button class="secondary" onclick="openFolderPicker()">Browse...</button>
+            
+            <div id="folder-picker" class="folder-picker hidden">
+                <div class="folder-picker-header">
+                    <button id="folder-up" class="secondary" onclick="browseFolder(folderPicker.parent)">⬆ Up</button>
+                    <span id="folder-path" class="folder-picker-path">Choose a location</span>
+                    <button id="folder-select" onclick="selectFolder(folderPicker.path)">Use This Folder</button>
+                </div>
+                <div id="folder-list" class="folder-list"></div>
+            </div>
             
             <div id="music-status" class="hidden"></div>
         </div>
@@ -252,6 +343,8 @@ func (ss *SetupServer) handleSetupPage(w http.ResponseWriter, r *http.Request) {
             </div>
             
             <button id="complete-setup" onclick="completeSetup()" disabled>Complete Setup</button>
+            
+            <div id="complete-status" class="hidden"></div>
         </div>
     </div>
 
@@ -262,6 +355,112 @@ func (ss *SetupServer) handleSetupPage(w http.ResponseWriter, r *http.Request) {
             musicPath: ''
         };
 
+        // All setup API calls carry the setup code shown in the BMA CLI console
+        async function apiFetch(url, options) {
+            options = options || {};
+            options.headers = Object.assign({}, options.headers, {
+                'X-Setup-Code': sessionStorage.getItem('bmaSetupCode') || ''
+            });
+
+            const response = await fetch(url, options);
+            if (response.status === 401) {
+                const code = prompt('Enter the setup code shown in the BMA CLI console:');
+                if (code) {
+                    sessionStorage.setItem('bmaSetupCode', code.trim());
+                    return apiFetch(url, options);
+                }
+            }
+            return response;
+        }
+
+        let folderPicker = {
+            path: '',
+            parent: '',
+            generation: 0
+        };
+
+        function openFolderPicker() {
+            document.getElementById('folder-picker').classList.remove('hidden');
+            browseFolder('');
+        }
+
+        // Lists the subfolders of path (the browse roots when path is empty)
+        async function browseFolder(path) {
+            const generation = ++folderPicker.generation;
+            const list = document.getElementById('folder-list');
+            list.innerHTML = '<div class="loading">Loading folders...</div>';
+
+            try {
+                const response = await apiFetch('/api/browse?path=' + encodeURIComponent(path));
+                const data = await response.json();
+                if (!response.ok) {
+                    list.innerHTML = '';
+                    showMusicStatus('error', '❌ ' + (data.message || data.error || 'Cannot open folder'));
+                    return;
+                }
+
+                folderPicker.path = data.path || '';
+                folderPicker.parent = data.parent || '';
+                document.getElementById('folder-path').textContent = data.path || 'Choose a location';
+                document.getElementById('folder-up').disabled = !data.path;
+                document.getElementById('folder-select').disabled = !data.path;
+
+                list.innerHTML = '';
+                if (data.entries.length === 0) {
+                    list.innerHTML = '<div class="folder-item">No subfolders</div>';
+                }
+                const counts = [];
+                data.entries.forEach(function(entry) {
+                    const item = document.createElement('div');
+                    item.className = 'folder-item';
+                    item.onclick = function() { browseFolder(entry.path); };
+
+                    const name = document.createElement('span');
+                    name.textContent = '📁 ' + entry.name;
+                    const count = document.createElement('span');
+                    count.className = 'folder-count';
+                    count.textContent = '…';
+                    counts.push({ path: entry.path, element: count });
+
+                    item.appendChild(name);
+                    item.appendChild(count);
+                    list.appendChild(item);
+                });
+                loadFolderCounts(counts, generation);
+            } catch (error) {
+                console.error('Error browsing folders:', error);
+                list.innerHTML = '';
+                showMusicStatus('error', '❌ Error loading folders.');
+            }
+        }
+
+        // Counts the music files of the listed folders one at a time, until another folder is opened
+        async function loadFolderCounts(counts, generation) {
+            for (const count of counts) {
+                if (generation !== folderPicker.generation) {
+                    return;
+                }
+                try {
+                    const response = await apiFetch('/api/browse/count?path=' + encodeURIComponent(count.path));
+                    const data = await response.json();
+                    count.element.textContent = response.ok
+                        ? data.musicFileCount + (data.countTruncated ? '+' : '') + ' music files'
+                        : '';
+                } catch (error) {
+                    count.element.textContent = '';
+                }
+            }
+        }
+
+        function selectFolder(path) {
+            if (!path) {
+                return;
+            }
+            document.getElementById('music-path').value = path;
+            document.getElementById('folder-picker').classList.add('hidden');
+            validateMusicDirectory();
+        }
+
         // Check Tailscale status on page load
         document.addEventListener('DOMContentLoaded', function() {
             checkTailscaleStatus();
@@ -269,7 +468,7 @@ func (ss *SetupServer) handleSetupPage(w http.ResponseWriter, r *http.Request) {
 
         async function checkTailscaleStatus() {
             try {
-                const response = await fetch('/api/tailscale/status');
+                const response = await apiFetch('/api/tailscale/status');
                 const data = await response.json();
                 
                 const statusDiv = document.getElementById('tailscale-status');
@@ -321,7 +520,7 @@ func (ss *SetupServer) handleSetupPage(w http.ResponseWriter, r *http.Request) {
             }
 
             try {
-                const response = await fetch('/api/music/validate', {
+                const response = await apiFetch('/api/music/validate', {
                     method: 'POST',
                     headers: {
                         'Content-Type': 'application/json',
@@ -337,7 +536,7 @@ func (ss *SetupServer) handleSetupPage(w http.ResponseWriter, r *http.Request) {
                     setupState.musicPath = musicPath;
                     updateSteps();
                 } else {
-                    showMusicStatus('error', '❌ ' + (data.error || 'Invalid music directory'));
+                    showMusicStatus('error', '❌ ' + (data.message || data.error || 'Invalid music directory'));
                     setupState.musicDirectoryValid = false;
                 }
             } catch (error) {
@@ -381,7 +580,7 @@ func (ss *SetupServer) handleSetupPage(w http.ResponseWriter, r *http.Request) {
             }
 
             try {
-                const response = await fetch('/api/setup/complete', {
+                const response = await apiFetch('/api/setup/complete', {
                     method: 'POST',
                     headers: {
                         'Content-Type': 'application/json',
@@ -395,17 +594,54 @@ func (ss *SetupServer) handleSetupPage(w http.ResponseWriter, r *http.Request) {
                 const data = await response.json();
                 
                 if (data.success) {
-                    alert('🎉 Setup complete! BMA CLI will now restart as a music server.');
-                    // Redirect or close
-                    window.location.href = '/';
+                    document.getElementById('complete-setup').disabled = true;
+                    showCompleteStatus('info', '🎉 Setup complete! Starting the music server...');
+                    waitForMusicServer(0);
                 } else {
-                    alert('❌ Setup failed: ' + (data.error || 'Unknown error'));
+                    alert('❌ Setup failed: ' + (data.message || data.error || 'Unknown error'));
                 }
             } catch (error) {
                 console.error('Error completing setup:', error);
                 alert('❌ Error completing setup.');
             }
         }
+
+        function showCompleteStatus(type, message) {
+            const statusDiv = document.getElementById('complete-status');
+            statusDiv.className = 'status ' + type;
+            statusDiv.innerHTML = message;
+            statusDiv.classList.remove('hidden');
+        }
+
+        // The music server replaces the setup server on the same port,
+        // so poll its health endpoint and open the pairing page once it answers
+        async function waitForMusicServer(attempt) {
+            try {
+                const response = await fetch('/health', { cache: 'no-store' });
+                if (response.ok) {
+                    const data = await response.json();
+                    if (data.status === 'healthy') {
+                        // The library is scanned in the background, pairing works in the meantime
+                        if (data.scan) {
+                            showCompleteStatus('success', '✅ Music server is running! Scanning your library (' +
+                                data.scan.processed + ' of ' + data.scan.discovered + ' songs read), opening the pairing page...');
+                        } else {
+                            showCompleteStatus('success', '✅ Music server is running! Opening the pairing page...');
+                        }
+                        window.location.href = '/qr';
+                        return;
+                    }
+                }
+            } catch (error) {
+                // Expected while the servers switch over
+            }
+
+            if (attempt >= 60) {
+                showCompleteStatus('error', '❌ The music server did not start. Check the BMA CLI console output.');
+                return;
+            }
+            setTimeout(function() { waitForMusicServer(attempt + 1); }, 1000);
+        }
     </script>
 </body>
 </html>`
@@ -413,7 +649,7 @@ func (ss *SetupServer) handleSetupPage(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
 	t, err := template.New("setup").Parse(tmpl)
 	if err != nil {
-		http.Error(w, "Template error", http.StatusInternalServerError)
+		api.WriteError(w, r, http.StatusInternalServerError, api.CodeInternal, "Template error")
 		return
 	}
 	
@@ -422,8 +658,6 @@ func (ss *SetupServer) handleSetupPage(w http.ResponseWriter, r *http.Request) {
 
 // handleTailscaleStatus returns Tailscale status and auth info
 func (ss *SetupServer) handleTailscaleStatus(w http.ResponseWriter, r *http.Request) {
-	log.Println("🔍 Tailscale status requested")
-	
 	response := map[string]interface{}{
 		"available":     false,
 		"authenticated": false,
@@ -460,8 +694,6 @@ func (ss *SetupServer) handleTailscaleStatus(w http.ResponseWriter, r *http.Requ
 
 // handleTailscaleAuth handles Tailscale authentication
 func (ss *SetupServer) handleTailscaleAuth(w http.ResponseWriter, r *http.Request) {
-	log.Println("🔐 Tailscale auth requested")
-	
 	// TODO: Implement actual Tailscale auth verification
 	response := map[string]interface{}{
 		"success": true,
@@ -474,20 +706,26 @@ func (ss *SetupServer) handleTailscaleAuth(w http.ResponseWriter, r *http.Reques
 
 // handleMusicDirectoryValidation validates the music directory
 func (ss *SetupServer) handleMusicDirectoryValidation(w http.ResponseWriter, r *http.Request) {
-	log.Println("📁 Music directory validation requested")
-	
 	var request struct {
 		Path string `json:"path"`
 	}
 	
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+		api.WriteError(w, r, http.StatusBadRequest, api.CodeInvalidRequest, "Invalid request")
 		return
 	}
 	
-	log.Printf("📁 Validating music directory: %s", request.Path)
+	logger.DebugContext(r.Context(), "Validating music folder", "path", request.Path)
 	
-	response := ss.validateMusicDirectory(request.Path)
+	// Only folders the folder picker could show, so the setup API can't probe the rest of the disk
+	dir, _, err := resolveInsideRoots(request.Path, ss.resolvedBrowseRoots())
+	if err != nil {
+		logger.WarnContext(r.Context(), "Music folder validation rejected", "path", request.Path, "err", err)
+		api.WriteError(w, r, http.StatusForbidden, codeFolderForbidden, err.Error())
+		return
+	}
+	
+	response := ss.validateMusicDirectory(dir)
 	
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -495,36 +733,40 @@ func (ss *SetupServer) handleMusicDirectoryValidation(w http.ResponseWriter, r *
 
 // handleSetupComplete completes the setup process
 func (ss *SetupServer) handleSetupComplete(w http.ResponseWriter, r *http.Request) {
-	log.Println("✅ Setup completion requested")
-	
 	var request struct {
 		MusicPath           string `json:"musicPath"`
 		TailscaleConfigured bool   `json:"tailscaleConfigured"`
 	}
 	
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+		api.WriteError(w, r, http.StatusBadRequest, api.CodeInvalidRequest, "Invalid request")
 		return
 	}
 	
-	log.Printf("✅ Completing setup with music path: %s", request.MusicPath)
+	// Re-validate, the request may not come from the setup page
+	musicPath, _, err := resolveInsideRoots(request.MusicPath, ss.resolvedBrowseRoots())
+	if err != nil {
+		logger.WarnContext(r.Context(), "Music folder rejected", "path", request.MusicPath, "err", err)
+		api.WriteError(w, r, http.StatusForbidden, codeFolderForbidden, err.Error())
+		return
+	}
+	if _, err := ValidateMusicDirectory(musicPath); err != nil {
+		logger.WarnContext(r.Context(), "Music folder rejected", "path", musicPath, "err", err)
+		api.WriteError(w, r, http.StatusBadRequest, codeInvalidMusicFolder, err.Error())
+		return
+	}
 	
 	// Update configuration
-	ss.config.MusicFolder = request.MusicPath
+	ss.config.ResetLibraryRoots(musicPath)
 	ss.config.SetupComplete = true
 	
 	if err := ss.config.SaveConfig(); err != nil {
-		log.Printf("❌ Failed to save config: %v", err)
-		response := map[string]interface{}{
-			"success": false,
-			"error":   "Failed to save configuration",
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		logger.ErrorContext(r.Context(), "Failed to save config", "err", err)
+		api.WriteError(w, r, http.StatusInternalServerError, codeConfigNotSaved, "Failed to save configuration")
 		return
 	}
 	
-	log.Println("✅ Setup completed successfully")
+	logger.InfoContext(r.Context(), "Setup completed", "music_folder", musicPath)
 	
 	response := map[string]interface{}{
 		"success": true,
@@ -534,12 +776,12 @@ func (ss *SetupServer) handleSetupComplete(w http.ResponseWriter, r *http.Reques
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 	
-	// Schedule server restart
-	go func() {
-		time.Sleep(1 * time.Second)
-		log.Println("🔄 Restarting as music server...")
-		os.Exit(0) // This will cause the application to restart as a music server
-	}()
+	// Hand off to the music server once this response has been sent
+	ss.completeOnce.Do(func() {
+		ss.expireSetupCode()
+		logger.Info("Switching to the music server")
+		close(ss.completed)
+	})
 }
 
 // getTailscaleAuthURL gets the Tailscale authentication URL
@@ -548,14 +790,14 @@ func (ss *SetupServer) getTailscaleAuthURL() string {
 	cmd := exec.Command("tailscale", "status")
 	output, err := cmd.Output()
 	if err != nil {
-		log.Printf("🔍 Tailscale not available: %v", err)
+		logger.Debug("Tailscale not available", "err", err)
 		return ""
 	}
 	
 	// If we can get status, Tailscale is available and likely authenticated
 	statusStr := string(output)
 	if len(statusStr) > 0 {
-		log.Println("✅ Tailscale is available and authenticated")
+		logger.Debug("Tailscale is available and authenticated")
 		// Return empty string to indicate it's already set up
 		return "authenticated"
 	}
@@ -565,15 +807,20 @@ func (ss *SetupServer) getTailscaleAuthURL() string {
 
 // getTailscaleIP gets the Tailscale IP address
 func (ss *SetupServer) getTailscaleIP() string {
+	return DetectTailscaleIP()
+}
+
+// DetectTailscaleIP returns this machine's Tailscale IPv4 address, or "" if unavailable
+func DetectTailscaleIP() string {
 	cmd := exec.Command("tailscale", "ip", "-4")
 	output, err := cmd.Output()
 	if err != nil {
-		log.Printf("⚠️ Failed to get Tailscale IP: %v", err)
+		logger.Debug("Tailscale IP not available", "err", err)
 		return ""
 	}
 	
 	ip := strings.TrimSpace(string(output))
-	log.Printf("🔗 Found Tailscale IP: %s", ip)
+	logger.Debug("Tailscale IP found", "ip", ip)
 	return ip
 }
 
@@ -586,7 +833,7 @@ func (ss *SetupServer) generateAuthQR(url string) string {
 	// Generate QR code
 	qrCode, err := qrcode.Encode(url, qrcode.Medium, 256)
 	if err != nil {
-		log.Printf("❌ Failed to generate QR code: %v", err)
+		logger.Error("Failed to generate QR code", "err", err)
 		return ""
 	}
 	
@@ -602,20 +849,30 @@ func (ss *SetupServer) validateMusicDirectory(path string) map[string]interface{
 		"error":     "",
 	}
 	
+	musicCount, err := ValidateMusicDirectory(path)
+	if err != nil {
+		response["error"] = err.Error()
+		return response
+	}
+	
+	response["valid"] = true
+	response["fileCount"] = musicCount
+	return response
+}
+
+// ValidateMusicDirectory checks that path is a directory containing music files and returns how many
+func ValidateMusicDirectory(path string) (int, error) {
 	// Check if directory exists
 	info, err := os.Stat(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			response["error"] = "Directory does not exist"
-		} else {
-			response["error"] = fmt.Sprintf("Cannot access directory: %v", err)
+			return 0, fmt.Errorf("Directory does not exist")
 		}
-		return response
+		return 0, fmt.Errorf("Cannot access directory: %v", err)
 	}
 	
 	if !info.IsDir() {
-		response["error"] = "Path is not a directory"
-		return response
+		return 0, fmt.Errorf("Path is not a directory")
 	}
 	
 	// Count music files
@@ -625,27 +882,26 @@ func (ss *SetupServer) validateMusicDirectory(path string) map[string]interface{
 			return nil // Continue walking, ignore errors
 		}
 		
-		if !info.IsDir() {
-			ext := strings.ToLower(filepath.Ext(info.Name()))
-			if ext == ".mp3" || ext == ".m4a" || ext == ".flac" || ext == ".wav" {
-				musicCount++
-			}
+		if !info.IsDir() && isMusicFile(info.Name()) {
+			musicCount++
 		}
 		
 		return nil
 	})
 	
 	if err != nil {
-		response["error"] = fmt.Sprintf("Error scanning directory: %v", err)
-		return response
+		return 0, fmt.Errorf("Error scanning directory: %v", err)
 	}
 	
 	if musicCount == 0 {
-		response["error"] = "No music files found in directory"
-		return response
+		return 0, fmt.Errorf("No music files found in directory")
 	}
 	
-	response["valid"] = true
-	response["fileCount"] = musicCount
-	return response
-}
\ No newline at end of file
+	return musicCount, nil
+}
+
+// isMusicFile returns true for file names with a supported audio extension
+func isMusicFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".mp3" || ext == ".m4a" || ext == ".flac" || ext == ".wav"
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+
+	"bma-core/api"
+)
+
+// Setup page protection
+// Features:
+// - One-time setup code printed to the console, required on all /api/* setup routes
+// - Code accepted from the X-Setup-Code header or the bma_setup_code cookie
+// - Code expires as soon as setup completes
+// - Requests limited to localhost and private networks unless explicitly allowed
+
+const (
+	setupCodeHeader   = "X-Setup-Code"
+	setupCodeCookie   = "bma_setup_code"
+	setupCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // No 0/O or 1/I to avoid typos
+	setupCodeLength   = 8
+)
+
+// Error codes of the setup API, in addition to the api package's
+const (
+	codeNetworkForbidden   api.ErrorCode = "setup_network_forbidden" // Request from outside the local network
+	codeSetupCompleted     api.ErrorCode = "setup_completed"         // The setup code expired with the setup
+	codeSetupCodeRequired  api.ErrorCode = "setup_code_required"     // Missing or wrong setup code
+	codeFolderForbidden    api.ErrorCode = "folder_forbidden"        // Folder outside the browse roots
+	codeFolderUnreadable   api.ErrorCode = "folder_unreadable"
+	codeInvalidMusicFolder api.ErrorCode = "invalid_music_folder"
+	codeConfigNotSaved     api.ErrorCode = "config_not_saved"
+)
+
+// tailscaleCGNAT is the range Tailscale assigns to devices on a tailnet
+var tailscaleCGNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// generateSetupCode creates a random code formatted as XXXX-XXXX
+func generateSetupCode() (string, error) {
+	random := make([]byte, setupCodeLength)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	code := make([]byte, 0, setupCodeLength+1)
+	for i, b := range random {
+		if i == setupCodeLength/2 {
+			code = append(code, '-')
+		}
+		code = append(code, setupCodeAlphabet[int(b)%len(setupCodeAlphabet)])
+	}
+	return string(code), nil
+}
+
+// normalizeSetupCode makes code comparison ignore case, spaces and dashes
+func normalizeSetupCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+// SetupCode returns the code users must enter on the setup page
+func (ss *SetupServer) SetupCode() string {
+	ss.codeMutex.Lock()
+	defer ss.codeMutex.Unlock()
+	return ss.setupCode
+}
+
+// AllowAnyNetwork disables the localhost/private network restriction
+func (ss *SetupServer) AllowAnyNetwork(allow bool) {
+	ss.codeMutex.Lock()
+	defer ss.codeMutex.Unlock()
+	ss.allowAnyNetwork = allow
+}
+
+// expireSetupCode invalidates the setup code once setup has completed
+func (ss *SetupServer) expireSetupCode() {
+	ss.codeMutex.Lock()
+	defer ss.codeMutex.Unlock()
+	ss.setupCode = ""
+	logger.Info("Setup code expired")
+}
+
+// checkSetupCode compares a submitted code with the current one in constant time
+func (ss *SetupServer) checkSetupCode(code string) bool {
+	ss.codeMutex.Lock()
+	expected := ss.setupCode
+	ss.codeMutex.Unlock()
+
+	if expected == "" || code == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(normalizeSetupCode(code)), []byte(normalizeSetupCode(expected))) == 1
+}
+
+// networkRestrictionMiddleware rejects setup requests from outside the local network
+func (ss *SetupServer) networkRestrictionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ss.codeMutex.Lock()
+		allowAny := ss.allowAnyNetwork
+		ss.codeMutex.Unlock()
+
+		if !allowAny && !isLocalNetworkAddress(r.RemoteAddr) {
+			logger.WarnContext(r.Context(), "Setup request from outside the local network rejected", "client", r.RemoteAddr)
+			api.WriteError(w, r, http.StatusForbidden, codeNetworkForbidden, "Setup is only available from localhost or the local network")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireSetupCodeMiddleware protects the setup API with the one-time setup code
+func (ss *SetupServer) requireSetupCodeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ss.SetupCode() == "" {
+			api.WriteError(w, r, http.StatusGone, codeSetupCompleted, "Setup has already been completed")
+			return
+		}
+
+		code := r.Header.Get(setupCodeHeader)
+		if code == "" {
+			if cookie, err := r.Cookie(setupCodeCookie); err == nil {
+				code = cookie.Value
+			}
+		}
+
+		if !ss.checkSetupCode(code) {
+			if code != "" {
+				logger.WarnContext(r.Context(), "Invalid setup code", "client", r.RemoteAddr)
+			}
+			api.WriteError(w, r, http.StatusUnauthorized, codeSetupCodeRequired, "Setup code required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// storeSetupCodeCookie remembers a valid ?code= query parameter in a cookie
+func (ss *SetupServer) storeSetupCodeCookie(w http.ResponseWriter, r *http.Request) bool {
+	code := r.URL.Query().Get("code")
+	if code == "" || !ss.checkSetupCode(code) {
+		return false
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     setupCodeCookie,
+		Value:    normalizeSetupCode(code),
+		Path:     "/api/",
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return true
+}
+
+// isLocalNetworkAddress returns true for loopback, private, link-local and Tailscale addresses
+func isLocalNetworkAddress(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || tailscaleCGNAT.Contains(ip)
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSetupCode(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		code, err := generateSetupCode()
+		if err != nil {
+			t.Fatalf("generateSetupCode() error = %v", err)
+		}
+		if len(code) != setupCodeLength+1 || code[setupCodeLength/2] != '-' {
+			t.Fatalf("generateSetupCode() = %q, want XXXX-XXXX", code)
+		}
+		for _, c := range normalizeSetupCode(code) {
+			if !strings.ContainsRune(setupCodeAlphabet, c) {
+				t.Fatalf("generateSetupCode() = %q, %q is not in the alphabet", code, c)
+			}
+		}
+	}
+}
+
+func TestCheckSetupCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		code     string
+		want     bool
+	}{
+		{"exact", "ABCD-EFGH", "ABCD-EFGH", true},
+		{"lower case", "ABCD-EFGH", "abcd-efgh", true},
+		{"without dash", "ABCD-EFGH", "ABCDEFGH", true},
+		{"with spaces", "ABCD-EFGH", " ABCD EFGH ", true},
+		{"wrong code", "ABCD-EFGH", "ABCD-EFGJ", false},
+		{"prefix", "ABCD-EFGH", "ABCD", false},
+		{"empty code", "ABCD-EFGH", "", false},
+		{"expired", "", "ABCD-EFGH", false},
+		{"expired and empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := &SetupServer{setupCode: tt.expected}
+			if got := ss.checkSetupCode(tt.code); got != tt.want {
+				t.Errorf("checkSetupCode(%q) with code %q = %v, want %v", tt.code, tt.expected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireSetupCodeMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupCode  string
+		header     string
+		cookie     string
+		wantStatus int
+	}{
+		{"header", "ABCD-EFGH", "abcd-efgh", "", http.StatusOK},
+		{"cookie", "ABCD-EFGH", "", "ABCDEFGH", http.StatusOK},
+		{"header wins over cookie", "ABCD-EFGH", "ABCD-EFGH", "WRONGCODE", http.StatusOK},
+		{"wrong header", "ABCD-EFGH", "WXYZ-WXYZ", "", http.StatusUnauthorized},
+		{"wrong cookie", "ABCD-EFGH", "", "WXYZWXYZ", http.StatusUnauthorized},
+		{"no code", "ABCD-EFGH", "", "", http.StatusUnauthorized},
+		{"setup completed", "", "ABCD-EFGH", "", http.StatusGone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := &SetupServer{setupCode: tt.setupCode}
+			handler := ss.requireSetupCodeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/setup/status", nil)
+			if tt.header != "" {
+				req.Header.Set(setupCodeHeader, tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: setupCodeCookie, Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestStoreSetupCodeCookie(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStored bool
+	}{
+		{"valid code", "?code=abcd-efgh", true},
+		{"wrong code", "?code=WXYZ-WXYZ", false},
+		{"no code", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := &SetupServer{setupCode: "ABCD-EFGH"}
+			rec := httptest.NewRecorder()
+			stored := ss.storeSetupCodeCookie(rec, httptest.NewRequest(http.MethodGet, "/"+tt.query, nil))
+			if stored != tt.wantStored {
+				t.Fatalf("storeSetupCodeCookie() = %v, want %v", stored, tt.wantStored)
+			}
+
+			cookies := rec.Result().Cookies()
+			if !tt.wantStored {
+				if len(cookies) != 0 {
+					t.Errorf("got cookies %v, want none", cookies)
+				}
+				return
+			}
+			if len(cookies) != 1 {
+				t.Fatalf("got %d cookies, want 1", len(cookies))
+			}
+			cookie := cookies[0]
+			if cookie.Name != setupCodeCookie || cookie.Value != "ABCDEFGH" {
+				t.Errorf("cookie = %s=%s, want %s=ABCDEFGH", cookie.Name, cookie.Value, setupCodeCookie)
+			}
+			if !cookie.HttpOnly || cookie.SameSite != http.SameSiteStrictMode || cookie.Path != "/api/" {
+				t.Errorf("cookie = %+v, want HttpOnly, SameSite=Strict and Path=/api/", cookie)
+			}
+		})
+	}
+}
+
+func TestIsLocalNetworkAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1:5000", true},
+		{"[::1]:5000", true},
+		{"192.168.1.20:5000", true},
+		{"10.0.0.5:5000", true},
+		{"172.16.4.1:5000", true},
+		{"169.254.10.10:5000", true},
+		{"[fe80::1]:5000", true},
+		{"100.101.102.103:5000", true}, // Tailscale
+		{"100.128.0.1:5000", false},    // Just outside the Tailscale range
+		{"8.8.8.8:5000", false},
+		{"[2001:4860:4860::8888]:5000", false},
+		{"192.168.1.20", true},
+		{"not-an-address", false},
+	}
+
+	for _, tt := range tests {
+		if got := isLocalNetworkAddress(tt.addr); got != tt.want {
+			t.Errorf("isLocalNetworkAddress(%q) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
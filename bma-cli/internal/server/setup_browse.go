@@ -0,0 +1,214 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"bma-core/api"
+)
+
+// Folder picker for the web setup wizard
+// Features:
+// - Lists the subdirectories of a folder, the music files below each one are counted on request
+// - Starts from the home directory and mounted drives (or Config.BrowseRoots)
+// - Sandboxed: paths outside the browse roots are rejected, symlinks included
+
+// maxBrowseCountEntries limits how many files are visited when counting music in one folder
+const maxBrowseCountEntries = 20000
+
+// BrowseEntry describes a folder shown in the setup folder picker
+type BrowseEntry struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// BrowseCountResponse is returned by /api/browse/count
+type BrowseCountResponse struct {
+	Path              string `json:"path"`
+	MusicFileCount    int    `json:"musicFileCount"`
+	CountTruncated    bool   `json:"countTruncated,omitempty"`
+	HasSubdirectories bool   `json:"hasSubdirectories"`
+}
+
+// BrowseResponse is returned by /api/browse
+type BrowseResponse struct {
+	Path    string        `json:"path,omitempty"`
+	Parent  string        `json:"parent,omitempty"`
+	Entries []BrowseEntry `json:"entries"`
+}
+
+// handleBrowse lists the browse roots, or the subdirectories of ?path=
+func (ss *SetupServer) handleBrowse(w http.ResponseWriter, r *http.Request) {
+	requestedPath := r.URL.Query().Get("path")
+	logger.DebugContext(r.Context(), "Browse requested", "path", requestedPath)
+
+	roots := ss.resolvedBrowseRoots()
+	response := BrowseResponse{
+		Entries: make([]BrowseEntry, 0),
+	}
+
+	if requestedPath == "" {
+		// Top level: the roots themselves
+		for _, root := range roots {
+			response.Entries = append(response.Entries, BrowseEntry{Name: root, Path: root})
+		}
+		writeBrowseResponse(w, r, response)
+		return
+	}
+
+	dir, root, err := resolveInsideRoots(requestedPath, roots)
+	if err != nil {
+		logger.WarnContext(r.Context(), "Browse rejected", "path", requestedPath, "err", err)
+		api.WriteError(w, r, http.StatusForbidden, codeFolderForbidden, err.Error())
+		return
+	}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		api.WriteError(w, r, http.StatusBadRequest, codeFolderUnreadable, fmt.Sprintf("Cannot read directory: %v", err))
+		return
+	}
+
+	response.Path = dir
+	if dir != root {
+		response.Parent = filepath.Dir(dir)
+	}
+
+	for _, entry := range dirEntries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		entryPath := filepath.Join(dir, entry.Name())
+		if entry.Type()&fs.ModeSymlink != 0 {
+			// Follow symlinks only if they stay inside the sandbox
+			target, _, err := resolveInsideRoots(entryPath, roots)
+			if err != nil {
+				continue
+			}
+			if info, err := os.Stat(target); err != nil || !info.IsDir() {
+				continue
+			}
+		} else if !entry.IsDir() {
+			continue
+		}
+
+		response.Entries = append(response.Entries, BrowseEntry{Name: entry.Name(), Path: entryPath})
+	}
+
+	sort.Slice(response.Entries, func(i, j int) bool {
+		return strings.ToLower(response.Entries[i].Name) < strings.ToLower(response.Entries[j].Name)
+	})
+
+	writeBrowseResponse(w, r, response)
+}
+
+// handleBrowseCount counts the music files below ?path=. The folder picker asks for each
+// folder separately, so listing a folder doesn't walk all of its subfolders at once.
+func (ss *SetupServer) handleBrowseCount(w http.ResponseWriter, r *http.Request) {
+	requestedPath := r.URL.Query().Get("path")
+
+	dir, _, err := resolveInsideRoots(requestedPath, ss.resolvedBrowseRoots())
+	if err != nil {
+		logger.WarnContext(r.Context(), "Browse count rejected", "path", requestedPath, "err", err)
+		api.WriteError(w, r, http.StatusForbidden, codeFolderForbidden, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(countDirectory(dir)); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to encode browse count", "err", err)
+	}
+}
+
+// resolvedBrowseRoots returns the browse roots with symlinks resolved
+func (ss *SetupServer) resolvedBrowseRoots() []string {
+	var roots []string
+	for _, root := range ss.config.GetBrowseRoots() {
+		resolved, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			continue
+		}
+		roots = append(roots, resolved)
+	}
+	return roots
+}
+
+// resolveInsideRoots resolves path and returns it with the root containing it
+func resolveInsideRoots(path string, roots []string) (string, string, error) {
+	if !filepath.IsAbs(path) {
+		return "", "", fmt.Errorf("Path must be absolute")
+	}
+
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(path))
+	if err != nil {
+		return "", "", fmt.Errorf("Directory does not exist")
+	}
+
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, resolved)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return resolved, root, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("Path is outside the allowed folders")
+}
+
+// countDirectory counts the music files below dir for the folder picker
+func countDirectory(dir string) BrowseCountResponse {
+	entry := BrowseCountResponse{Path: dir}
+
+	if children, err := os.ReadDir(dir); err == nil {
+		for _, child := range children {
+			if child.IsDir() && !strings.HasPrefix(child.Name(), ".") {
+				entry.HasSubdirectories = true
+				break
+			}
+		}
+	}
+
+	visited := 0
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable folders
+		}
+
+		visited++
+		if visited > maxBrowseCountEntries {
+			entry.CountTruncated = true
+			return filepath.SkipAll
+		}
+
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if isMusicFile(d.Name()) {
+			entry.MusicFileCount++
+		}
+		return nil
+	})
+
+	return entry
+}
+
+// writeBrowseResponse encodes a browse response as JSON
+func writeBrowseResponse(w http.ResponseWriter, r *http.Request, response BrowseResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to encode browse response", "err", err)
+	}
+}
@@ -1,112 +1,235 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
-	"os/signal"
+	"path/filepath"
 	"strings"
-	"syscall"
 
-	"bma-cli/internal/models"
 	"bma-cli/internal/server"
+	"bma-core/logging"
+	"bma-core/settings"
 )
 
+// Exit codes shared by all commands
+const (
+	exitOK    = 0 // Command succeeded
+	exitError = 1 // Command failed (server unreachable, invalid folder, ...)
+	exitUsage = 2 // Invalid command line
+)
+
+// logger tags the command line's log lines with component=cli
+var logger = logging.Component("cli")
+
+// command is a bma-cli subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+// commands lists all subcommands in the order shown by the usage text
+var commands = []command{
+	{"serve", "Start the music streaming server", runServe},
+	{"setup", "Configure the server (web wizard or interactive terminal)", runSetup},
+	{"scan", "Scan the library roots and print a library summary", runScan},
+	{"roots", "List, add or remove music library roots", runRoots},
+	{"pair", "Create a pairing token for a new device", runPair},
+	{"devices", "List or revoke paired devices", runDevices},
+	{"status", "Query a running server", runStatus},
+	{"config", "Show or change configuration values", runConfig},
+}
+
 func main() {
-	log.Println("🚀 Starting BMA CLI (Basic Music App) - Headless Server Edition")
+	// Keep bma-cli's config and paired devices apart from the desktop app's
+	settings.ConfigDirName = ".bma-cli"
 
-	// Load configuration
-	config, err := models.LoadConfig()
-	if err != nil {
-		log.Printf("⚠️ Error loading config: %v", err)
-		config = &models.Config{SetupComplete: false}
+	// Without a subcommand keep the original behaviour: setup on first run, then serve
+	if len(os.Args) < 2 {
+		os.Exit(runDefault())
+	}
+
+	name := os.Args[1]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		printUsage(os.Stdout)
+		os.Exit(exitOK)
 	}
 
-	// Check if setup is complete
+	for _, cmd := range commands {
+		if cmd.name == name {
+			os.Exit(cmd.run(os.Args[2:]))
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "bma-cli: unknown command %q\n\n", name)
+	printUsage(os.Stderr)
+	os.Exit(exitUsage)
+}
+
+// runDefault picks setup or serve based on whether setup was completed
+func runDefault() int {
+	config := loadConfig()
+	if err := setupLogging(config, &logOptions{}); err != nil {
+		return fail("%v", err)
+	}
+	logger.Info("Starting BMA CLI (Basic Music App) - Headless Server Edition")
+
 	if !config.SetupComplete {
-		log.Println("🔧 First run detected - starting setup server")
-		startSetupServer(config)
-	} else {
-		log.Println("✅ Setup complete - starting main streaming server")
-		startMainServer(config)
+		logger.Info("First run detected, starting the setup server")
+		return startSetupServer(config, &qrOptions{style: "auto"}, false)
 	}
+
+	logger.Info("Setup complete, starting the music server")
+	return startMainServer(config, &qrOptions{style: "auto"})
 }
 
-func startSetupServer(config *models.Config) {
-	log.Println("🌐 Starting setup web server at http://localhost:8080/setup")
-	
-	// Create setup server
-	setupServer := server.NewSetupServer(config)
-	
-	// Handle graceful shutdown
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	
-	go func() {
-		<-c
-		log.Println("🛑 Received shutdown signal, stopping setup server...")
-		setupServer.Shutdown()
-		os.Exit(0)
-	}()
-	
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("🎵 BMA CLI Setup")
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Println("Setup server is running on port 8080")
-	fmt.Println("")
-	fmt.Println("To access the setup page:")
-	fmt.Println("1. Find this device's IP address: hostname -I")
-	fmt.Println("2. Open web browser on any device (same WiFi)")
-	fmt.Println("3. Go to: http://[YOUR-IP]:8080/setup")
-	fmt.Println("")
-	fmt.Println("Example: http://192.168.1.100:8080/setup")
-	fmt.Println(strings.Repeat("=", 60) + "\n")
-	
-	// Start the setup server (this will block)
-	if err := setupServer.Start(); err != nil {
-		log.Fatalf("❌ Failed to start setup server: %v", err)
+// printUsage writes the top-level help text
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "BMA CLI - headless music streaming server")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  bma-cli                 Run setup on first start, otherwise serve")
+	fmt.Fprintln(w, "  bma-cli <command> [flags]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
 	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Run 'bma-cli <command> -h' for the flags of a command.")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Exit codes: 0 success, 1 failure, 2 invalid usage")
 }
 
-func startMainServer(config *models.Config) {
-	log.Println("🌐 Starting main streaming server")
-	
-	// Create music library
-	musicLibrary := models.NewMusicLibrary()
-	
-	// Load music from configured folder
-	if config.MusicFolder != "" {
-		log.Printf("📁 Loading music from: %s", config.MusicFolder)
-		musicLibrary.SelectFolder(config.MusicFolder)
+// newFlagSet creates a flag set that reports errors instead of exiting
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet("bma-cli "+name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bma-cli %s %s\n\nFlags:\n", name, usage)
+		fs.PrintDefaults()
 	}
-	
-	// Create main server
-	mainServer := server.NewMusicServer(config, musicLibrary)
-	
-	// Handle graceful shutdown
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	
-	go func() {
-		<-c
-		log.Println("🛑 Received shutdown signal, stopping music server...")
-		mainServer.Shutdown()
-		os.Exit(0)
-	}()
-	
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("🎵 BMA CLI Music Server")
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Printf("Music Library: %s\n", config.MusicFolder)
-	fmt.Printf("Server running at: http://localhost:8080\n")
-	if config.TailscaleIP != "" {
-		fmt.Printf("Tailscale access: http://%s:8080\n", config.TailscaleIP)
+	return fs
+}
+
+// parseFlags parses args and converts failures into an exit code (-1 means continue).
+// Flags may appear after positional arguments, e.g. `roots add DIR --label NAS`.
+func parseFlags(fs *flag.FlagSet, args []string) int {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return exitOK
+			}
+			return exitUsage
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
 	}
-	fmt.Println("Ready for connections from BMA mobile apps")
-	fmt.Println(strings.Repeat("=", 60) + "\n")
-	
-	// Start the music server (this will block)
-	if err := mainServer.Start(); err != nil {
-		log.Fatalf("❌ Failed to start music server: %v", err)
+
+	// Leave the positional arguments in fs.Args()
+	fs.Parse(append([]string{"--"}, positional...))
+	return -1
+}
+
+// qrOptions controls how pairing QR codes are printed in the terminal
+type qrOptions struct {
+	style  string
+	invert bool
+}
+
+// addQRFlags registers the QR code flags shared by serve and pair
+func addQRFlags(fs *flag.FlagSet) *qrOptions {
+	opts := &qrOptions{}
+	fs.StringVar(&opts.style, "qr", "auto", "terminal QR code style: auto, unicode, ascii or off")
+	fs.BoolVar(&opts.invert, "invert-qr", false, "draw dark modules filled (for light terminal themes)")
+	return opts
+}
+
+// enabled returns false when QR output was turned off
+func (o *qrOptions) enabled() bool {
+	return o.style != "off" && o.style != "none"
+}
+
+// parse validates the QR style flag
+func (o *qrOptions) parse() (server.QRStyle, error) {
+	return server.ParseQRStyle(o.style)
+}
+
+// logOptions overrides the configured log level and format for one run
+type logOptions struct {
+	level  string
+	format string
+}
+
+// addLogFlags registers the logging flags shared by serve and setup
+func addLogFlags(fs *flag.FlagSet) *logOptions {
+	opts := &logOptions{}
+	fs.StringVar(&opts.level, "log-level", "", "log level: debug, info, warn or error (default from config or $BMA_LOG_LEVEL, info)")
+	fs.StringVar(&opts.format, "log-format", "", "log format: text or json (default from config or $BMA_LOG_FORMAT, text)")
+	return opts
+}
+
+// setupLogging configures the logger from the config, the environment and the flags, in that order
+func setupLogging(config *settings.Config, opts *logOptions) error {
+	options := config.GetLogOptions()
+	if opts.level != "" {
+		options.Level = opts.level
+	}
+	if opts.format != "" {
+		options.Format = opts.format
+	}
+	return logging.Setup(options)
+}
+
+// loadConfig loads the configuration, falling back to defaults on error
+func loadConfig() *settings.Config {
+	config, err := settings.LoadConfig()
+	if err != nil {
+		logger.Warn("Failed to load config, using defaults", "err", err)
+		config = &settings.Config{SetupComplete: false}
 	}
-}
\ No newline at end of file
+	return config
+}
+
+// quietLogs hides the server's logging for one-shot commands, verbose shows it down to debug level
+func quietLogs(verbose bool) {
+	if verbose {
+		logging.Setup(logging.Options{Level: "debug", Format: os.Getenv(logging.FormatEnv)})
+	} else {
+		logging.Setup(logging.Options{Output: io.Discard})
+	}
+}
+
+// fail prints an error for a command and returns the failure exit code
+func fail(format string, args ...interface{}) int {
+	fmt.Fprintf(os.Stderr, "❌ "+format+"\n", args...)
+	return exitError
+}
+
+// usageError prints a usage problem and returns the usage exit code
+func usageError(fs *flag.FlagSet, format string, args ...interface{}) int {
+	fmt.Fprintf(os.Stderr, format+"\n\n", args...)
+	fs.Usage()
+	return exitUsage
+}
+
+// expandPath resolves ~ and relative paths to an absolute path
+func expandPath(path string) string {
+	path = strings.TrimSpace(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+		}
+	}
+	if absPath, err := filepath.Abs(path); err == nil {
+		return absPath
+	}
+	return path
+}
@@ -0,0 +1,165 @@
+// Package api serves the HTTP API the BMA Android app talks to. bma-cli and the BMA desktop app
+// both register it, so every endpoint, response and authentication rule is the same in either server.
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"bma-core/library"
+	"bma-core/logging"
+	"bma-core/metrics"
+	"github.com/gorilla/mux"
+)
+
+// logger tags the API's log lines with component=api
+var logger = logging.Component("api")
+
+// pairingTokenLifetime is how long a token from /pair or a QR code stays valid
+const pairingTokenLifetime = 60 * time.Minute
+
+// Version is the API version reported by /info and the OpenAPI document, the same in every server binary
+const Version = "2.0"
+
+// APIVersion is the path prefix of the versioned routes, e.g. /v1/songs
+const APIVersion = "v1"
+
+// Capabilities lists the API features in /info, so clients can check for them before use
+var Capabilities = []string{
+	"pairing",         // POST /pair, /disconnect and /qr
+	"songs",           // /songs
+	"albums",          // /albums
+	"artists",         // /artists and /artists/{artistId}
+	"search",          // /search
+	"pagination",      // limit and cursor on /songs and /albums
+	"filters",         // album, artist, genre, year and folder on /songs and /albums
+	"sorting",         // sort and order on /songs and /albums
+	"field-selection", // fields on /songs and /albums
+	"etag",            // If-None-Match on library responses
+	"gzip",            // Accept-Encoding: gzip on JSON responses
+	"zstd",            // Accept-Encoding: zstd on JSON responses
+	"library-changes", // /library/changes
+	"library-scan",    // /library/scan
+	"library-health",  // /library/health
+	"offline-songs",   // Songs of unavailable roots, streaming them returns 503
+	"openapi",         // /v1/openapi.json
+	"error-codes",     // ErrorResponse with an error code and request ID on every error
+}
+
+// Authenticator issues and checks the pairing tokens devices send as "Authorization: Bearer <token>"
+type Authenticator interface {
+	// IssueToken creates a pairing token that is valid for validFor
+	IssueToken(validFor time.Duration) (token string, expiresAt time.Time, err error)
+	// Authenticate checks token and records the request as activity of its device.
+	// It returns pairing.ErrTokenExpired for expired tokens, any other error rejects the token as invalid.
+	Authenticate(token, clientIP, userAgent string) error
+	// Revoke invalidates token, it reports false if no device used it
+	Revoke(token string) bool
+}
+
+// ServerInfo describes the server binary in /info and on the /qr page
+type ServerInfo struct {
+	Name         string // Product name, e.g. "BMA CLI Music Server"
+	HTTPPort     int
+	LocalURL     string
+	TailscaleURL string // Empty without Tailscale
+	MusicPath    string // Enabled library roots, comma separated
+}
+
+// PreferredURL returns the URL devices should connect to, the Tailscale one if available
+func (si ServerInfo) PreferredURL() string {
+	if si.TailscaleURL != "" {
+		return si.TailscaleURL
+	}
+	return si.LocalURL
+}
+
+// Options configures a Handler
+type Options struct {
+	Library    *library.MusicLibrary // nil serves an empty library
+	Auth       Authenticator
+	ServerInfo func() ServerInfo // Called per request, the network may change while the server runs
+
+	// Metrics records the request, streaming and library metrics, nil keeps them in a private registry.
+	// Servers add their own gauges to it, e.g. the paired devices, and serve it on a listener of their own:
+	// /metrics needs no token, so it is never served on the API port.
+	Metrics *metrics.Registry
+}
+
+// Handler serves the API endpoints
+type Handler struct {
+	musicLibrary *library.MusicLibrary
+	auth         Authenticator
+	serverInfo   func() ServerInfo
+
+	registry *metrics.Registry
+	metrics  *apiMetrics
+
+	openAPIOnce sync.Once
+	openAPI     []byte // OpenAPI document, built on first request
+}
+
+// New creates the API handler of a server
+func New(options Options) *Handler {
+	registry := options.Metrics
+	if registry == nil {
+		registry = metrics.NewRegistry()
+	}
+	return &Handler{
+		musicLibrary: options.Library,
+		auth:         options.Auth,
+		serverInfo:   options.ServerInfo,
+		registry:     registry,
+		metrics:      newAPIMetrics(registry, options.Library),
+	}
+}
+
+// Register adds the API routes and middleware to router, which gives every request an ID, logs it
+// and counts it in the metrics. Every route is served below /v1, and without prefix for apps that
+// predate the versioned routes.
+func (h *Handler) Register(router *mux.Router) {
+	router.Use(RequestIDMiddleware)
+	router.Use(AccessLogMiddleware)
+	router.Use(h.metricsMiddleware)
+	router.Use(corsMiddleware)
+	router.Use(compressionMiddleware)
+	router.NotFoundHandler = h.unmatchedHandler(handleNotFound)
+	router.MethodNotAllowedHandler = h.unmatchedHandler(handleMethodNotAllowed)
+
+	for _, rt := range h.routes() {
+		handler := rt.handler
+		if rt.auth {
+			handler = h.requireAuth(handler)
+		}
+		router.HandleFunc("/"+APIVersion+rt.path, handler).Methods(rt.method)
+		router.HandleFunc(rt.path, handler).Methods(rt.method)
+	}
+
+	logger.Debug("API routes configured", "prefix", "/"+APIVersion)
+}
+
+// unmatchedHandler wraps the handler of requests no route matched. The router's middleware only
+// runs for matched routes, so it applies the request ID, logging, metrics and CORS headers itself.
+// Routes only match their own method, so CORS preflight requests (OPTIONS) are answered here too.
+func (h *Handler) unmatchedHandler(handler http.HandlerFunc) http.Handler {
+	return RequestIDMiddleware(AccessLogMiddleware(h.metricsMiddleware(corsMiddleware(handler))))
+}
+
+// corsMiddleware adds CORS headers for browser clients
+func corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-None-Match, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, X-Library-Revision, X-Total-Count, X-Request-ID")
+
+		// Handle preflight requests
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestUnmatchedRequests(t *testing.T) {
+	router := mux.NewRouter()
+	New(Options{}).Register(router)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		wantCode int
+		wantErr  ErrorCode // Error code of the body, "" for an empty body
+	}{
+		{"preflight", http.MethodOptions, "/v1/songs", http.StatusOK, ""},
+		{"preflight without prefix", http.MethodOptions, "/pair", http.StatusOK, ""},
+		{"wrong method", http.MethodDelete, "/v1/songs", http.StatusMethodNotAllowed, CodeMethodNotAllowed},
+		{"unknown endpoint", http.MethodGet, "/v1/nothing", http.StatusNotFound, CodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.path, nil)
+			request.Header.Set("Origin", "http://localhost:3000")
+			request.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantCode {
+				t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, recorder.Code, tt.wantCode)
+			}
+			if got := recorder.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+				t.Errorf("%s %s Access-Control-Allow-Origin = %q, want *", tt.method, tt.path, got)
+			}
+			if recorder.Header().Get(RequestIDHeader) == "" {
+				t.Errorf("%s %s has no request ID", tt.method, tt.path)
+			}
+
+			if tt.wantErr == "" {
+				if recorder.Body.Len() != 0 {
+					t.Errorf("%s %s body = %q, want none", tt.method, tt.path, recorder.Body.String())
+				}
+				return
+			}
+			var body ErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("%s %s body is not an error response: %v", tt.method, tt.path, err)
+			}
+			if body.Error != tt.wantErr {
+				t.Errorf("%s %s error code = %q, want %q", tt.method, tt.path, body.Error, tt.wantErr)
+			}
+		})
+	}
+}
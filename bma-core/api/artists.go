@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"bma-core/library"
+)
+
+// ArtistSummary is one entry of /artists
+type ArtistSummary struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	SortName      string `json:"sortName"`
+	AlbumCount    int    `json:"albumCount"`
+	TrackCount    int    `json:"trackCount"`
+	HasArtwork    bool   `json:"hasArtwork"`
+	ArtworkSongID string `json:"artworkSongId,omitempty"` // Fetch the artwork from /artwork/{artworkSongId}
+}
+
+// ArtistAlbum is an album in /artists/{id}
+type ArtistAlbum struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Year          int    `json:"year,omitempty"`
+	TrackCount    int    `json:"trackCount"`
+	ArtworkSongID string `json:"artworkSongId,omitempty"`
+}
+
+// ArtistTrack is a song in /artists/{id} that is not on one of the artist's albums
+type ArtistTrack struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Artist      string `json:"artist"`
+	Album       string `json:"album"`
+	TrackNumber int    `json:"trackNumber"`
+	HasArtwork  bool   `json:"hasArtwork"`
+	Offline     bool   `json:"offline"`
+}
+
+// ArtistDetail is the response of /artists/{id}
+type ArtistDetail struct {
+	ArtistSummary
+	Albums []ArtistAlbum `json:"albums"`
+	Tracks []ArtistTrack `json:"tracks"`
+}
+
+// newArtistSummary converts an artist for /artists
+func newArtistSummary(artist *library.Artist) ArtistSummary {
+	summary := ArtistSummary{
+		ID:         artist.ID.String(),
+		Name:       artist.Name,
+		SortName:   artist.SortName,
+		AlbumCount: artist.AlbumCount(),
+		TrackCount: artist.TrackCount(),
+	}
+	if song := artist.ArtworkSong(); song != nil {
+		summary.HasArtwork = true
+		summary.ArtworkSongID = song.ID.String()
+	}
+	return summary
+}
+
+// albumArtworkSongID returns the ID of the first song of an album with artwork, or ""
+func albumArtworkSongID(album *library.Album) string {
+	for _, song := range album.Songs {
+		if song.HasArtwork() {
+			return song.ID.String()
+		}
+	}
+	return ""
+}
+
+// handleArtists returns all artists sorted by sort name
+func (h *Handler) handleArtists(w http.ResponseWriter, r *http.Request) {
+	artists := []ArtistSummary{}
+	if h.musicLibrary != nil {
+		if libraryNotModified(w, r, h.musicLibrary, "") {
+			return
+		}
+		for _, artist := range h.musicLibrary.GetArtists() {
+			artists = append(artists, newArtistSummary(artist))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(artists); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to encode artists", "err", err)
+	}
+}
+
+// handleArtist returns one artist with its albums and the tracks it appears on elsewhere
+func (h *Handler) handleArtist(w http.ResponseWriter, r *http.Request) {
+	artistID := mux.Vars(r)["artistId"]
+	logger.DebugContext(r.Context(), "Artist requested", "artist_id", artistID)
+
+	if h.musicLibrary == nil {
+		WriteError(w, r, http.StatusServiceUnavailable, CodeLibraryUnavailable, "Music library not available")
+		return
+	}
+	if libraryNotModified(w, r, h.musicLibrary, "") {
+		return
+	}
+	artist := h.musicLibrary.GetArtistByID(artistID)
+	if artist == nil {
+		WriteError(w, r, http.StatusNotFound, CodeArtistNotFound, "Artist not found")
+		return
+	}
+
+	detail := ArtistDetail{
+		ArtistSummary: newArtistSummary(artist),
+		Albums:        []ArtistAlbum{},
+		Tracks:        []ArtistTrack{},
+	}
+	for _, album := range artist.Albums {
+		detail.Albums = append(detail.Albums, ArtistAlbum{
+			ID:            album.ID.String(),
+			Name:          album.Name,
+			Year:          album.Year,
+			TrackCount:    album.TrackCount(),
+			ArtworkSongID: albumArtworkSongID(album),
+		})
+	}
+	for _, song := range artist.Tracks() {
+		detail.Tracks = append(detail.Tracks, ArtistTrack{
+			ID:          song.ID.String(),
+			Title:       song.Title,
+			Artist:      song.Artist,
+			Album:       song.Album,
+			TrackNumber: song.TrackNumber,
+			HasArtwork:  song.HasArtwork(),
+			Offline:     song.Offline,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(detail); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to encode artist", "err", err)
+	}
+}
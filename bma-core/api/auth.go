@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"bma-core/pairing"
+)
+
+// AuthContextKey is used for storing auth data in request context
+type AuthContextKey string
+
+const (
+	TokenContextKey     AuthContextKey = "token"
+	UserAgentContextKey AuthContextKey = "userAgent"
+	ClientIPContextKey  AuthContextKey = "clientIP"
+)
+
+// requireAuth only lets requests with a valid Bearer pairing token through
+func (h *Handler) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Extract Authorization header
+		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" {
+			h.writeAuthError(w, r, CodeTokenMissing, "Missing authorization token")
+			return
+		}
+
+		// Validate Bearer token format
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			h.writeAuthError(w, r, CodeTokenMissing, "Invalid authorization format, expected a Bearer token")
+			return
+		}
+
+		// Extract token
+		token := strings.TrimPrefix(authHeader, "Bearer ")
+		if len(token) == 0 {
+			h.writeAuthError(w, r, CodeTokenMissing, "Empty authorization token")
+			return
+		}
+
+		// Extract client information
+		clientIP := extractClientIP(r)
+		userAgent := r.Header.Get("User-Agent")
+		if userAgent == "" {
+			userAgent = "unknown"
+		}
+
+		// Validate token and track the device
+		if err := h.auth.Authenticate(token, clientIP, userAgent); err != nil {
+			logger.WarnContext(r.Context(), "Token rejected", "token", truncateToken(token), "client", clientIP, "err", err)
+			if errors.Is(err, pairing.ErrTokenExpired) {
+				h.writeAuthError(w, r, CodeTokenExpired, "Pairing token expired, pair the device again")
+			} else {
+				h.writeAuthError(w, r, CodeTokenInvalid, "Invalid pairing token, pair the device again")
+			}
+			return
+		}
+
+		logger.DebugContext(r.Context(), "Token accepted", "token", truncateToken(token), "client", clientIP)
+
+		// Add auth data to request context
+		ctx := context.WithValue(r.Context(), TokenContextKey, token)
+		ctx = context.WithValue(ctx, ClientIPContextKey, clientIP)
+		ctx = context.WithValue(ctx, UserAgentContextKey, userAgent)
+
+		// Call next handler with enriched context
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// extractClientIP gets the real client IP, considering proxy headers
+func extractClientIP(r *http.Request) string {
+	// Check X-Forwarded-For header (most common proxy header)
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		// X-Forwarded-For can contain multiple IPs, take the first one
+		ips := strings.Split(forwarded, ",")
+		return strings.TrimSpace(ips[0])
+	}
+
+	// Check X-Real-IP header (Nginx)
+	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		return realIP
+	}
+
+	// Fall back to RemoteAddr
+	return r.RemoteAddr
+}
+
+// truncateToken safely truncates a token for logging
+func truncateToken(token string) string {
+	if len(token) <= 8 {
+		return strings.Repeat("*", len(token))
+	}
+	return token[:8] + "..."
+}
+
+// writeAuthError rejects a request that has no valid pairing token and counts it in the metrics
+func (h *Handler) writeAuthError(w http.ResponseWriter, r *http.Request, code ErrorCode, message string) {
+	h.metrics.authFailures.With(string(code)).Inc()
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	WriteError(w, r, http.StatusUnauthorized, code, message)
+}
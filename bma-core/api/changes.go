@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// revisionHeader carries the library revision a /songs or /albums response belongs to
+const revisionHeader = "X-Library-Revision"
+
+// SongChanges lists the added, updated and removed songs in /library/changes
+type SongChanges struct {
+	Added   []Song   `json:"added"`
+	Updated []Song   `json:"updated"`
+	Removed []string `json:"removed"` // IDs
+}
+
+// AlbumChanges lists the added, updated and removed albums in /library/changes
+type AlbumChanges struct {
+	Added   []Album  `json:"added"`
+	Updated []Album  `json:"updated"`
+	Removed []string `json:"removed"` // IDs
+}
+
+// ChangesResponse is the response of /library/changes
+type ChangesResponse struct {
+	Revision   int64        `json:"revision"`
+	Since      int64        `json:"since"`
+	FullResync bool         `json:"fullResync"` // Fetch /songs and /albums again, the changes are empty
+	Songs      SongChanges  `json:"songs"`
+	Albums     AlbumChanges `json:"albums"`
+}
+
+// handleLibraryChanges returns the songs and albums changed after the revision in ?since=
+func (h *Handler) handleLibraryChanges(w http.ResponseWriter, r *http.Request) {
+	since, err := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+	if err != nil {
+		WriteError(w, r, http.StatusBadRequest, CodeInvalidRequest, "Missing or invalid since revision")
+		return
+	}
+	if h.musicLibrary == nil {
+		WriteError(w, r, http.StatusServiceUnavailable, CodeLibraryUnavailable, "Music library not available")
+		return
+	}
+
+	changes := h.musicLibrary.ChangesSince(since)
+	response := ChangesResponse{
+		Revision:   changes.Revision,
+		Since:      since,
+		FullResync: changes.FullResync,
+		Songs:      SongChanges{Added: []Song{}, Updated: []Song{}, Removed: changes.RemovedSongs},
+		Albums:     AlbumChanges{Added: []Album{}, Updated: []Album{}, Removed: changes.RemovedAlbums},
+	}
+	for _, song := range changes.AddedSongs {
+		response.Songs.Added = append(response.Songs.Added, newSong(song))
+	}
+	for _, song := range changes.UpdatedSongs {
+		response.Songs.Updated = append(response.Songs.Updated, newSong(song))
+	}
+	for _, album := range changes.AddedAlbums {
+		response.Albums.Added = append(response.Albums.Added, newAlbum(album))
+	}
+	for _, album := range changes.UpdatedAlbums {
+		response.Albums.Updated = append(response.Albums.Updated, newAlbum(album))
+	}
+
+	if changes.FullResync {
+		logger.DebugContext(r.Context(), "Changes requested, client must resync", "since", since, "revision", changes.Revision)
+	} else {
+		logger.DebugContext(r.Context(), "Changes requested", "since", since, "revision", changes.Revision,
+			"songs", len(response.Songs.Added)+len(response.Songs.Updated)+len(response.Songs.Removed),
+			"albums", len(response.Albums.Added)+len(response.Albums.Updated)+len(response.Albums.Removed))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to encode library changes", "err", err)
+	}
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressMinSize is the smallest response body worth compressing
+const compressMinSize = 1024
+
+// responseEncoders are the content codings the server can produce, in order of preference when
+// the client accepts several with the same quality
+var responseEncoders = []struct {
+	name string
+	pool *sync.Pool
+}{
+	{"zstd", &sync.Pool{New: func() interface{} {
+		// One goroutine per encoder, the pool already gives each response its own
+		encoder, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+		return encoder
+	}}},
+	{"gzip", &sync.Pool{New: func() interface{} { return gzip.NewWriter(io.Discard) }}},
+}
+
+// encoderWriter is what each responseEncoders pool holds
+type encoderWriter interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+	Flush() error
+}
+
+// negotiateEncoding picks a content coding from an Accept-Encoding header, "" for an uncompressed response
+func negotiateEncoding(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		qualities[name] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, encoder := range responseEncoders {
+		quality, ok := qualities[encoder.name]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > bestQuality {
+			best, bestQuality = encoder.name, quality
+		}
+	}
+	return best
+}
+
+// compressionMiddleware compresses JSON and text responses with the encoding negotiated through Accept-Encoding.
+// Streams and artwork pass through untouched, as do small bodies and responses that are already encoded.
+func compressionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, status: http.StatusOK}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// compressWriter buffers the start of a response and compresses it once it reaches compressMinSize
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string
+	status      int
+	wroteHeader bool
+	passthrough bool // Response is not compressible, writes go straight to the client
+	buffer      []byte
+	encoder     encoderWriter // Set once compression started
+	pool        *sync.Pool
+}
+
+// compressible reports whether a response with the current headers and status may be compressed
+func (cw *compressWriter) compressible() bool {
+	header := cw.Header()
+	if cw.status != http.StatusOK || header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	return strings.HasPrefix(contentType, "application/json") || strings.HasPrefix(contentType, "text/")
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = code
+	if !cw.compressible() {
+		cw.passthrough = true
+		cw.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (cw *compressWriter) Write(data []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.passthrough {
+		return cw.ResponseWriter.Write(data)
+	}
+	if cw.encoder != nil {
+		return cw.encoder.Write(data)
+	}
+
+	cw.buffer = append(cw.buffer, data...)
+	if len(cw.buffer) >= compressMinSize {
+		if err := cw.startEncoding(); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// startEncoding sends the headers of the compressed response and the buffered start of the body
+func (cw *compressWriter) startEncoding() error {
+	header := cw.Header()
+	header.Set("Content-Encoding", cw.encoding)
+	header.Del("Content-Length")
+	// A compressed body is a different representation, so it needs its own strong ETag
+	if etag := header.Get("ETag"); strings.HasSuffix(etag, `"`) {
+		header.Set("ETag", strings.TrimSuffix(etag, `"`)+"-"+cw.encoding+`"`)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	for _, encoder := range responseEncoders {
+		if encoder.name == cw.encoding {
+			cw.pool = encoder.pool
+		}
+	}
+	cw.encoder = cw.pool.Get().(encoderWriter)
+	cw.encoder.Reset(cw.ResponseWriter)
+
+	buffered := cw.buffer
+	cw.buffer = nil
+	_, err := cw.encoder.Write(buffered)
+	return err
+}
+
+// Flush sends what was written so far, compressing it if the body is large enough
+func (cw *compressWriter) Flush() {
+	if !cw.passthrough && cw.encoder == nil && len(cw.buffer) > 0 {
+		cw.startEncoding()
+	}
+	if cw.encoder != nil {
+		cw.encoder.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close finishes the compressed stream, or sends a body too small to compress as is
+func (cw *compressWriter) Close() error {
+	if cw.encoder != nil {
+		err := cw.encoder.Close()
+		cw.encoder.Reset(io.Discard)
+		cw.pool.Put(cw.encoder)
+		cw.encoder = nil
+		return err
+	}
+	if cw.passthrough || !cw.wroteHeader {
+		return nil
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	_, err := cw.ResponseWriter.Write(cw.buffer)
+	return err
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"GZIP", "gzip"},
+		{"zstd", "zstd"},
+		{"gzip, deflate, br, zstd", "zstd"}, // zstd wins a tie
+		{"gzip;q=1.0, zstd;q=0.5", "gzip"},
+		{"zstd;q=0, gzip", "gzip"},
+		{"gzip;q=0", ""},
+		{"*", "zstd"},
+		{"*;q=0.5, gzip", "gzip"},
+		{"zstd;q=0, *", "gzip"},
+		{"gzip;q=abc", ""}, // Invalid quality
+	}
+
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.acceptEncoding); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.acceptEncoding, got, tt.want)
+		}
+	}
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	large := strings.Repeat(`{"title":"Waterloo"}`, compressMinSize/10)
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		status         int
+		body           string
+		wantEncoding   string
+		wantETag       string
+	}{
+		{"large JSON with gzip", "gzip", "application/json", http.StatusOK, large, "gzip", `"1-abc-gzip"`},
+		{"large JSON with zstd", "zstd, gzip", "application/json", http.StatusOK, large, "zstd", `"1-abc-zstd"`},
+		{"text", "gzip", "text/plain; charset=utf-8", http.StatusOK, large, "gzip", `"1-abc-gzip"`},
+		{"just below the threshold", "gzip", "application/json", http.StatusOK, large[:compressMinSize-1], "", `"1-abc"`},
+		{"at the threshold", "gzip", "application/json", http.StatusOK, large[:compressMinSize], "gzip", `"1-abc-gzip"`},
+		{"client without compression", "", "application/json", http.StatusOK, large, "", `"1-abc"`},
+		{"audio", "gzip", "audio/mpeg", http.StatusOK, large, "", `"1-abc"`},
+		{"error status", "gzip", "application/json", http.StatusNotFound, large, "", `"1-abc"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := compressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Header().Set("ETag", `"1-abc"`)
+				w.WriteHeader(tt.status)
+				// Several writes, so the threshold is reached while buffering
+				for i := 0; i < len(tt.body); i += 100 {
+					io.WriteString(w, tt.body[i:min(i+100, len(tt.body))])
+				}
+			}))
+
+			request := httptest.NewRequest("GET", "/v1/songs", nil)
+			if tt.acceptEncoding != "" {
+				request.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.status)
+			}
+			if got := recorder.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := recorder.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if got := recorder.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want Accept-Encoding", got)
+			}
+			if body := decodeBody(t, tt.wantEncoding, recorder.Body.Bytes()); body != tt.body {
+				t.Errorf("body has %d bytes after decoding, want %d", len(body), len(tt.body))
+			}
+		})
+	}
+}
+
+// decodeBody undoes the content coding of a response body
+func decodeBody(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+	var reader io.Reader = bytes.NewReader(body)
+	switch encoding {
+	case "gzip":
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = gzipReader
+	case "zstd":
+		zstdReader, err := zstd.NewReader(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer zstdReader.Close()
+		reader = zstdReader
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("decoding %s body: %v", encoding, err)
+	}
+	return string(decoded)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ErrorCode identifies the cause of an error response, so clients can tell failures apart without parsing messages
+type ErrorCode string
+
+const (
+	CodeInvalidRequest     ErrorCode = "invalid_request"     // Missing or invalid parameter
+	CodeCursorExpired      ErrorCode = "cursor_expired"      // The library changed since the first page, start again
+	CodeNotFound           ErrorCode = "not_found"           // No such endpoint
+	CodeMethodNotAllowed   ErrorCode = "method_not_allowed"  // Endpoint exists, but not for this method
+	CodeTokenMissing       ErrorCode = "token_missing"       // No "Authorization: Bearer" header
+	CodeTokenInvalid       ErrorCode = "token_invalid"       // Unknown or revoked token, pair again
+	CodeTokenExpired       ErrorCode = "token_expired"       // Token lifetime is over, pair again
+	CodeLibraryUnavailable ErrorCode = "library_unavailable" // The server has no music library
+	CodeLibraryNotScanned  ErrorCode = "library_not_scanned" // The library has not finished its first scan
+	CodeSongNotFound       ErrorCode = "song_not_found"      // No song with this ID, it may have been deleted
+	CodeArtistNotFound     ErrorCode = "artist_not_found"
+	CodeArtworkNotFound    ErrorCode = "artwork_not_found" // The song has no embedded artwork
+	CodeSongOffline        ErrorCode = "song_offline"      // The song's library root is unavailable, retry later
+	CodeFileMissing        ErrorCode = "file_missing"      // The song's file was deleted since the last scan
+	CodeFileUnreadable     ErrorCode = "file_unreadable"   // The song's file exists but cannot be read
+	CodeScanInProgress     ErrorCode = "scan_in_progress"
+	CodeNoScanRunning      ErrorCode = "no_scan_running"
+	CodeInternal           ErrorCode = "internal_error"
+)
+
+// ErrorResponse is the JSON body of every error response
+type ErrorResponse struct {
+	Error     ErrorCode   `json:"error"`
+	Message   string      `json:"message"` // For people, may change between versions
+	Status    int         `json:"status"`
+	RequestID string      `json:"requestId"`         // Also in the X-Request-ID header and the server log
+	Reason    string      `json:"reason,omitempty"`  // song_offline: why the root is unavailable
+	Root      string      `json:"root,omitempty"`    // song_offline: the unavailable library root
+	Details   interface{} `json:"details,omitempty"` // Code specific, e.g. the scan status of scan_in_progress
+}
+
+// WriteError writes an error response with the request's ID
+func WriteError(w http.ResponseWriter, r *http.Request, status int, code ErrorCode, message string) {
+	writeErrorResponse(w, r, ErrorResponse{Error: code, Message: message, Status: status})
+}
+
+// writeErrorResponse writes a prepared error response, filling in the request ID
+func writeErrorResponse(w http.ResponseWriter, r *http.Request, response ErrorResponse) {
+	response.RequestID = requestIDOf(w, r)
+
+	// Errors are not cacheable like the library responses that set these before failing
+	w.Header().Del("ETag")
+	w.Header().Del("Cache-Control")
+
+	logger.DebugContext(r.Context(), "Request failed", "status", response.Status, "code", response.Error, "message", response.Message)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.Status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to encode error response", "err", err)
+	}
+}
+
+// handleNotFound answers requests for unknown endpoints
+func handleNotFound(w http.ResponseWriter, r *http.Request) {
+	WriteError(w, r, http.StatusNotFound, CodeNotFound, "No such endpoint: "+r.URL.Path)
+}
+
+// handleMethodNotAllowed answers requests with a method the endpoint does not support
+func handleMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	WriteError(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, r.Method+" is not supported by "+r.URL.Path)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"bma-core/library"
+)
+
+// libraryETag is a strong ETag for a library response: the revision, and a hash of the request and of extra,
+// since the query parameters and anything not covered by the revision change the body too
+func libraryETag(revision int64, r *http.Request, extra string) string {
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%s?%s|%s", r.URL.Path, r.URL.RawQuery, extra)
+	return fmt.Sprintf(`"%d-%s"`, revision, strconv.FormatUint(hash.Sum64(), 36))
+}
+
+// matchingETag returns the entry of an If-None-Match header naming etag in any content coding, "" if none does
+func matchingETag(ifNoneMatch, etag string) string {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return etag
+		}
+		for _, encoder := range responseEncoders {
+			if strings.TrimSuffix(candidate, "-"+encoder.name+`"`)+`"` == etag {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
+
+// libraryNotModified sets the revision and ETag headers of a library response and answers 304
+// when the client already has it. Call it before reading the library, so a change in between is sent
+// again rather than missed.
+// extra lists response data the revision does not cover, "" if there is none.
+func libraryNotModified(w http.ResponseWriter, r *http.Request, musicLibrary *library.MusicLibrary, extra string) bool {
+	revision := musicLibrary.GetRevision()
+	etag := libraryETag(revision, r, extra)
+
+	header := w.Header()
+	header.Set(revisionHeader, strconv.FormatInt(revision, 10))
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", "no-cache")
+
+	if matched := matchingETag(r.Header.Get("If-None-Match"), etag); matched != "" {
+		// Answer with the ETag of the representation the client has, compressed or not
+		header.Set("ETag", matched)
+		logger.DebugContext(r.Context(), "Not modified", "revision", revision)
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
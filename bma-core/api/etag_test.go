@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMatchingETag(t *testing.T) {
+	const etag = `"1792361220082-1vps6mu"`
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        string
+	}{
+		{"no header", "", ""},
+		{"same", etag, etag},
+		{"weak", "W/" + etag, etag},
+		{"gzip", `"1792361220082-1vps6mu-gzip"`, `"1792361220082-1vps6mu-gzip"`},
+		{"zstd", `"1792361220082-1vps6mu-zstd"`, `"1792361220082-1vps6mu-zstd"`},
+		{"unknown encoding", `"1792361220082-1vps6mu-br"`, ""},
+		{"one of several", `"1-abc", "1792361220082-1vps6mu-gzip"`, `"1792361220082-1vps6mu-gzip"`},
+		{"any", "*", etag},
+		{"older revision", `"1792361220081-1vps6mu"`, ""},
+		{"other request", `"1792361220082-xyz-gzip"`, ""},
+	}
+
+	for _, tt := range tests {
+		if got := matchingETag(tt.ifNoneMatch, etag); got != tt.want {
+			t.Errorf("%s: matchingETag(%q) = %q, want %q", tt.name, tt.ifNoneMatch, got, tt.want)
+		}
+	}
+}
+
+func TestLibraryETag(t *testing.T) {
+	request := func(target string) string {
+		return libraryETag(7, httptest.NewRequest("GET", target, nil), "")
+	}
+
+	if request("/v1/songs?sort=title") != request("/v1/songs?sort=title") {
+		t.Error("libraryETag differs for the same request")
+	}
+	if request("/v1/songs?sort=title") == request("/v1/songs?sort=year") {
+		t.Error("libraryETag is the same for different query parameters")
+	}
+	if request("/v1/songs") == libraryETag(8, httptest.NewRequest("GET", "/v1/songs", nil), "") {
+		t.Error("libraryETag is the same for different revisions")
+	}
+	if request("/v1/info") == libraryETag(7, httptest.NewRequest("GET", "/v1/info", nil), "scanning") {
+		t.Error("libraryETag ignores extra")
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"bma-core/library"
+)
+
+// handleHealth returns server health status
+func (h *Handler) handleHealth(w http.ResponseWriter, r *http.Request) {
+	response := HealthResponse{Status: "healthy"}
+	if h.musicLibrary != nil {
+		// The server answers while the first scan runs, so the setup page can show its progress
+		if progress := h.musicLibrary.GetScanProgress(); progress.Scanning {
+			response.Scan = &HealthScan{Discovered: progress.Discovered, Processed: progress.Processed}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleInfo returns server information
+func (h *Handler) handleInfo(w http.ResponseWriter, r *http.Request) {
+	// Get music library statistics
+	var albumCount, songCount int
+	roots := []library.RootStatus{}
+	var lastScan library.ScanStats
+	var revision int64
+	if h.musicLibrary != nil {
+		roots = h.musicLibrary.GetRootStatuses()
+		lastScan = h.musicLibrary.GetLastScanStats()
+		// Scan statistics and root availability change without a new revision
+		if libraryNotModified(w, r, h.musicLibrary, fmt.Sprintf("%+v|%+v", lastScan, roots)) {
+			return
+		}
+		albumCount = h.musicLibrary.GetAlbumCount()
+		songCount = h.musicLibrary.GetSongCount()
+		revision = h.musicLibrary.GetRevision()
+	}
+
+	info := h.serverInfo()
+	response := InfoResponse{
+		Server:       info.Name,
+		Version:      Version,
+		APIVersion:   APIVersion,
+		Capabilities: Capabilities,
+		HasTailscale: info.TailscaleURL != "",
+		TailscaleURL: info.TailscaleURL,
+		HTTPSPort:    8443, // For compatibility
+		HTTPPort:     info.HTTPPort,
+		Protocol:     "http", // Also over Tailscale, which encrypts the connection itself
+		Library: LibraryInfo{
+			AlbumCount: albumCount,
+			SongCount:  songCount,
+			HasLibrary: h.musicLibrary != nil,
+			MusicPath:  info.MusicPath,
+			Roots:      roots,
+			LastScan:   lastScan,
+			Revision:   revision,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to encode server info", "err", err)
+	}
+}
+
+// handleSongs returns the list of all songs, optionally filtered, sorted and paginated
+func (h *Handler) handleSongs(w http.ResponseWriter, r *http.Request) {
+	params, err := parseListParams(r, library.SongSortKeys)
+	if err != nil {
+		WriteError(w, r, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Check if music library is available
+	if h.musicLibrary == nil {
+		logger.WarnContext(r.Context(), "No music library available")
+		writeList(w, r, []interface{}{}, 0, "", params)
+		return
+	}
+
+	// Get songs from the music library, unless the client has this revision already
+	if libraryNotModified(w, r, h.musicLibrary, "") {
+		return
+	}
+	librarySongs, revision := h.musicLibrary.GetSongsAtRevision()
+	if params.cursorExpired(revision) {
+		WriteError(w, r, http.StatusGone, CodeCursorExpired, "The library changed since the first page was fetched, start again without a cursor")
+		return
+	}
+
+	librarySongs = library.FilterSongs(librarySongs, params.filter)
+	librarySongs, _ = library.SortSongs(librarySongs, params.sort, params.descending)
+	start, end, nextCursor := params.page(len(librarySongs), revision)
+
+	// Convert songs to JSON-compatible format, only for the requested page
+	songs := make([]interface{}, 0, end-start)
+	for i, song := range librarySongs[start:end] {
+		item := newSong(song)
+		sortOrder := start + i // Explicit sort order for Android to maintain
+		item.SortOrder = &sortOrder
+		songs = append(songs, params.selectFields(item))
+	}
+
+	logger.DebugContext(r.Context(), "Songs listed", "returned", len(songs), "matching", len(librarySongs))
+	writeList(w, r, songs, len(librarySongs), nextCursor, params)
+}
+
+// handleAlbums returns the list of all albums, optionally filtered, sorted and paginated
+func (h *Handler) handleAlbums(w http.ResponseWriter, r *http.Request) {
+	params, err := parseListParams(r, library.AlbumSortKeys)
+	if err != nil {
+		WriteError(w, r, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Check if music library is available
+	if h.musicLibrary == nil {
+		logger.WarnContext(r.Context(), "No music library available")
+		writeList(w, r, []interface{}{}, 0, "", params)
+		return
+	}
+
+	// Get albums from the music library, unless the client has this revision already
+	if libraryNotModified(w, r, h.musicLibrary, "") {
+		return
+	}
+	libraryAlbums, revision := h.musicLibrary.GetAlbumsAtRevision()
+	if params.cursorExpired(revision) {
+		WriteError(w, r, http.StatusGone, CodeCursorExpired, "The library changed since the first page was fetched, start again without a cursor")
+		return
+	}
+
+	libraryAlbums = library.FilterAlbums(libraryAlbums, params.filter)
+	libraryAlbums, _ = library.SortAlbums(libraryAlbums, params.sort, params.descending)
+	start, end, nextCursor := params.page(len(libraryAlbums), revision)
+
+	// Convert albums to JSON-compatible format, only for the requested page
+	albums := make([]interface{}, 0, end-start)
+	for _, album := range libraryAlbums[start:end] {
+		albums = append(albums, params.selectFields(newAlbum(album)))
+	}
+
+	logger.DebugContext(r.Context(), "Albums listed", "returned", len(albums), "matching", len(libraryAlbums))
+	writeList(w, r, albums, len(libraryAlbums), nextCursor, params)
+}
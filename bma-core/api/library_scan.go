@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"bma-core/library"
+)
+
+// ScanStatus is the response of /library/scan
+type ScanStatus struct {
+	Scanning bool                 `json:"scanning"`
+	Progress library.ScanProgress `json:"progress"`
+	LastScan library.ScanStats    `json:"lastScan"`
+	Roots    []library.RootStatus `json:"roots"`
+}
+
+// handleScanStatus reports the progress of the running or last scan
+func (h *Handler) handleScanStatus(w http.ResponseWriter, r *http.Request) {
+	h.writeScanStatus(w, http.StatusOK)
+}
+
+// writeScanConflict rejects a scan request that conflicts with the running scan, or the lack of one
+func (h *Handler) writeScanConflict(w http.ResponseWriter, r *http.Request, code ErrorCode, message string) {
+	writeErrorResponse(w, r, ErrorResponse{
+		Error:   code,
+		Message: message,
+		Status:  http.StatusConflict,
+		Details: h.scanStatus(),
+	})
+}
+
+// handleScanStart starts a background rescan, or returns 409 if one is running
+func (h *Handler) handleScanStart(w http.ResponseWriter, r *http.Request) {
+	if h.musicLibrary == nil {
+		WriteError(w, r, http.StatusServiceUnavailable, CodeLibraryUnavailable, "Music library not available")
+		return
+	}
+
+	// The scan outlives the request, so it must not use the request context
+	if err := h.musicLibrary.StartScan(context.Background()); err == library.ErrScanInProgress {
+		h.writeScanConflict(w, r, CodeScanInProgress, "A library scan is already running")
+		return
+	}
+	logger.InfoContext(r.Context(), "Library rescan requested over HTTP")
+	h.writeScanStatus(w, http.StatusAccepted)
+}
+
+// handleScanCancel stops the running scan, keeping the previous library
+func (h *Handler) handleScanCancel(w http.ResponseWriter, r *http.Request) {
+	if h.musicLibrary == nil {
+		WriteError(w, r, http.StatusServiceUnavailable, CodeLibraryUnavailable, "Music library not available")
+		return
+	}
+	if !h.musicLibrary.CancelScan() {
+		h.writeScanConflict(w, r, CodeNoScanRunning, "No library scan is running")
+		return
+	}
+	logger.InfoContext(r.Context(), "Library scan cancelled over HTTP")
+	h.writeScanStatus(w, http.StatusOK)
+}
+
+// scanStatus returns the progress of the running or last scan
+func (h *Handler) scanStatus() ScanStatus {
+	status := ScanStatus{Roots: []library.RootStatus{}}
+	if h.musicLibrary != nil {
+		status.Progress = h.musicLibrary.GetScanProgress()
+		status.Scanning = status.Progress.Scanning
+		status.LastScan = h.musicLibrary.GetLastScanStats()
+		status.Roots = h.musicLibrary.GetRootStatuses()
+	}
+	return status
+}
+
+// writeScanStatus writes the current ScanStatus with the given status code
+func (h *Handler) writeScanStatus(w http.ResponseWriter, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(h.scanStatus())
+}
+
+// handleLibraryHealth returns the problems found by the last scan
+func (h *Handler) handleLibraryHealth(w http.ResponseWriter, r *http.Request) {
+	if h.musicLibrary == nil {
+		WriteError(w, r, http.StatusServiceUnavailable, CodeLibraryUnavailable, "Music library not available")
+		return
+	}
+	report := h.musicLibrary.GetHealthReport()
+	if report == nil {
+		WriteError(w, r, http.StatusServiceUnavailable, CodeLibraryNotScanned, "The library has not been scanned yet")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
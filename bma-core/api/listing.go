@@ -0,0 +1,204 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"bma-core/library"
+)
+
+// Page sizes of paginated /songs and /albums requests
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// listPage is the response of /songs and /albums when a limit or cursor is given, see SongPage and AlbumPage
+type listPage struct {
+	Items      []interface{} `json:"items"`
+	Total      int           `json:"total"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
+
+// listParams holds the filter, sort, field and page parameters of a list request
+type listParams struct {
+	filter     library.LibraryFilter
+	sort       string
+	descending bool
+	fields     map[string]bool // nil returns all fields
+	paginated  bool
+	offset     int
+	limit      int
+	revision   int64 // Library revision of the cursor, 0 without one
+}
+
+// parseListParams reads the query parameters of /songs or /albums. sortKeys lists the valid sort values.
+func parseListParams(r *http.Request, sortKeys []string) (listParams, error) {
+	query := r.URL.Query()
+	params := listParams{
+		filter: library.LibraryFilter{
+			Album:  query.Get("album"),
+			Artist: query.Get("artist"),
+			Genre:  query.Get("genre"),
+			Folder: query.Get("folder"),
+		},
+		sort: query.Get("sort"),
+	}
+
+	if year := query.Get("year"); year != "" {
+		from, to, err := library.ParseYearRange(year)
+		if err != nil {
+			return params, err
+		}
+		params.filter.YearFrom, params.filter.YearTo = from, to
+	}
+
+	if params.sort != "" && !containsString(sortKeys, params.sort) {
+		return params, fmt.Errorf("unknown sort %q, use one of %s", params.sort, strings.Join(sortKeys, ", "))
+	}
+	switch order := query.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		params.descending = true
+	default:
+		return params, fmt.Errorf("unknown order %q, use asc or desc", order)
+	}
+
+	if fields := query.Get("fields"); fields != "" {
+		params.fields = map[string]bool{"id": true}
+		for _, field := range strings.Split(fields, ",") {
+			params.fields[strings.TrimSpace(field)] = true
+		}
+	}
+
+	params.limit = defaultPageSize
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 {
+			return params, fmt.Errorf("invalid limit %q", limit)
+		}
+		params.limit = value
+		if params.limit > maxPageSize {
+			params.limit = maxPageSize
+		}
+		params.paginated = true
+	}
+	if cursor := query.Get("cursor"); cursor != "" {
+		offset, revision, err := params.decodeCursor(cursor)
+		if err != nil {
+			return params, err
+		}
+		params.offset = offset
+		params.revision = revision
+		params.paginated = true
+	}
+	return params, nil
+}
+
+// signature identifies the filters and sort order, so a cursor cannot be reused with different ones
+func (p listParams) signature() string {
+	hash := fnv.New32a()
+	fmt.Fprintf(hash, "%+v|%s|%t", p.filter, p.sort, p.descending)
+	return strconv.FormatUint(uint64(hash.Sum32()), 36)
+}
+
+// encodeCursor returns the cursor of the page starting at offset in the items of a library revision
+func (p listParams) encodeCursor(offset int, revision int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d:%s", offset, revision, p.signature())))
+}
+
+// decodeCursor returns the offset and library revision stored in a cursor made by encodeCursor
+func (p listParams) decodeCursor(cursor string) (int, int64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid cursor")
+	}
+	parts := strings.SplitN(string(data), ":", 3)
+	if len(parts) != 3 {
+		return 0, 0, fmt.Errorf("invalid cursor")
+	}
+	offset, err1 := strconv.Atoi(parts[0])
+	revision, err2 := strconv.ParseInt(parts[1], 10, 64)
+	if err1 != nil || err2 != nil || offset < 0 || revision <= 0 {
+		return 0, 0, fmt.Errorf("invalid cursor")
+	}
+	if parts[2] != p.signature() {
+		return 0, 0, fmt.Errorf("cursor was made for different filters or sort order")
+	}
+	return offset, revision, nil
+}
+
+// cursorExpired reports whether the cursor was made for another library revision, whose
+// items had different offsets. The client must start again from the first page.
+func (p listParams) cursorExpired(revision int64) bool {
+	return p.revision != 0 && p.revision != revision
+}
+
+// page returns the range of the total items of a library revision to return, and the cursor
+// of the next page if there is one
+func (p listParams) page(total int, revision int64) (start, end int, nextCursor string) {
+	if !p.paginated {
+		return 0, total, ""
+	}
+	start = p.offset
+	if start > total {
+		start = total
+	}
+	end = start + p.limit
+	if end >= total {
+		return start, total, ""
+	}
+	return start, end, p.encodeCursor(end, revision)
+}
+
+// selectFields drops the fields not asked for with ?fields=
+func (p listParams) selectFields(item interface{}) interface{} {
+	if p.fields == nil {
+		return item
+	}
+
+	// Round trip through JSON, so the selected fields keep the names and encoding of the full item
+	data, err := json.Marshal(item)
+	if err != nil {
+		return item
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return item
+	}
+	for key := range fields {
+		if !p.fields[key] {
+			delete(fields, key)
+		}
+	}
+	return fields
+}
+
+// writeList writes one page of items, or a plain array for clients that did not ask for pages
+func writeList(w http.ResponseWriter, r *http.Request, items []interface{}, total int, nextCursor string, params listParams) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+
+	var body interface{} = items
+	if params.paginated {
+		body = listPage{Items: items, Total: total, NextCursor: nextCursor}
+	}
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to encode list", "err", err)
+	}
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"testing"
+
+	"bma-core/library"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	params := listParams{sort: "year", descending: true}
+	for _, tt := range []struct {
+		offset   int
+		revision int64
+	}{
+		{0, 1},
+		{500, 1792361220082},
+		{123456, 42},
+	} {
+		offset, revision, err := params.decodeCursor(params.encodeCursor(tt.offset, tt.revision))
+		if err != nil {
+			t.Fatalf("decodeCursor(encodeCursor(%d, %d)) error = %v", tt.offset, tt.revision, err)
+		}
+		if offset != tt.offset || revision != tt.revision {
+			t.Errorf("decodeCursor(encodeCursor(%d, %d)) = %d, %d", tt.offset, tt.revision, offset, revision)
+		}
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	params := listParams{sort: "title"}
+	encode := func(text string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(text))
+	}
+
+	tests := []struct {
+		name    string
+		params  listParams
+		cursor  string
+		wantErr string
+	}{
+		{"valid", params, params.encodeCursor(100, 7), ""},
+		{"other sort", listParams{sort: "year"}, params.encodeCursor(100, 7), "cursor was made for different filters or sort order"},
+		{"other order", listParams{sort: "title", descending: true}, params.encodeCursor(100, 7), "cursor was made for different filters or sort order"},
+		{"other filter", listParams{sort: "title", filter: library.LibraryFilter{Genre: "Jazz"}}, params.encodeCursor(100, 7), "cursor was made for different filters or sort order"},
+		{"not base64", params, "not a cursor!", "invalid cursor"},
+		{"without revision", params, encode("100:" + params.signature()), "invalid cursor"},
+		{"negative offset", params, encode("-1:7:" + params.signature()), "invalid cursor"},
+		{"zero revision", params, encode("100:0:" + params.signature()), "invalid cursor"},
+		{"offset not a number", params, encode("x:7:" + params.signature()), "invalid cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tt.params.decodeCursor(tt.cursor)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("decodeCursor() error = %v, want none", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Errorf("decodeCursor() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCursorExpired(t *testing.T) {
+	tests := []struct {
+		name           string
+		cursorRevision int64
+		revision       int64
+		want           bool
+	}{
+		{"no cursor", 0, 10, false},
+		{"same revision", 10, 10, false},
+		{"library changed", 10, 11, true},
+		{"server restarted", 10, 5, true},
+	}
+
+	for _, tt := range tests {
+		params := listParams{revision: tt.cursorRevision}
+		if got := params.cursorExpired(tt.revision); got != tt.want {
+			t.Errorf("%s: cursorExpired(%d) = %v, want %v", tt.name, tt.revision, got, tt.want)
+		}
+	}
+}
+
+func TestListPages(t *testing.T) {
+	const revision = 1792361220082
+	const total = 250
+
+	// Follow the cursors through all pages, like a client would
+	var seen, pages int
+	query := "/v1/songs?limit=100&sort=title"
+	for {
+		params, err := parseListParams(httptest.NewRequest("GET", query, nil), library.SongSortKeys)
+		if err != nil {
+			t.Fatalf("parseListParams(%q) error = %v", query, err)
+		}
+		if params.cursorExpired(revision) {
+			t.Fatalf("cursor of %q expired at the same revision", query)
+		}
+
+		start, end, next := params.page(total, revision)
+		if start != seen {
+			t.Fatalf("page %d starts at %d, want %d", pages, start, seen)
+		}
+		seen = end
+		pages++
+		if next == "" {
+			break
+		}
+		query = "/v1/songs?limit=100&sort=title&cursor=" + next
+	}
+	if seen != total || pages != 3 {
+		t.Errorf("got %d items in %d pages, want %d in 3", seen, pages, total)
+	}
+
+	// A cursor from before a rescan is rejected instead of shifting the page
+	params, err := parseListParams(httptest.NewRequest("GET", query, nil), library.SongSortKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !params.cursorExpired(revision + 1) {
+		t.Errorf("cursor of %q did not expire after the library changed", query)
+	}
+
+	// Without limit or cursor, the whole list is returned
+	params, _ = parseListParams(httptest.NewRequest("GET", "/v1/songs", nil), library.SongSortKeys)
+	if start, end, next := params.page(total, revision); start != 0 || end != total || next != "" {
+		t.Errorf("unpaginated page = %d, %d, %q, want 0, %d, no cursor", start, end, next, total)
+	}
+}
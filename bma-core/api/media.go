@@ -0,0 +1,143 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"bma-core/library"
+	"github.com/gorilla/mux"
+)
+
+// handleStream serves MP3 file content for a given song ID
+func (h *Handler) handleStream(w http.ResponseWriter, r *http.Request) {
+	songID := mux.Vars(r)["songId"]
+	if songID == "" {
+		WriteError(w, r, http.StatusBadRequest, CodeInvalidRequest, "Missing song ID")
+		return
+	}
+
+	// Check if music library is available
+	if h.musicLibrary == nil {
+		WriteError(w, r, http.StatusServiceUnavailable, CodeLibraryUnavailable, "Music library not available")
+		return
+	}
+
+	// Find the song in the music library
+	song := h.musicLibrary.GetSongByID(songID)
+	if song == nil {
+		WriteError(w, r, http.StatusNotFound, CodeSongNotFound, "Song not found")
+		return
+	}
+
+	// Songs on an unplugged drive stay in the library but cannot be streamed
+	var offlineErr *library.OfflineError
+	if err := h.musicLibrary.CheckSongAvailable(song); errors.As(err, &offlineErr) {
+		writeOfflineError(w, r, offlineErr)
+		return
+	}
+
+	// Open the file, it may have been deleted or lost its permissions since the last scan
+	file, err := os.Open(song.Path)
+	if os.IsNotExist(err) {
+		logger.WarnContext(r.Context(), "Music file missing", "path", song.Path)
+		WriteError(w, r, http.StatusNotFound, CodeFileMissing, "The music file was moved or deleted since the last scan")
+		return
+	}
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to open music file", "path", song.Path, "err", err)
+		WriteError(w, r, http.StatusInternalServerError, CodeFileUnreadable, "The music file cannot be read")
+		return
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to read music file info", "path", song.Path, "err", err)
+		WriteError(w, r, http.StatusInternalServerError, CodeFileUnreadable, "The music file cannot be read")
+		return
+	}
+
+	logger.DebugContext(r.Context(), "Streaming song", "song_id", songID, "path", song.Path, "bytes", fileInfo.Size())
+
+	h.metrics.activeStreams.Inc()
+	defer h.metrics.activeStreams.Dec()
+
+	// Stream the MP3 file, once it started errors can only cut the response short
+	written, err := writeFileResponse(w, file, fileInfo.Size(), "audio/mpeg")
+	h.metrics.streamedBytes.Add(float64(written))
+	if err != nil {
+		logger.WarnContext(r.Context(), "Stream cut short", "song_id", songID, "err", err)
+	}
+}
+
+// writeOfflineError tells the client why a song cannot be streamed right now
+func writeOfflineError(w http.ResponseWriter, r *http.Request, offlineErr *library.OfflineError) {
+	w.Header().Set("Retry-After", "30")
+	writeErrorResponse(w, r, ErrorResponse{
+		Error:   CodeSongOffline,
+		Message: "The library folder containing this song is not available",
+		Reason:  offlineErr.Reason,
+		Root:    offlineErr.RootPath,
+		Status:  http.StatusServiceUnavailable,
+	})
+}
+
+// handleArtwork serves album artwork for a given song ID
+func (h *Handler) handleArtwork(w http.ResponseWriter, r *http.Request) {
+	songID := mux.Vars(r)["songId"]
+	if songID == "" {
+		WriteError(w, r, http.StatusBadRequest, CodeInvalidRequest, "Missing song ID")
+		return
+	}
+
+	// Check if music library is available
+	if h.musicLibrary == nil {
+		WriteError(w, r, http.StatusServiceUnavailable, CodeLibraryUnavailable, "Music library not available")
+		return
+	}
+
+	// Find the song in the music library
+	song := h.musicLibrary.GetSongByID(songID)
+	if song == nil {
+		WriteError(w, r, http.StatusNotFound, CodeSongNotFound, "Song not found")
+		return
+	}
+
+	// Check if song has artwork
+	artworkData := song.GetArtwork()
+	if len(artworkData) == 0 {
+		WriteError(w, r, http.StatusNotFound, CodeArtworkNotFound, "The song has no artwork")
+		return
+	}
+
+	// Determine content type (most MP3 artwork is JPEG, but could be PNG)
+	contentType := "image/jpeg"
+	if len(artworkData) >= 8 {
+		// Check for PNG signature
+		if artworkData[0] == 0x89 && artworkData[1] == 0x50 && artworkData[2] == 0x4E && artworkData[3] == 0x47 {
+			contentType = "image/png"
+		}
+	}
+
+	// Set headers and serve artwork
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(artworkData)))
+	w.Header().Set("Cache-Control", "public, max-age=3600") // Cache for 1 hour
+
+	if _, err := w.Write(artworkData); err != nil {
+		logger.WarnContext(r.Context(), "Failed to send artwork", "song_id", songID, "err", err)
+	}
+}
+
+// writeFileResponse streams an open file as HTTP response and returns the bytes sent
+func writeFileResponse(w http.ResponseWriter, file io.Reader, size int64, contentType string) (int64, error) {
+	// Set headers
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", size))
+
+	// Stream file content
+	return io.Copy(w, file)
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"bma-core/library"
+	"bma-core/metrics"
+	"github.com/gorilla/mux"
+)
+
+// apiMetrics records the requests, streams and rejected tokens of a Handler
+type apiMetrics struct {
+	requests      *metrics.CounterVec
+	duration      *metrics.HistogramVec
+	streamedBytes *metrics.Counter
+	activeStreams *metrics.Gauge
+	authFailures  *metrics.CounterVec
+}
+
+// newAPIMetrics registers the API metrics, and gauges reading the library at scrape time
+func newAPIMetrics(registry *metrics.Registry, musicLibrary *library.MusicLibrary) *apiMetrics {
+	m := &apiMetrics{
+		requests: registry.CounterVec("bma_http_requests_total",
+			"HTTP requests answered, by method, route and status code", "method", "route", "status"),
+		duration: registry.HistogramVec("bma_http_request_duration_seconds",
+			"Time to answer HTTP requests, by method and route", metrics.DurationBuckets, "method", "route"),
+		streamedBytes: registry.Counter("bma_stream_bytes_total",
+			"Bytes of music files sent to devices"),
+		activeStreams: registry.Gauge("bma_active_streams",
+			"Songs being streamed right now"),
+		authFailures: registry.CounterVec("bma_auth_failures_total",
+			"Requests rejected for a missing, invalid or expired pairing token, by error code", "reason"),
+	}
+
+	// A nil library reads as empty
+	libraryGauge := func(name, help string, value func(ml *library.MusicLibrary) float64) {
+		registry.GaugeFunc(name, help, func() float64 {
+			if musicLibrary == nil {
+				return 0
+			}
+			return value(musicLibrary)
+		})
+	}
+	libraryGauge("bma_library_songs", "Songs in the library", func(ml *library.MusicLibrary) float64 {
+		return float64(ml.GetSongCount())
+	})
+	libraryGauge("bma_library_albums", "Albums in the library", func(ml *library.MusicLibrary) float64 {
+		return float64(ml.GetAlbumCount())
+	})
+	libraryGauge("bma_library_artists", "Artists in the library", func(ml *library.MusicLibrary) float64 {
+		return float64(len(ml.GetArtists()))
+	})
+	libraryGauge("bma_library_scanning", "1 while the library is being scanned", func(ml *library.MusicLibrary) float64 {
+		return metrics.Bool(ml.IsCurrentlyScanning())
+	})
+	libraryGauge("bma_library_scan_duration_seconds", "Duration of the last library scan", func(ml *library.MusicLibrary) float64 {
+		return ml.GetLastScanStats().Duration.Seconds()
+	})
+	libraryGauge("bma_library_scan_files", "Music files found by the last library scan", func(ml *library.MusicLibrary) float64 {
+		return float64(ml.GetLastScanStats().Files)
+	})
+	libraryGauge("bma_library_scan_failed_files", "Music files whose tags the last library scan could not read", func(ml *library.MusicLibrary) float64 {
+		return float64(ml.GetLastScanStats().Failed)
+	})
+	libraryGauge("bma_library_scan_failed_roots", "Library roots the last scan could not read", func(ml *library.MusicLibrary) float64 {
+		failed := 0
+		for _, status := range ml.GetRootStatuses() {
+			if status.Error != "" {
+				failed++
+			}
+		}
+		return float64(failed)
+	})
+
+	return m
+}
+
+// metricsMiddleware counts every request and its duration by route template, e.g. /v1/songs/{songId}
+func (h *Handler) metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		route := routeTemplate(r)
+		h.metrics.requests.With(r.Method, route, strconv.Itoa(recorder.status)).Inc()
+		h.metrics.duration.With(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// routeTemplate returns the path template of the matched route, so song IDs don't create a series
+// each. Requests no route matched are counted as "unmatched".
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return "unmatched"
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pathParamPattern finds the {name} parameters of a route path
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// handleOpenAPI serves the OpenAPI document generated from the routes and response types
+func (h *Handler) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	h.openAPIOnce.Do(func() {
+		data, err := json.MarshalIndent(h.openAPIDocument(), "", "  ")
+		if err != nil {
+			logger.ErrorContext(r.Context(), "Failed to build OpenAPI document", "err", err)
+			return
+		}
+		h.openAPI = data
+	})
+	if h.openAPI == nil {
+		WriteError(w, r, http.StatusInternalServerError, CodeInternal, "OpenAPI document not available")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(h.openAPI)
+}
+
+// openAPIDocument describes every route in OpenAPI 3.0 format
+func (h *Handler) openAPIDocument() map[string]interface{} {
+	schemas := schemaBuilder{schemas: map[string]interface{}{}}
+	schemas.ref(reflect.TypeOf(ErrorResponse{}))
+
+	paths := map[string]interface{}{}
+	for _, rt := range h.routes() {
+		item, ok := paths[rt.path].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			paths[rt.path] = item
+		}
+		item[strings.ToLower(rt.method)] = schemas.operation(rt)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "BMA Music Server API",
+			"version":     Version,
+			"description": "Served by bma-cli and the BMA desktop app. The same routes are available without the /" + APIVersion + " prefix.",
+		},
+		"servers": []interface{}{
+			map[string]interface{}{"url": "/" + APIVersion},
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas.schemas,
+			"securitySchemes": map[string]interface{}{
+				"pairingToken": map[string]interface{}{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "Token from POST /pair or a pairing QR code",
+				},
+			},
+		},
+	}
+}
+
+// schemaBuilder collects the schemas of named types in components/schemas
+type schemaBuilder struct {
+	schemas map[string]interface{}
+}
+
+// operation describes one route
+func (b *schemaBuilder) operation(rt route) map[string]interface{} {
+	operation := map[string]interface{}{
+		"summary":     rt.summary,
+		"operationId": operationID(rt),
+	}
+
+	var parameters []interface{}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(rt.path, -1) {
+		parameters = append(parameters, map[string]interface{}{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+	for _, param := range rt.query {
+		parameters = append(parameters, map[string]interface{}{
+			"name":        param.name,
+			"in":          "query",
+			"required":    param.required,
+			"description": param.description,
+			"schema":      map[string]interface{}{"type": param.kind},
+		})
+	}
+	if parameters != nil {
+		operation["parameters"] = parameters
+	}
+
+	status := rt.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]interface{}{"description": http.StatusText(status)}
+	switch {
+	case rt.response != nil:
+		schema := b.schema(reflect.TypeOf(rt.response))
+		if rt.alternate != nil {
+			schema = map[string]interface{}{
+				"oneOf": []interface{}{schema, b.schema(reflect.TypeOf(rt.alternate))},
+			}
+		}
+		success["content"] = map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+	case rt.contentType != "":
+		success["content"] = map[string]interface{}{rt.contentType: map[string]interface{}{}}
+	}
+	responses := map[string]interface{}{strconv.Itoa(status): success}
+
+	if rt.conditional {
+		responses[strconv.Itoa(http.StatusNotModified)] = map[string]interface{}{
+			"description": "The library has not changed since the ETag sent in If-None-Match",
+		}
+	}
+	if rt.auth {
+		operation["security"] = []interface{}{map[string]interface{}{"pairingToken": []string{}}}
+		responses[strconv.Itoa(http.StatusUnauthorized)] = errorResponse("token_missing, token_invalid or token_expired")
+	}
+	for code, description := range rt.errors {
+		responses[strconv.Itoa(code)] = errorResponse(description)
+	}
+	operation["responses"] = responses
+	return operation
+}
+
+// errorResponse describes an error status, all of them have an ErrorResponse body
+func errorResponse(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]interface{}{"$ref": "#/components/schemas/ErrorResponse"},
+			},
+		},
+	}
+}
+
+// operationID names a route, e.g. getArtistsArtistId for GET /artists/{artistId}
+func operationID(rt route) string {
+	id := strings.ToLower(rt.method)
+	for _, part := range strings.FieldsFunc(rt.path, func(r rune) bool { return !isAlphanumeric(r) }) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+// isAlphanumeric reports whether r is an ASCII letter or digit
+func isAlphanumeric(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schema returns the JSON schema of a Go type, as encoding/json encodes it
+func (b *schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == durationType:
+		return map[string]interface{}{"type": "integer", "format": "int64", "description": "Nanoseconds"}
+	case t.Implements(textMarshalerType):
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := b.schema(t.Elem())
+		if _, isRef := schema["$ref"]; isRef {
+			return map[string]interface{}{"allOf": []interface{}{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.Struct:
+		return b.ref(t)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// ref adds a struct type to components/schemas and returns a reference to it
+func (b *schemaBuilder) ref(t reflect.Type) map[string]interface{} {
+	name := t.Name()
+	if name == "" {
+		return b.object(t)
+	}
+	if _, done := b.schemas[name]; !done {
+		b.schemas[name] = map[string]interface{}{} // Placeholder for recursive types
+		b.schemas[name] = b.object(t)
+	}
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+// object returns the schema of a struct, with the fields encoding/json writes
+func (b *schemaBuilder) object(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	b.addFields(t, properties, &required)
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addFields adds the JSON fields of a struct, including those of embedded structs
+func (b *schemaBuilder) addFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			b.addFields(field.Type, properties, required)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = b.schema(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"time"
+
+	"bma-core/pairing"
+)
+
+// Pair issues a new pairing token for a device to connect with
+func (h *Handler) Pair() (pairing.PairingData, error) {
+	token, expiresAt, err := h.auth.IssueToken(pairingTokenLifetime)
+	if err != nil {
+		return pairing.PairingData{}, err
+	}
+	return pairing.PairingData{
+		ServerURL: h.serverInfo().PreferredURL(),
+		Token:     token,
+		ExpiresAt: expiresAt.Truncate(time.Second), // Whole seconds, as the app parses them
+	}, nil
+}
+
+// PairingData issues a new pairing token and returns the JSON to put into a QR code
+func (h *Handler) PairingData() (string, error) {
+	pairingData, err := h.Pair()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(pairingData)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// handlePair creates a new pairing token for device authentication
+func (h *Handler) handlePair(w http.ResponseWriter, r *http.Request) {
+	pairingData, err := h.Pair()
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to issue pairing token", "err", err)
+		WriteError(w, r, http.StatusInternalServerError, CodeInternal, "Failed to issue a pairing token")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(pairingData); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to encode pairing response", "err", err)
+		return
+	}
+
+	logger.InfoContext(r.Context(), "Pairing token issued", "token", truncateToken(pairingData.Token), "valid_for", pairingTokenLifetime)
+}
+
+// handleDisconnect revokes the token the request was made with
+func (h *Handler) handleDisconnect(w http.ResponseWriter, r *http.Request) {
+	// Extract token from request context (set by requireAuth)
+	token, ok := r.Context().Value(TokenContextKey).(string)
+	if !ok {
+		logger.ErrorContext(r.Context(), "No token in disconnect request context")
+		WriteError(w, r, http.StatusBadRequest, CodeInvalidRequest, "Invalid request")
+		return
+	}
+
+	clientIP, _ := r.Context().Value(ClientIPContextKey).(string)
+	if h.auth.Revoke(token) {
+		logger.InfoContext(r.Context(), "Device disconnected", "token", truncateToken(token), "client", clientIP)
+	} else {
+		logger.WarnContext(r.Context(), "No device found to disconnect", "token", truncateToken(token), "client", clientIP)
+	}
+
+	response := DisconnectResponse{
+		Status:  "disconnected",
+		Message: "Device successfully disconnected",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to encode disconnect response", "err", err)
+	}
+}
+
+// qrPageTemplate is the pairing page served at /qr
+var qrPageTemplate = template.Must(template.New("qr").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.ServerName}} - Pair Device</title>
+    <style>
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
+            max-width: 600px;
+            margin: 0 auto;
+            padding: 20px;
+            background-color: #f5f5f5;
+            text-align: center;
+        }
+        .container {
+            background: white;
+            border-radius: 12px;
+            padding: 30px;
+            box-shadow: 0 2px 10px rgba(0,0,0,0.1);
+        }
+        h1 {
+            color: #333;
+            margin-bottom: 20px;
+        }
+        .qr-container {
+            margin: 30px 0;
+        }
+        .qr-code {
+            border: 1px solid #ddd;
+            border-radius: 8px;
+            max-width: 256px;
+        }
+        .info {
+            background: #e7f3ff;
+            border: 1px solid #b3d7ff;
+            border-radius: 8px;
+            padding: 15px;
+            margin: 20px 0;
+        }
+        .server-info {
+            text-align: left;
+            background: #f8f9fa;
+            border-radius: 8px;
+            padding: 15px;
+            margin: 20px 0;
+        }
+        button {
+            background: #007bff;
+            color: white;
+            border: none;
+            padding: 12px 24px;
+            border-radius: 4px;
+            cursor: pointer;
+            font-size: 16px;
+            margin: 10px;
+        }
+        button:hover {
+            background: #0056b3;
+        }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h1>🎵 {{.ServerName}} - Pair Your Device</h1>
+
+        <div class="info">
+            <strong>Instructions:</strong><br>
+            1. Open the BMA app on your phone<br>
+            2. Tap "Scan QR Code"<br>
+            3. Scan the QR code below
+        </div>
+
+        <div class="qr-container">
+            <img class="qr-code" src="data:image/png;base64,{{.QRCode}}" alt="Pairing QR Code">
+        </div>
+
+        <div class="server-info">
+            <strong>Server Information:</strong><br>
+            <strong>Local URL:</strong> {{.LocalURL}}<br>
+            {{if .TailscaleURL}}<strong>Remote URL:</strong> {{.TailscaleURL}}<br>{{end}}
+            {{if .MusicPath}}<strong>Music Library:</strong> {{.MusicPath}}<br>{{end}}
+            <strong>Songs:</strong> {{.SongCount}} | <strong>Albums:</strong> {{.AlbumCount}}
+        </div>
+
+        <button onclick="window.location.reload()">🔄 Refresh QR Code</button>
+        <button onclick="window.location.href='/info'">📊 Server Info</button>
+    </div>
+</body>
+</html>`))
+
+// handleQRPage serves a page with a pairing QR code, for pairing from a browser on the server
+func (h *Handler) handleQRPage(w http.ResponseWriter, r *http.Request) {
+	pairingData, err := h.PairingData()
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to issue pairing token", "err", err)
+		WriteError(w, r, http.StatusInternalServerError, CodeInternal, "Failed to generate pairing token")
+		return
+	}
+
+	qrCode, err := pairing.GenerateSimpleQR(pairingData, 256)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to generate QR code", "err", err)
+		WriteError(w, r, http.StatusInternalServerError, CodeInternal, "Failed to generate QR code")
+		return
+	}
+
+	info := h.serverInfo()
+	data := struct {
+		ServerName   string
+		QRCode       string
+		LocalURL     string
+		TailscaleURL string
+		MusicPath    string
+		SongCount    int
+		AlbumCount   int
+	}{
+		ServerName:   info.Name,
+		QRCode:       base64.StdEncoding.EncodeToString(qrCode),
+		LocalURL:     info.LocalURL,
+		TailscaleURL: info.TailscaleURL,
+		MusicPath:    info.MusicPath,
+	}
+	if h.musicLibrary != nil {
+		data.SongCount = h.musicLibrary.GetSongCount()
+		data.AlbumCount = h.musicLibrary.GetAlbumCount()
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := qrPageTemplate.Execute(w, data); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to render QR code page", "err", err)
+	}
+}
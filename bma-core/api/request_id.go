@@ -0,0 +1,67 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"bma-core/logging"
+)
+
+// RequestIDHeader carries the ID of a request. Clients may send their own, the response always has one.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength limits the IDs accepted from clients
+const maxRequestIDLength = 64
+
+// RequestIDMiddleware gives every request an ID, from the client's X-Request-ID header or a new one.
+// Handlers logging with the request context tag their log lines with it.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+// RequestID returns the ID RequestIDMiddleware gave a request, or "" if it did not run
+func RequestID(r *http.Request) string {
+	return logging.RequestID(r.Context())
+}
+
+// requestIDOf returns the ID of a request, giving it one if the middleware did not run
+func requestIDOf(w http.ResponseWriter, r *http.Request) string {
+	if id := RequestID(r); id != "" {
+		return id
+	}
+	if id := w.Header().Get(RequestIDHeader); id != "" {
+		return id
+	}
+	id := newRequestID()
+	w.Header().Set(RequestIDHeader, id)
+	return id
+}
+
+// newRequestID returns a random 16 character hex ID
+func newRequestID() string {
+	var id [8]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// validRequestID accepts short IDs of letters, digits and - _ . characters, so they are safe to log
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if !isAlphanumeric(r) && r != '-' && r != '_' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
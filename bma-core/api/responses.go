@@ -0,0 +1,161 @@
+package api
+
+import (
+	"bma-core/library"
+)
+
+// HealthResponse is the response of /health
+type HealthResponse struct {
+	Status string      `json:"status"`
+	Scan   *HealthScan `json:"scan,omitempty"` // Only while the library is being scanned
+}
+
+// HealthScan is the progress of a running scan in /health, without the paths of the files
+type HealthScan struct {
+	Discovered int `json:"discovered"`
+	Processed  int `json:"processed"`
+}
+
+// InfoResponse is the response of /info
+type InfoResponse struct {
+	Server       string      `json:"server"`
+	Version      string      `json:"version"`
+	APIVersion   string      `json:"apiVersion"`   // Path prefix of the versioned routes, e.g. "v1"
+	Capabilities []string    `json:"capabilities"` // Features clients can detect, see Capabilities
+	HasTailscale bool        `json:"hasTailscale"`
+	TailscaleURL string      `json:"tailscaleUrl"`
+	HTTPSPort    int         `json:"httpsPort"` // For compatibility
+	HTTPPort     int         `json:"httpPort"`
+	Protocol     string      `json:"protocol"`
+	Library      LibraryInfo `json:"library"`
+}
+
+// LibraryInfo holds the music library statistics in /info
+type LibraryInfo struct {
+	AlbumCount int                  `json:"albumCount"`
+	SongCount  int                  `json:"songCount"`
+	HasLibrary bool                 `json:"hasLibrary"`
+	MusicPath  string               `json:"musicPath"`
+	Roots      []library.RootStatus `json:"roots"`
+	LastScan   library.ScanStats    `json:"lastScan"`
+	Revision   int64                `json:"revision"`
+}
+
+// DisconnectResponse is the response of /disconnect
+type DisconnectResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// Song is a song in /songs and /library/changes
+type Song struct {
+	ID              string `json:"id"`
+	Filename        string `json:"filename"`
+	Title           string `json:"title"`
+	Artist          string `json:"artist"`
+	Album           string `json:"album"`
+	TrackNumber     int    `json:"trackNumber"`
+	TrackTotal      int    `json:"trackTotal"`
+	DiscNumber      int    `json:"discNumber"`
+	DiscTotal       int    `json:"discTotal"`
+	AlbumArtist     string `json:"albumArtist"`
+	Year            int    `json:"year"`
+	Genre           string `json:"genre"`
+	Composer        string `json:"composer"`
+	IsCompilation   bool   `json:"isCompilation"`
+	ParentDirectory string `json:"parentDirectory"`
+	RootLabel       string `json:"rootLabel"`
+	Offline         bool   `json:"offline"`
+	HasArtwork      bool   `json:"hasArtwork"`
+	SortOrder       *int   `json:"sortOrder,omitempty"` // Position in the /songs list, for Android to maintain
+}
+
+// AlbumSong is a song of an album in /albums
+type AlbumSong struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Artist      string `json:"artist"`
+	TrackNumber int    `json:"trackNumber"`
+	DiscNumber  int    `json:"discNumber"`
+	HasArtwork  bool   `json:"hasArtwork"`
+	Offline     bool   `json:"offline"`
+}
+
+// Album is an album in /albums and /library/changes
+type Album struct {
+	ID            string      `json:"id"`
+	Name          string      `json:"name"`
+	Artist        string      `json:"artist"`
+	TrackCount    int         `json:"trackCount"`
+	DiscCount     int         `json:"discCount"`
+	Year          int         `json:"year"`
+	Genre         string      `json:"genre"`
+	IsCompilation bool        `json:"isCompilation"`
+	Songs         []AlbumSong `json:"songs"`
+}
+
+// SongPage is the response of /songs when a limit or cursor is given
+type SongPage struct {
+	Items      []Song `json:"items"`
+	Total      int    `json:"total"`                // Songs matching the filters, across all pages
+	NextCursor string `json:"nextCursor,omitempty"` // Empty on the last page
+}
+
+// AlbumPage is the response of /albums when a limit or cursor is given
+type AlbumPage struct {
+	Items      []Album `json:"items"`
+	Total      int     `json:"total"`                // Albums matching the filters, across all pages
+	NextCursor string  `json:"nextCursor,omitempty"` // Empty on the last page
+}
+
+// newSong converts a song for /songs
+func newSong(song *library.Song) Song {
+	return Song{
+		ID:              song.ID.String(),
+		Filename:        song.Filename,
+		Title:           song.Title,
+		Artist:          song.Artist,
+		Album:           song.Album,
+		TrackNumber:     song.TrackNumber,
+		TrackTotal:      song.TrackTotal,
+		DiscNumber:      song.Disc(),
+		DiscTotal:       song.DiscTotal,
+		AlbumArtist:     song.AlbumArtistOrArtist(),
+		Year:            song.Year,
+		Genre:           song.Genre,
+		Composer:        song.Composer,
+		IsCompilation:   song.Compilation,
+		ParentDirectory: song.ParentDirectory,
+		RootLabel:       song.RootLabel,
+		Offline:         song.Offline,
+		HasArtwork:      song.HasArtwork(),
+	}
+}
+
+// newAlbum converts an album and its songs for /albums
+func newAlbum(album *library.Album) Album {
+	songs := make([]AlbumSong, len(album.Songs))
+	for i, song := range album.Songs {
+		songs[i] = AlbumSong{
+			ID:          song.ID.String(),
+			Title:       song.Title,
+			Artist:      song.Artist,
+			TrackNumber: song.TrackNumber,
+			DiscNumber:  song.Disc(),
+			HasArtwork:  song.HasArtwork(),
+			Offline:     song.Offline,
+		}
+	}
+
+	return Album{
+		ID:            album.ID.String(),
+		Name:          album.Name,
+		Artist:        album.Artist,
+		TrackCount:    album.TrackCount(),
+		DiscCount:     album.DiscCount,
+		Year:          album.Year,
+		Genre:         album.Genre,
+		IsCompilation: album.IsCompilation,
+		Songs:         songs,
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"net/http"
+
+	"bma-core/library"
+	"bma-core/pairing"
+)
+
+// route is one API endpoint. Register serves it and the OpenAPI document describes it.
+type route struct {
+	method      string
+	path        string // Without the /v1 prefix
+	handler     http.HandlerFunc
+	auth        bool // Requires a Bearer pairing token
+	summary     string
+	query       []queryParam
+	status      int            // Success status code, 0 for 200
+	response    interface{}    // Zero value of the JSON response, nil if contentType is set
+	alternate   interface{}    // Another JSON response shape, e.g. the paged list of /songs
+	contentType string         // Content type of non-JSON responses
+	conditional bool           // Answers If-None-Match with 304 Not Modified
+	errors      map[int]string // Other status codes and what they mean
+}
+
+// queryParam is a query parameter of a route
+type queryParam struct {
+	name        string
+	kind        string // OpenAPI type: string or integer
+	required    bool
+	description string
+}
+
+// listQuery are the query parameters of /songs and /albums
+var listQuery = []queryParam{
+	{name: "album", kind: "string", description: "Only items of this album, ignoring case and accents"},
+	{name: "artist", kind: "string", description: "Only items crediting this artist or album artist"},
+	{name: "genre", kind: "string", description: "Only items of this genre, ignoring case and accents"},
+	{name: "year", kind: "string", description: "A year (1999) or range (1990-1999)"},
+	{name: "folder", kind: "string", description: "Only items in this folder or its subfolders, absolute or relative to the library root"},
+	{name: "sort", kind: "string", description: "Sort key"},
+	{name: "order", kind: "string", description: "asc (default) or desc"},
+	{name: "fields", kind: "string", description: "Comma separated fields to return, id is always included"},
+	{name: "limit", kind: "integer", description: "Page size, returns a page object instead of an array"},
+	{name: "cursor", kind: "string", description: "nextCursor of the previous page"},
+}
+
+// routes lists every API endpoint
+func (h *Handler) routes() []route {
+	return []route{
+		// Public endpoints
+		{method: "GET", path: "/health", handler: h.handleHealth, summary: "Server health check",
+			response: HealthResponse{}},
+		{method: "GET", path: "/info", handler: h.handleInfo, summary: "Server, API and library information",
+			response: InfoResponse{}, conditional: true},
+		{method: "POST", path: "/pair", handler: h.handlePair, summary: "Create a pairing token",
+			response: pairing.PairingData{}},
+		{method: "GET", path: "/qr", handler: h.handleQRPage, summary: "Web page with a pairing QR code",
+			contentType: "text/html"},
+		{method: "GET", path: "/openapi.json", handler: h.handleOpenAPI, summary: "This OpenAPI document",
+			contentType: "application/json"},
+
+		// Authenticated endpoints (require a Bearer pairing token)
+		{method: "POST", path: "/disconnect", handler: h.handleDisconnect, auth: true,
+			summary: "Revoke the token of the calling device", response: DisconnectResponse{}},
+		{method: "GET", path: "/songs", handler: h.handleSongs, auth: true,
+			summary: "List songs, optionally filtered, sorted and paged", query: listQuery,
+			response: []Song{}, alternate: SongPage{}, conditional: true,
+			errors: map[int]string{
+				http.StatusBadRequest: "invalid_request",
+				http.StatusGone:       "cursor_expired: the library changed since the first page, start again without a cursor",
+			}},
+		{method: "GET", path: "/albums", handler: h.handleAlbums, auth: true,
+			summary: "List albums with their songs, optionally filtered, sorted and paged", query: listQuery,
+			response: []Album{}, alternate: AlbumPage{}, conditional: true,
+			errors: map[int]string{
+				http.StatusBadRequest: "invalid_request",
+				http.StatusGone:       "cursor_expired: the library changed since the first page, start again without a cursor",
+			}},
+		{method: "GET", path: "/artists", handler: h.handleArtists, auth: true,
+			summary: "List artists sorted by sort name", response: []ArtistSummary{}, conditional: true},
+		{method: "GET", path: "/artists/{artistId}", handler: h.handleArtist, auth: true,
+			summary:  "An artist's albums, and the tracks they appear on elsewhere",
+			response: ArtistDetail{}, conditional: true,
+			errors: map[int]string{http.StatusNotFound: "artist_not_found"}},
+		{method: "GET", path: "/search", handler: h.handleSearch, auth: true,
+			summary: "Search songs, albums and artists",
+			query: []queryParam{
+				{name: "q", kind: "string", required: true, description: "Search terms, accents and case are ignored"},
+				{name: "limit", kind: "integer", description: "Results per kind, 20 by default"},
+			},
+			response: SearchResponse{}, conditional: true,
+			errors: map[int]string{http.StatusBadRequest: "invalid_request: missing query or invalid limit"}},
+		{method: "GET", path: "/stream/{songId}", handler: h.handleStream, auth: true,
+			summary: "Stream the audio file of a song", contentType: "audio/mpeg",
+			errors: map[int]string{
+				http.StatusNotFound:            "song_not_found, or file_missing if the file was deleted since the last scan",
+				http.StatusInternalServerError: "file_unreadable",
+				http.StatusServiceUnavailable:  "song_offline with Retry-After, or library_unavailable",
+			}},
+		{method: "GET", path: "/artwork/{songId}", handler: h.handleArtwork, auth: true,
+			summary: "Embedded album artwork of a song", contentType: "image/jpeg",
+			errors: map[int]string{http.StatusNotFound: "song_not_found or artwork_not_found"}},
+
+		// Library scan status and control
+		{method: "GET", path: "/library/scan", handler: h.handleScanStatus, auth: true,
+			summary: "Progress of the running or last library scan", response: ScanStatus{}},
+		{method: "POST", path: "/library/scan", handler: h.handleScanStart, auth: true,
+			summary: "Start a rescan in the background", status: http.StatusAccepted, response: ScanStatus{},
+			errors: map[int]string{http.StatusConflict: "scan_in_progress, details holds the ScanStatus"}},
+		{method: "DELETE", path: "/library/scan", handler: h.handleScanCancel, auth: true,
+			summary: "Cancel the running scan and keep the previous library", response: ScanStatus{},
+			errors: map[int]string{http.StatusConflict: "no_scan_running, details holds the ScanStatus"}},
+		{method: "GET", path: "/library/changes", handler: h.handleLibraryChanges, auth: true,
+			summary: "Songs and albums added, updated or removed since a library revision",
+			query: []queryParam{
+				{name: "since", kind: "integer", required: true, description: "Library revision the client has"},
+			},
+			response: ChangesResponse{},
+			errors:   map[int]string{http.StatusBadRequest: "invalid_request: missing or invalid since revision"}},
+		{method: "GET", path: "/library/health", handler: h.handleLibraryHealth, auth: true,
+			summary: "Problems found by the last scan", response: library.HealthReport{},
+			errors: map[int]string{http.StatusServiceUnavailable: "library_not_scanned or library_unavailable"}},
+	}
+}
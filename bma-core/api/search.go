@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"bma-core/library"
+)
+
+// maxSearchLimit caps the limit parameter of /search
+const maxSearchLimit = 100
+
+// SearchSong is a song in /search results
+type SearchSong struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Artist      string `json:"artist"`
+	Album       string `json:"album"`
+	TrackNumber int    `json:"trackNumber"`
+	Year        int    `json:"year,omitempty"`
+	HasArtwork  bool   `json:"hasArtwork"`
+	Offline     bool   `json:"offline"`
+}
+
+// SearchAlbum is an album in /search results
+type SearchAlbum struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Artist        string `json:"artist"`
+	Year          int    `json:"year,omitempty"`
+	TrackCount    int    `json:"trackCount"`
+	IsCompilation bool   `json:"isCompilation"`
+	ArtworkSongID string `json:"artworkSongId,omitempty"`
+}
+
+// SearchResponse is the response of /search, each list ordered best match first
+type SearchResponse struct {
+	Query   string          `json:"query"`
+	Songs   []SearchSong    `json:"songs"`
+	Albums  []SearchAlbum   `json:"albums"`
+	Artists []ArtistSummary `json:"artists"`
+}
+
+// handleSearch searches songs, albums and artists by the q parameter, at most limit of each
+func (h *Handler) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		WriteError(w, r, http.StatusBadRequest, CodeInvalidRequest, "Missing search query (q)")
+		return
+	}
+
+	limit := library.DefaultSearchLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			WriteError(w, r, http.StatusBadRequest, CodeInvalidRequest, "Invalid limit")
+			return
+		}
+		limit = parsed
+		if limit > maxSearchLimit {
+			limit = maxSearchLimit
+		}
+	}
+
+	response := SearchResponse{
+		Query:   query,
+		Songs:   []SearchSong{},
+		Albums:  []SearchAlbum{},
+		Artists: []ArtistSummary{},
+	}
+	if h.musicLibrary != nil {
+		if libraryNotModified(w, r, h.musicLibrary, "") {
+			return
+		}
+		results := h.musicLibrary.Search(query, limit)
+		for _, song := range results.Songs {
+			response.Songs = append(response.Songs, SearchSong{
+				ID:          song.ID.String(),
+				Title:       song.Title,
+				Artist:      song.Artist,
+				Album:       song.Album,
+				TrackNumber: song.TrackNumber,
+				Year:        song.Year,
+				HasArtwork:  song.HasArtwork(),
+				Offline:     song.Offline,
+			})
+		}
+		for _, album := range results.Albums {
+			response.Albums = append(response.Albums, SearchAlbum{
+				ID:            album.ID.String(),
+				Name:          album.Name,
+				Artist:        album.Artist,
+				Year:          album.Year,
+				TrackCount:    album.TrackCount(),
+				IsCompilation: album.IsCompilation,
+				ArtworkSongID: albumArtworkSongID(album),
+			})
+		}
+		for _, artist := range results.Artists {
+			response.Artists = append(response.Artists, newArtistSummary(artist))
+		}
+	}
+
+	logger.DebugContext(r.Context(), "Search done", "query", query,
+		"songs", len(response.Songs), "albums", len(response.Albums), "artists", len(response.Artists))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to encode search results", "err", err)
+	}
+}
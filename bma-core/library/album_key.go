@@ -0,0 +1,178 @@
+package library
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// songIDNamespace and albumIDNamespace derive song IDs from paths and album IDs from album keys,
+// so IDs stay the same across scans and restarts
+var (
+	songIDNamespace  = uuid.MustParse("0f3b8e52-7a1c-4a4e-9d36-2b8f6c1d5e70")
+	albumIDNamespace = uuid.MustParse("c4d2a7e9-3b56-4f18-8a0e-71f9d3b6c2a4")
+)
+
+// songID returns the ID of the song at path
+func songID(path string) uuid.UUID {
+	return uuid.NewSHA1(songIDNamespace, []byte(path))
+}
+
+// albumName returns the album a song is grouped under
+func albumName(song *Song) string {
+	name := song.Album
+	if name == "" {
+		name = song.InferredAlbum()
+	}
+	if name == "" {
+		name = "Unknown Album"
+	}
+	return name
+}
+
+// albumArtistKey returns the lowercase album artist used to group a song, shared by all songs of a compilation
+func albumArtistKey(song *Song) string {
+	if song.Compilation {
+		return strings.ToLower(VariousArtists)
+	}
+	return strings.ToLower(song.AlbumArtist)
+}
+
+// albumKey identifies the album of a song by album artist and album name, ignoring case.
+// Only the album artist tag is used, so albums without it still form one album.
+func albumKey(song *Song) string {
+	return albumArtistKey(song) + "\x00" + strings.ToLower(albumName(song))
+}
+
+// compareAlbumOrder orders two songs by album name, album artist, release year and disc.
+// It returns 0 if both belong on the same disc of the same album. years holds the release
+// year of every song, see releaseYears; 0 is compared like any other year, so the order is
+// transitive and the songs of one release stay together.
+func compareAlbumOrder(song1, song2 *Song, years map[*Song]int) int {
+	if c := strings.Compare(strings.ToLower(albumName(song1)), strings.ToLower(albumName(song2))); c != 0 {
+		return c
+	}
+	if c := strings.Compare(albumArtistKey(song1), albumArtistKey(song2)); c != 0 {
+		return c
+	}
+	if c := years[song1] - years[song2]; c != 0 {
+		return c
+	}
+	return song1.Disc() - song2.Disc()
+}
+
+// releaseYears returns the year of the release each song is grouped under by splitByYear:
+// its own year, or the most common year of its album if it has none
+func releaseYears(songs []*Song) map[*Song]int {
+	byAlbum := make(map[string][]*Song)
+	for _, song := range songs {
+		key := albumKey(song)
+		byAlbum[key] = append(byAlbum[key], song)
+	}
+
+	years := make(map[*Song]int, len(songs))
+	for _, albumSongs := range byAlbum {
+		common := commonYear(albumSongs)
+		for _, song := range albumSongs {
+			year := song.Year
+			if year == 0 {
+				year = common
+			}
+			years[song] = year
+		}
+	}
+	return years
+}
+
+// commonYear returns the year most songs have, the earliest on a tie, or 0 if none has a year
+func commonYear(songs []*Song) int {
+	yearCounts := make(map[int]int)
+	for _, song := range songs {
+		if song.Year > 0 {
+			yearCounts[song.Year]++
+		}
+	}
+
+	common := 0
+	for year, count := range yearCounts {
+		if count > yearCounts[common] || (count == yearCounts[common] && year < common) {
+			common = year
+		}
+	}
+	return common
+}
+
+// splitByYear separates releases that share album artist and name, such as two live albums,
+// by year. Songs without a year join the most common year.
+func splitByYear(songs []*Song) [][]*Song {
+	common := commonYear(songs)
+
+	var years []int
+	byYear := make(map[int][]*Song)
+	for _, song := range songs {
+		year := song.Year
+		if year == 0 {
+			year = common
+		}
+		if _, ok := byYear[year]; !ok {
+			years = append(years, year)
+		}
+		byYear[year] = append(byYear[year], song)
+	}
+
+	sort.Ints(years)
+	groups := make([][]*Song, 0, len(years))
+	for _, year := range years {
+		groups = append(groups, byYear[year])
+	}
+	return groups
+}
+
+// newAlbum builds an album from songs that are already in track order
+func newAlbum(songs []*Song) *Album {
+	first := songs[0]
+	album := &Album{
+		Name:   albumName(first),
+		Songs:  songs,
+		Artist: first.AlbumArtist,
+	}
+
+	if first.Compilation {
+		album.Artist = VariousArtists
+		album.IsCompilation = true
+	}
+
+	// Without an album artist tag use the first song's artist, like before album artist support
+	if album.Artist == "" {
+		album.Artist = first.Artist
+	}
+	if album.Artist == "" {
+		album.Artist = first.InferredArtist()
+	}
+
+	genreCounts := make(map[string]int)
+	for _, song := range songs {
+		if song.Year > 0 && (album.Year == 0 || song.Year < album.Year) {
+			album.Year = song.Year
+		}
+		if song.Disc() > album.DiscCount {
+			album.DiscCount = song.Disc()
+		}
+		if song.DiscTotal > album.DiscCount {
+			album.DiscCount = song.DiscTotal
+		}
+		if song.Genre != "" {
+			genreCounts[song.Genre]++
+		}
+	}
+	for genre, count := range genreCounts {
+		if count > genreCounts[album.Genre] || (count == genreCounts[album.Genre] && genre < album.Genre) {
+			album.Genre = genre
+		}
+	}
+
+	album.ID = uuid.NewSHA1(albumIDNamespace, []byte(albumKey(first)+"\x00"+strconv.Itoa(album.Year)))
+	return album
+}
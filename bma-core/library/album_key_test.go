@@ -0,0 +1,151 @@
+package library
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// testSong creates a song of an album, named after its year and track for test failures
+func testSong(album string, year, disc, track int) *Song {
+	return &Song{
+		Title:       fmt.Sprintf("%s %d-%d-%d", album, year, disc, track),
+		Album:       album,
+		AlbumArtist: "Band",
+		Year:        year,
+		DiscNumber:  disc,
+		TrackNumber: track,
+	}
+}
+
+func TestCompareAlbumOrder(t *testing.T) {
+	songs := []*Song{
+		testSong("Live", 2001, 1, 1),
+		testSong("Live", 0, 1, 2),
+		testSong("Live", 1999, 1, 1),
+		testSong("Live", 1999, 2, 1),
+		testSong("Studio", 0, 1, 1),
+		{Title: "Other", Album: "Live", AlbumArtist: "Other Band", Year: 2001},
+	}
+	years := releaseYears(songs)
+
+	tests := []struct {
+		name  string
+		song1 *Song
+		song2 *Song
+		want  int // Sign of the result
+	}{
+		{"album name first", songs[0], songs[4], -1},
+		{"album artist before year", songs[5], songs[2], 1},
+		{"earlier release first", songs[2], songs[0], -1},
+		{"undated song joins the most common year", songs[1], songs[2], 0},
+		{"undated song sorts with its release", songs[1], songs[0], -1},
+		{"disc within a release", songs[3], songs[2], 1},
+		{"same disc", songs[2], songs[2], 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareAlbumOrder(tt.song1, tt.song2, years)
+			if sign(got) != tt.want {
+				t.Errorf("compareAlbumOrder(%q, %q) = %d, want sign %d", tt.song1.Title, tt.song2.Title, got, tt.want)
+			}
+			if reverse := compareAlbumOrder(tt.song2, tt.song1, years); sign(reverse) != -tt.want {
+				t.Errorf("compareAlbumOrder(%q, %q) = %d, want sign %d", tt.song2.Title, tt.song1.Title, reverse, -tt.want)
+			}
+		})
+	}
+
+	// Every triple must be transitive, or sort.Slice may split releases
+	for _, a := range songs {
+		for _, b := range songs {
+			for _, c := range songs {
+				if compareAlbumOrder(a, b, years) < 0 && compareAlbumOrder(b, c, years) < 0 && compareAlbumOrder(a, c, years) >= 0 {
+					t.Errorf("not transitive: %q < %q < %q but not %q < %q", a.Title, b.Title, c.Title, a.Title, c.Title)
+				}
+			}
+		}
+	}
+}
+
+func TestOrganizeMixedYears(t *testing.T) {
+	// Two releases of the same album, plus a song without a year; 2001 and 1999 tie,
+	// so the undated song joins the earlier release
+	songs := []*Song{
+		testSong("Live", 2001, 1, 2),
+		testSong("Live", 0, 1, 3),
+		testSong("Live", 1999, 1, 2),
+		testSong("Live", 2001, 1, 1),
+		testSong("Live", 1999, 1, 1),
+	}
+
+	ml := NewMusicLibrary()
+	for i := 0; i < 20; i++ {
+		// Shuffle the input order to catch comparator problems that depend on it
+		input := append(songs[i%len(songs):len(songs):len(songs)], songs[:i%len(songs)]...)
+		albums := ml.organizeIntoAlbums(ml.organizeAndSortSongs(input))
+
+		var got [][]string
+		for _, album := range albums {
+			var titles []string
+			for _, song := range album.Songs {
+				titles = append(titles, song.Title)
+			}
+			got = append(got, titles)
+		}
+		want := [][]string{
+			{"Live 1999-1-1", "Live 1999-1-2", "Live 0-1-3"},
+			{"Live 2001-1-1", "Live 2001-1-2"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("albums = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestSplitByYear(t *testing.T) {
+	tests := []struct {
+		name  string
+		years []int
+		want  [][]int
+	}{
+		{"one year", []int{2001, 2001}, [][]int{{2001, 2001}}},
+		{"no years", []int{0, 0}, [][]int{{0, 0}}},
+		{"undated songs join the only year", []int{0, 2001, 0}, [][]int{{0, 2001, 0}}},
+		{"releases by year", []int{2001, 1999, 2001}, [][]int{{1999}, {2001, 2001}}},
+		{"undated songs join the most common year", []int{1999, 0, 2001, 2001}, [][]int{{1999}, {0, 2001, 2001}}},
+		{"earliest year on a tie", []int{2001, 0, 1999}, [][]int{{0, 1999}, {2001}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var songs []*Song
+			for i, year := range tt.years {
+				songs = append(songs, testSong("Live", year, 1, i+1))
+			}
+
+			var got [][]int
+			for _, group := range splitByYear(songs) {
+				var years []int
+				for _, song := range group {
+					years = append(years, song.Year)
+				}
+				got = append(got, years)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitByYear(%v) = %v, want %v", tt.years, got, tt.want)
+			}
+		})
+	}
+}
+
+// sign returns -1, 0 or 1
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,191 @@
+package library
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// artistIDNamespace derives artist IDs from names, so an artist keeps its ID across scans
+var artistIDNamespace = uuid.MustParse("6b2c43d8-65ab-4d95-a5cc-ce2d49607e6e")
+
+// featuringPattern matches "(feat. B)" and "[ft. B]" credits written in brackets
+var featuringPattern = regexp.MustCompile(`(?i)\s*[(\[]\s*(?:feat\.?|ft\.?|featuring)\s+([^)\]]+)[)\]]`)
+
+// creditSeparator splits "A feat. B", "A ft. B", "A featuring B", "A; B" and "A / B"
+var creditSeparator = regexp.MustCompile(`(?i)\s+(?:feat\.?|ft\.?|featuring)\s+|\s*;\s*|\s+/\s+`)
+
+// Artist is everyone credited on a song or as an album artist, with "The X" and "X, The" folded together
+type Artist struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`     // Display name, such as "The Beatles"
+	SortName string    `json:"sortName"` // Name without a leading article, such as "Beatles, The"
+	Albums   []*Album  `json:"albums"`   // Albums with the artist as album artist
+	Songs    []*Song   `json:"songs"`    // Songs crediting the artist, including features and compilation tracks
+}
+
+// AlbumCount returns the number of albums of the artist
+func (a *Artist) AlbumCount() int {
+	return len(a.Albums)
+}
+
+// TrackCount returns the number of songs crediting the artist
+func (a *Artist) TrackCount() int {
+	return len(a.Songs)
+}
+
+// Tracks returns the songs crediting the artist that are not on one of its albums, such as features
+func (a *Artist) Tracks() []*Song {
+	onAlbums := make(map[*Song]bool)
+	for _, album := range a.Albums {
+		for _, song := range album.Songs {
+			onAlbums[song] = true
+		}
+	}
+
+	var tracks []*Song
+	for _, song := range a.Songs {
+		if !onAlbums[song] {
+			tracks = append(tracks, song)
+		}
+	}
+	return tracks
+}
+
+// ArtworkSong returns a song whose artwork represents the artist, preferring its albums, or nil
+func (a *Artist) ArtworkSong() *Song {
+	for _, album := range a.Albums {
+		for _, song := range album.Songs {
+			if song.HasArtwork() {
+				return song
+			}
+		}
+	}
+	for _, song := range a.Songs {
+		if song.HasArtwork() {
+			return song
+		}
+	}
+	return nil
+}
+
+// SplitArtistCredit splits a multi-artist credit such as "A feat. B" or "A; B" into artist names.
+// "&" and "," are left alone since they are part of many band names.
+func SplitArtistCredit(credit string) []string {
+	credit = featuringPattern.ReplaceAllString(credit, " feat. $1")
+
+	var names []string
+	for _, name := range creditSeparator.Split(credit, -1) {
+		if name = strings.Join(strings.Fields(name), " "); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// artistNames returns the display and sort name of an artist, moving a leading "The" in either spelling
+func artistNames(name string) (display, sortName string) {
+	name = strings.Join(strings.Fields(name), " ")
+	lower := strings.ToLower(name)
+
+	switch {
+	case strings.HasSuffix(lower, ", the") && len(name) > len(", the"):
+		base := name[:len(name)-len(", the")]
+		return "The " + base, base + ", The"
+	case strings.HasPrefix(lower, "the ") && len(name) > len("the "):
+		base := name[len("the "):]
+		return name, base + ", The"
+	default:
+		return name, name
+	}
+}
+
+// artistIndexEntry collects an artist and the spellings of its name while the index is built
+type artistIndexEntry struct {
+	artist    *Artist
+	spellings map[string]int
+	songs     map[*Song]bool
+}
+
+// buildArtistIndex builds the artists of a library from song credits and album artists, sorted by sort name
+func buildArtistIndex(songs []*Song, albums []*Album) []*Artist {
+	entries := make(map[string]*artistIndexEntry)
+	var order []string
+
+	entry := func(credit string) *artistIndexEntry {
+		display, sortName := artistNames(credit)
+		key := strings.ToLower(sortName)
+		e, ok := entries[key]
+		if !ok {
+			e = &artistIndexEntry{
+				artist: &Artist{
+					ID:       uuid.NewSHA1(artistIDNamespace, []byte(key)),
+					SortName: sortName,
+					Albums:   []*Album{},
+					Songs:    []*Song{},
+				},
+				spellings: make(map[string]int),
+				songs:     make(map[*Song]bool),
+			}
+			entries[key] = e
+			order = append(order, key)
+		}
+		e.spellings[display]++
+		return e
+	}
+
+	for _, song := range songs {
+		for _, name := range SplitArtistCredit(song.Artist) {
+			if isVariousArtists(name) {
+				continue
+			}
+			if e := entry(name); !e.songs[song] {
+				e.songs[song] = true
+				e.artist.Songs = append(e.artist.Songs, song)
+			}
+		}
+	}
+
+	for _, album := range albums {
+		if album.IsCompilation {
+			continue
+		}
+		names := SplitArtistCredit(album.Artist)
+		if len(names) > 1 && album.Songs[0].AlbumArtist == "" {
+			names = names[:1] // Album artist taken from a track credit, the guests get the track only
+		}
+		for _, name := range names {
+			if isVariousArtists(name) {
+				continue
+			}
+			e := entry(name)
+			e.artist.Albums = append(e.artist.Albums, album)
+			for _, song := range album.Songs {
+				if !e.songs[song] {
+					e.songs[song] = true
+					e.artist.Songs = append(e.artist.Songs, song)
+				}
+			}
+		}
+	}
+
+	artists := make([]*Artist, 0, len(order))
+	for _, key := range order {
+		e := entries[key]
+		// Show the most used spelling, alphabetically first on a tie
+		best := 0
+		for display, count := range e.spellings {
+			if count > best || (count == best && display < e.artist.Name) {
+				e.artist.Name, best = display, count
+			}
+		}
+		artists = append(artists, e.artist)
+	}
+
+	sort.SliceStable(artists, func(i, j int) bool {
+		return strings.ToLower(artists[i].SortName) < strings.ToLower(artists[j].SortName)
+	})
+	return artists
+}
@@ -0,0 +1,254 @@
+package library
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// maxChangeLogEntries limits the change log, clients further behind must resync
+const maxChangeLogEntries = 20000
+
+// ChangeOp is what happened to a song or album
+type ChangeOp string
+
+const (
+	ChangeAdded   ChangeOp = "added"
+	ChangeUpdated ChangeOp = "updated"
+	ChangeRemoved ChangeOp = "removed"
+)
+
+// changeEntry records one change to a song or album in the change log
+type changeEntry struct {
+	revision int64
+	album    bool // Album change, song change otherwise
+	id       string
+	op       ChangeOp
+}
+
+// LibraryChanges lists what changed between two library revisions
+type LibraryChanges struct {
+	Revision      int64 // Current revision, pass it as since on the next request
+	FullResync    bool  // The change log no longer reaches back to since, fetch everything again
+	AddedSongs    []*Song
+	UpdatedSongs  []*Song
+	RemovedSongs  []string
+	AddedAlbums   []*Album
+	UpdatedAlbums []*Album
+	RemovedAlbums []string
+}
+
+// initialRevision starts revisions at the current time in milliseconds, so they keep increasing across restarts
+// and a revision from before a restart is older than the change log
+func initialRevision() int64 {
+	return time.Now().UnixMilli()
+}
+
+// GetRevision returns the current library revision (thread-safe)
+func (ml *MusicLibrary) GetRevision() int64 {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+	return ml.revision
+}
+
+// GetSongsAtRevision returns a copy of all songs and the revision they belong to (thread-safe)
+func (ml *MusicLibrary) GetSongsAtRevision() ([]*Song, int64) {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+
+	songs := make([]*Song, len(ml.Songs))
+	copy(songs, ml.Songs)
+	return songs, ml.revision
+}
+
+// GetAlbumsAtRevision returns a copy of all albums and the revision they belong to (thread-safe)
+func (ml *MusicLibrary) GetAlbumsAtRevision() ([]*Album, int64) {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+
+	albums := make([]*Album, len(ml.Albums))
+	copy(albums, ml.Albums)
+	return albums, ml.revision
+}
+
+// songFingerprint summarizes the fields of a song clients display, to notice updates
+func songFingerprint(song *Song) uint64 {
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%s|%s|%s|%s|%s|%d/%d|%d/%d|%d|%s|%s|%t|%s|%t|%d",
+		song.Title, song.Artist, song.Album, song.AlbumArtist, song.Filename,
+		song.TrackNumber, song.TrackTotal, song.DiscNumber, song.DiscTotal, song.Year,
+		song.Genre, song.Composer, song.Compilation, song.RootLabel, song.Offline, len(song.ArtworkData))
+	return hash.Sum64()
+}
+
+// albumFingerprint summarizes an album and the order of its songs, to notice updates
+func albumFingerprint(album *Album) uint64 {
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%s|%s|%d|%s|%d|%t|", album.Name, album.Artist, album.Year, album.Genre, album.DiscCount, album.IsCompilation)
+	for _, song := range album.Songs {
+		fmt.Fprintf(hash, "%s,%x;", song.ID, songFingerprint(song))
+	}
+	return hash.Sum64()
+}
+
+// diffLibrary compares the songs and albums of two scans
+func diffLibrary(oldSongs, newSongs []*Song, oldAlbums, newAlbums []*Album) []changeEntry {
+	var changes []changeEntry
+
+	oldSongPrints := make(map[string]uint64, len(oldSongs))
+	for _, song := range oldSongs {
+		oldSongPrints[song.ID.String()] = songFingerprint(song)
+	}
+	for _, song := range newSongs {
+		id := song.ID.String()
+		if fingerprint, ok := oldSongPrints[id]; !ok {
+			changes = append(changes, changeEntry{id: id, op: ChangeAdded})
+		} else if fingerprint != songFingerprint(song) {
+			changes = append(changes, changeEntry{id: id, op: ChangeUpdated})
+		}
+		delete(oldSongPrints, id)
+	}
+	for _, song := range oldSongs {
+		if _, removed := oldSongPrints[song.ID.String()]; removed {
+			changes = append(changes, changeEntry{id: song.ID.String(), op: ChangeRemoved})
+		}
+	}
+
+	oldAlbumPrints := make(map[string]uint64, len(oldAlbums))
+	for _, album := range oldAlbums {
+		oldAlbumPrints[album.ID.String()] = albumFingerprint(album)
+	}
+	for _, album := range newAlbums {
+		id := album.ID.String()
+		if fingerprint, ok := oldAlbumPrints[id]; !ok {
+			changes = append(changes, changeEntry{album: true, id: id, op: ChangeAdded})
+		} else if fingerprint != albumFingerprint(album) {
+			changes = append(changes, changeEntry{album: true, id: id, op: ChangeUpdated})
+		}
+		delete(oldAlbumPrints, id)
+	}
+	for _, album := range oldAlbums {
+		if _, removed := oldAlbumPrints[album.ID.String()]; removed {
+			changes = append(changes, changeEntry{album: true, id: album.ID.String(), op: ChangeRemoved})
+		}
+	}
+	return changes
+}
+
+// recordChanges compares the new songs and albums with the current ones and bumps the revision
+// if anything changed. The first scan only sets the revision, clients fetch that library in full.
+// Must be called with ml.mutex held for writing, before the new songs and albums are stored.
+func (ml *MusicLibrary) recordChanges(songs []*Song, albums []*Album) {
+	if !ml.scanned {
+		ml.scanned = true
+		ml.revision++
+		ml.changeLogStart = ml.revision
+		return
+	}
+
+	changes := diffLibrary(ml.Songs, songs, ml.Albums, albums)
+	if len(changes) == 0 {
+		return
+	}
+
+	ml.revision++
+	for i := range changes {
+		changes[i].revision = ml.revision
+	}
+	ml.changeLog = append(ml.changeLog, changes...)
+
+	// Drop whole revisions from the front, so a revision is never half in the log
+	if len(ml.changeLog) > maxChangeLogEntries {
+		cut := len(ml.changeLog) - maxChangeLogEntries
+		for cut < len(ml.changeLog) && ml.changeLog[cut].revision == ml.changeLog[cut-1].revision {
+			cut++
+		}
+		ml.changeLogStart = ml.changeLog[cut-1].revision
+		ml.changeLog = append([]changeEntry(nil), ml.changeLog[cut:]...)
+	}
+	logger.Info("Library revision", "revision", ml.revision, "changes", len(changes))
+}
+
+// ChangesSince returns the songs and albums added, updated or removed after revision since (thread-safe).
+// Several changes to one song are merged, so a song added and then removed is not listed.
+func (ml *MusicLibrary) ChangesSince(since int64) LibraryChanges {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+
+	changes := LibraryChanges{
+		Revision:      ml.revision,
+		AddedSongs:    []*Song{},
+		UpdatedSongs:  []*Song{},
+		RemovedSongs:  []string{},
+		AddedAlbums:   []*Album{},
+		UpdatedAlbums: []*Album{},
+		RemovedAlbums: []string{},
+	}
+	if since < ml.changeLogStart || since > ml.revision {
+		changes.FullResync = true
+		return changes
+	}
+
+	// Whether each changed song or album existed at since and exists now, in order of first change
+	type changeKey struct {
+		album bool
+		id    string
+	}
+	type changed struct {
+		changeKey
+		existedAt bool
+		existsNow bool
+	}
+	var order []changeKey
+	byKey := make(map[changeKey]*changed)
+	for _, entry := range ml.changeLog {
+		if entry.revision <= since {
+			continue
+		}
+		key := changeKey{entry.album, entry.id}
+		c, ok := byKey[key]
+		if !ok {
+			c = &changed{changeKey: key, existedAt: entry.op != ChangeAdded}
+			byKey[key] = c
+			order = append(order, key)
+		}
+		c.existsNow = entry.op != ChangeRemoved
+	}
+	if len(order) == 0 {
+		return changes
+	}
+
+	songsByID := make(map[string]*Song, len(ml.Songs))
+	for _, song := range ml.Songs {
+		songsByID[song.ID.String()] = song
+	}
+	albumsByID := make(map[string]*Album, len(ml.Albums))
+	for _, album := range ml.Albums {
+		albumsByID[album.ID.String()] = album
+	}
+
+	for _, key := range order {
+		c := byKey[key]
+		switch {
+		case !c.existsNow && c.existedAt && c.album:
+			changes.RemovedAlbums = append(changes.RemovedAlbums, c.id)
+		case !c.existsNow && c.existedAt:
+			changes.RemovedSongs = append(changes.RemovedSongs, c.id)
+		case !c.existsNow:
+			// Added and removed again since, the client never saw it
+		case c.album && albumsByID[c.id] != nil:
+			if c.existedAt {
+				changes.UpdatedAlbums = append(changes.UpdatedAlbums, albumsByID[c.id])
+			} else {
+				changes.AddedAlbums = append(changes.AddedAlbums, albumsByID[c.id])
+			}
+		case !c.album && songsByID[c.id] != nil:
+			if c.existedAt {
+				changes.UpdatedSongs = append(changes.UpdatedSongs, songsByID[c.id])
+			} else {
+				changes.AddedSongs = append(changes.AddedSongs, songsByID[c.id])
+			}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,129 @@
+package library
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// changeSong creates a song with the ID scans give the file at path
+func changeSong(path, title string) *Song {
+	return &Song{ID: songID(path), Path: path, Title: title}
+}
+
+// storeScan records and stores songs the way a finished scan does
+func storeScan(ml *MusicLibrary, songs []*Song) int64 {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+	ml.recordChanges(songs, nil)
+	ml.Songs = songs
+	return ml.revision
+}
+
+// changeSummary lists the titles or IDs of changed songs, for comparison
+func changeSummary(changes LibraryChanges) map[string][]string {
+	summary := map[string][]string{}
+	for _, song := range changes.AddedSongs {
+		summary["added"] = append(summary["added"], song.Title)
+	}
+	for _, song := range changes.UpdatedSongs {
+		summary["updated"] = append(summary["updated"], song.Title)
+	}
+	for _, id := range changes.RemovedSongs {
+		summary["removed"] = append(summary["removed"], id)
+	}
+	return summary
+}
+
+func TestChangesSince(t *testing.T) {
+	a := changeSong("/music/a.mp3", "A")
+	b := changeSong("/music/b.mp3", "B")
+	c := changeSong("/music/c.mp3", "C")
+	aRenamed := changeSong("/music/a.mp3", "A (Remastered)")
+	aRenamedAgain := changeSong("/music/a.mp3", "A (Live)")
+
+	ml := NewMusicLibrary()
+	first := storeScan(ml, []*Song{a, b})
+	if unchanged := storeScan(ml, []*Song{a, b}); unchanged != first {
+		t.Fatalf("revision changed from %d to %d without changes", first, unchanged)
+	}
+	second := storeScan(ml, []*Song{aRenamed, c})     // A updated, B removed, C added
+	third := storeScan(ml, []*Song{aRenamedAgain, b}) // A updated again, B back, C removed
+	if !(first < second && second < third) {
+		t.Fatalf("revisions %d, %d, %d do not increase", first, second, third)
+	}
+
+	tests := []struct {
+		name  string
+		since int64
+		want  map[string][]string
+	}{
+		{"up to date", third, map[string][]string{}},
+		{"one scan behind", second, map[string][]string{
+			"updated": {"A (Live)"},
+			"added":   {"B"},
+			"removed": {c.ID.String()},
+		}},
+		// C was added and removed since, the client never saw it. B was removed and came back,
+		// so the client has an old copy to update. A is listed once.
+		{"two scans behind", first, map[string][]string{
+			"updated": {"A (Live)", "B"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := ml.ChangesSince(tt.since)
+			if changes.FullResync {
+				t.Fatalf("ChangesSince(%d) asks for a full resync", tt.since)
+			}
+			if changes.Revision != third {
+				t.Errorf("ChangesSince(%d).Revision = %d, want %d", tt.since, changes.Revision, third)
+			}
+			if got := changeSummary(changes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChangesSince(%d) = %v, want %v", tt.since, got, tt.want)
+			}
+		})
+	}
+
+	for _, since := range []int64{first - 1, third + 1} {
+		if !ml.ChangesSince(since).FullResync {
+			t.Errorf("ChangesSince(%d) does not ask for a full resync, the log covers %d to %d", since, first, third)
+		}
+	}
+}
+
+func TestChangeLogTruncation(t *testing.T) {
+	ml := NewMusicLibrary()
+	songs := []*Song{changeSong("/music/0.mp3", "0")}
+	first := storeScan(ml, songs)
+
+	// A small revision, then one that pushes the log past its limit
+	songs = append(songs, changeSong("/music/1.mp3", "1"), changeSong("/music/2.mp3", "2"))
+	small := storeScan(ml, songs)
+	for i := 3; len(songs) < maxChangeLogEntries+2; i++ { // With the 2 logged changes, one over the limit
+		songs = append(songs, changeSong(fmt.Sprintf("/music/%d.mp3", i), fmt.Sprint(i)))
+	}
+	large := storeScan(ml, songs)
+	added := len(songs) - 3
+
+	if len(ml.changeLog) != added {
+		t.Errorf("change log has %d entries, want the %d of the last revision", len(ml.changeLog), added)
+	}
+	for _, entry := range ml.changeLog {
+		if entry.revision != large {
+			t.Fatalf("change log kept an entry of revision %d, want only %d", entry.revision, large)
+		}
+	}
+
+	// Only revisions whose following changes are all in the log can be caught up on
+	if !ml.ChangesSince(first).FullResync {
+		t.Errorf("ChangesSince(%d) does not ask for a full resync after its changes were dropped", first)
+	}
+	if changes := ml.ChangesSince(small); changes.FullResync || len(changes.AddedSongs) != added {
+		t.Errorf("ChangesSince(%d) = resync %t with %d added songs, want %d", small, changes.FullResync, len(changes.AddedSongs), added)
+	}
+	if changes := ml.ChangesSince(large); changes.FullResync || len(changes.AddedSongs) != 0 {
+		t.Errorf("ChangesSince(%d) = resync %t with %d added songs, want none", large, changes.FullResync, len(changes.AddedSongs))
+	}
+}
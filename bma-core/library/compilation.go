@@ -0,0 +1,95 @@
+package library
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// VariousArtists is the album artist shown for compilations
+const VariousArtists = "Various Artists"
+
+// Values of ScanOptions.Compilations
+const (
+	CompilationsAuto = "auto" // Compilation tags, "Various Artists" album artists and the folder heuristic
+	CompilationsTags = "tags" // Only compilation tags and "Various Artists" album artists
+)
+
+// compilationMinArtists is the number of different artists a folder album needs before the heuristic treats it as a compilation
+const compilationMinArtists = 3
+
+// variousArtistsNames are album artist tags that mark a compilation (lowercase)
+var variousArtistsNames = map[string]bool{
+	"various artists": true,
+	"various artist":  true,
+	"various":         true,
+	"va":              true,
+	"v.a.":            true,
+	"v/a":             true,
+}
+
+// isVariousArtists reports whether an album artist tag names no single artist
+func isVariousArtists(name string) bool {
+	return variousArtistsNames[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// hasCompilationFlag reports whether raw tags carry the iTunes compilation flag (TCMP, TCP in ID3v2.2, cpil in MP4)
+func hasCompilationFlag(raw map[string]interface{}) bool {
+	for _, name := range []string{"TCMP", "TCP", "cpil"} {
+		switch value := raw[name].(type) {
+		case string:
+			if v := strings.TrimSpace(strings.Trim(value, "\x00")); v != "" && v != "0" {
+				return true
+			}
+		case int:
+			if value != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// markCompilations sets Song.Compilation from the tags and, if useHeuristic is set,
+// for folders whose songs share an album but have many different artists and no album artist.
+func markCompilations(songs []*Song, useHeuristic bool) {
+	type folderAlbum struct{ folder, album string }
+	candidates := make(map[folderAlbum][]*Song)
+
+	for _, song := range songs {
+		song.Compilation = song.compilationTag || isVariousArtists(song.AlbumArtist)
+		if !song.Compilation && song.AlbumArtist == "" {
+			key := folderAlbum{filepath.Clean(song.ParentDirectory), strings.ToLower(albumName(song))}
+			candidates[key] = append(candidates[key], song)
+		}
+	}
+	if !useHeuristic {
+		return
+	}
+
+	for _, folderSongs := range candidates {
+		if looksLikeCompilation(folderSongs) {
+			for _, song := range folderSongs {
+				song.Compilation = true
+			}
+		}
+	}
+}
+
+// looksLikeCompilation reports whether the songs of one folder album are by many artists, none of them dominant
+func looksLikeCompilation(songs []*Song) bool {
+	artistCounts := make(map[string]int)
+	for _, song := range songs {
+		if song.Artist != "" {
+			artistCounts[strings.ToLower(song.Artist)]++
+		}
+	}
+	if len(artistCounts) < compilationMinArtists {
+		return false
+	}
+	for _, count := range artistCounts {
+		if count*2 > len(songs) {
+			return false // Mostly one artist with a few guests
+		}
+	}
+	return true
+}
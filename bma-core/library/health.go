@@ -0,0 +1,214 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dhowden/tag"
+)
+
+// IssueKind identifies a type of problem found by the library scanner
+type IssueKind string
+
+const (
+	IssueUnreadable         IssueKind = "unreadable"           // File or folder could not be read
+	IssueNoTags             IssueKind = "no_tags"              // File has no ID3 tags, metadata comes from the filename
+	IssueFilenameFallback   IssueKind = "filename_fallback"    // Tags are broken or have no title
+	IssueMissingTrackNumber IssueKind = "missing_track_number" // Neither tags nor filename give a track number
+	IssueMissingArtwork     IssueKind = "missing_artwork"      // No song of the album has embedded artwork
+	IssueInconsistentArtist IssueKind = "inconsistent_artist"  // Songs of one album name different artists
+	IssueDuplicateTrack     IssueKind = "duplicate_track"      // Songs of one album share a track number
+)
+
+// IssueKinds lists all issue kinds in display order
+var IssueKinds = []IssueKind{
+	IssueUnreadable,
+	IssueNoTags,
+	IssueFilenameFallback,
+	IssueMissingTrackNumber,
+	IssueMissingArtwork,
+	IssueInconsistentArtist,
+	IssueDuplicateTrack,
+}
+
+// Description returns a short human readable name for the issue kind
+func (k IssueKind) Description() string {
+	switch k {
+	case IssueUnreadable:
+		return "Unreadable files"
+	case IssueNoTags:
+		return "Files without tags"
+	case IssueFilenameFallback:
+		return "Titles taken from filename"
+	case IssueMissingTrackNumber:
+		return "Missing track numbers"
+	case IssueMissingArtwork:
+		return "Albums without artwork"
+	case IssueInconsistentArtist:
+		return "Albums with inconsistent artists"
+	case IssueDuplicateTrack:
+		return "Duplicate track numbers"
+	default:
+		return string(k)
+	}
+}
+
+// LibraryIssue is one problem found by the library scanner
+type LibraryIssue struct {
+	Kind   IssueKind `json:"kind"`
+	Path   string    `json:"path,omitempty"` // File or folder, empty for album issues
+	Album  string    `json:"album,omitempty"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// HealthReport collects the problems found by the last scan
+type HealthReport struct {
+	GeneratedAt time.Time         `json:"generatedAt"`
+	SongCount   int               `json:"songCount"`
+	AlbumCount  int               `json:"albumCount"`
+	Counts      map[IssueKind]int `json:"counts"`
+	Issues      []LibraryIssue    `json:"issues"`
+}
+
+// IssuesOfKind returns the issues of one kind
+func (r *HealthReport) IssuesOfKind(kind IssueKind) []LibraryIssue {
+	var issues []LibraryIssue
+	for _, issue := range r.Issues {
+		if issue.Kind == kind {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// issueCollector gathers issues from concurrent scan workers
+type issueCollector struct {
+	mutex  sync.Mutex
+	issues []LibraryIssue
+}
+
+// add records an issue (thread-safe)
+func (c *issueCollector) add(issue LibraryIssue) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.issues = append(c.issues, issue)
+}
+
+// list returns a copy of the recorded issues (thread-safe)
+func (c *issueCollector) list() []LibraryIssue {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	issues := make([]LibraryIssue, len(c.issues))
+	copy(issues, c.issues)
+	return issues
+}
+
+// buildHealthReport checks the scanned songs and albums, after the issues found while reading files
+func buildHealthReport(songs []*Song, albums []*Album, scanIssues []LibraryIssue) *HealthReport {
+	report := &HealthReport{
+		GeneratedAt: time.Now(),
+		SongCount:   len(songs),
+		AlbumCount:  len(albums),
+		Counts:      make(map[IssueKind]int),
+		Issues:      scanIssues,
+	}
+
+	for _, song := range songs {
+		if song.Offline {
+			continue // Checked when the root was last available
+		}
+		report.Issues = append(report.Issues, songIssues(song)...)
+	}
+	for _, album := range albums {
+		report.Issues = append(report.Issues, albumIssues(album)...)
+	}
+
+	for _, issue := range report.Issues {
+		report.Counts[issue.Kind]++
+	}
+	if report.Issues == nil {
+		report.Issues = []LibraryIssue{}
+	}
+	return report
+}
+
+// songIssues returns the metadata problems of one song
+func songIssues(song *Song) []LibraryIssue {
+	var issues []LibraryIssue
+	var pathErr *fs.PathError
+
+	switch {
+	case song.metadataErr == nil && song.titleFromFilename:
+		issues = append(issues, LibraryIssue{Kind: IssueFilenameFallback, Path: song.Path, Detail: "no title tag"})
+	case song.metadataErr == nil:
+	case errors.Is(song.metadataErr, tag.ErrNoTagsFound):
+		issues = append(issues, LibraryIssue{Kind: IssueNoTags, Path: song.Path})
+	case errors.As(song.metadataErr, &pathErr):
+		issues = append(issues, LibraryIssue{Kind: IssueUnreadable, Path: song.Path, Detail: pathErr.Err.Error()})
+	default:
+		issues = append(issues, LibraryIssue{Kind: IssueFilenameFallback, Path: song.Path, Detail: song.metadataErr.Error()})
+	}
+
+	if song.TrackNumber == 0 {
+		issues = append(issues, LibraryIssue{Kind: IssueMissingTrackNumber, Path: song.Path, Album: song.Album})
+	}
+	return issues
+}
+
+// albumIssues returns the problems found across the songs of one album
+func albumIssues(album *Album) []LibraryIssue {
+	var issues []LibraryIssue
+
+	hasArtwork := false
+	artists := make(map[string]bool)
+	type discTrack struct{ disc, track int }
+	trackCounts := make(map[discTrack]int)
+	for _, song := range album.Songs {
+		hasArtwork = hasArtwork || song.HasArtwork()
+		if song.Artist != "" {
+			artists[song.Artist] = true
+		}
+		if song.TrackNumber > 0 {
+			trackCounts[discTrack{song.Disc(), song.TrackNumber}]++
+		}
+	}
+
+	if !hasArtwork {
+		issues = append(issues, LibraryIssue{Kind: IssueMissingArtwork, Album: album.Name})
+	}
+
+	if len(artists) > 1 && !album.IsCompilation {
+		names := make([]string, 0, len(artists))
+		for name := range artists {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		issues = append(issues, LibraryIssue{Kind: IssueInconsistentArtist, Album: album.Name, Detail: strings.Join(names, ", ")})
+	}
+
+	var duplicates []discTrack
+	for position, count := range trackCounts {
+		if count > 1 {
+			duplicates = append(duplicates, position)
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].disc != duplicates[j].disc {
+			return duplicates[i].disc < duplicates[j].disc
+		}
+		return duplicates[i].track < duplicates[j].track
+	})
+	for _, position := range duplicates {
+		detail := fmt.Sprintf("track %d appears %d times", position.track, trackCounts[position])
+		if album.DiscCount > 1 {
+			detail = fmt.Sprintf("disc %d %s", position.disc, detail)
+		}
+		issues = append(issues, LibraryIssue{Kind: IssueDuplicateTrack, Album: album.Name, Detail: detail})
+	}
+	return issues
+}
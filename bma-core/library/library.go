@@ -0,0 +1,561 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bma-core/logging"
+	"github.com/google/uuid"
+)
+
+// logger tags the library's log lines with component=library
+var logger = logging.Component("library")
+
+// Album represents a collection of songs grouped by album name
+type Album struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	Songs         []*Song   `json:"songs"`
+	Artist        string    `json:"artist,omitempty"` // Album artist, "Various Artists" for compilations, or the first song's artist
+	Year          int       `json:"year,omitempty"`
+	Genre         string    `json:"genre,omitempty"`
+	DiscCount     int       `json:"discCount,omitempty"`
+	IsCompilation bool      `json:"isCompilation,omitempty"`
+}
+
+// TrackCount returns the number of songs in the album
+func (a *Album) TrackCount() int {
+	return len(a.Songs)
+}
+
+// MusicLibrary manages the collection of songs and albums
+type MusicLibrary struct {
+	mutex               sync.RWMutex
+	Songs               []*Song       `json:"songs"`
+	Albums              []*Album      `json:"albums"`
+	Artists             []*Artist     `json:"artists"`
+	SelectedFolderPath  string        `json:"selectedFolderPath,omitempty"` // First root, kept for older clients
+	Roots               []LibraryRoot `json:"roots"`
+	RootStatuses        []RootStatus  `json:"rootStatuses"`
+	IsScanning          bool          `json:"isScanning"`
+	LastScan            ScanStats     `json:"lastScan"`
+	Health              *HealthReport `json:"health,omitempty"` // Problems found by the last scan
+	scanOptions         ScanOptions
+	currentScan         *scanRun // Running scan, nil when idle
+	searchIndex         *searchIndex
+	revision            int64         // Increases on every change, see recordChanges
+	scanned             bool
+	changeLog           []changeEntry
+	changeLogStart      int64         // Oldest revision the change log can bring up to date
+	onScanningChanged   func(bool)
+	onLibraryChanged    func()
+	
+	progressMutex       sync.Mutex
+	progress            ScanProgress
+	progressReported    time.Time
+	onScanProgress      func(ScanProgress)
+}
+
+// RootStatus reports the outcome of the last scan of one library root
+type RootStatus struct {
+	Path      string `json:"path"`
+	Label     string `json:"label,omitempty"`
+	SongCount int    `json:"songCount"`
+	Offline   bool   `json:"offline,omitempty"` // Songs were kept from the previous scan
+	Error     string `json:"error,omitempty"`
+}
+
+// NewMusicLibrary creates a new music library instance
+func NewMusicLibrary() *MusicLibrary {
+	revision := initialRevision()
+	return &MusicLibrary{
+		Songs:          make([]*Song, 0),
+		Albums:         make([]*Album, 0),
+		revision:       revision,
+		changeLogStart: revision,
+	}
+}
+
+// SetScanningChangedCallback sets the callback for scanning state changes
+func (ml *MusicLibrary) SetScanningChangedCallback(callback func(bool)) {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+	ml.onScanningChanged = callback
+}
+
+// SetLibraryChangedCallback sets the callback for library updates
+func (ml *MusicLibrary) SetLibraryChangedCallback(callback func()) {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+	ml.onLibraryChanged = callback
+}
+
+// SetScanOptions sets the include/exclude rules used by the next scan
+func (ml *MusicLibrary) SetScanOptions(options ScanOptions) {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+	ml.scanOptions = options.WithDefaults()
+}
+
+// SelectFolder makes folderPath the only library root and scans it
+func (ml *MusicLibrary) SelectFolder(folderPath string) {
+	ml.SelectRoots([]LibraryRoot{{Path: folderPath, Enabled: true}})
+}
+
+// SelectRoots sets the library roots and scans all enabled roots into one library
+func (ml *MusicLibrary) SelectRoots(roots []LibraryRoot) {
+	ml.SetRoots(roots)
+	ml.ScanFolder()
+}
+
+// SetRoots sets the library roots without scanning them, stopping a scan of the old roots
+func (ml *MusicLibrary) SetRoots(roots []LibraryRoot) {
+	ml.mutex.Lock()
+	ml.Roots = make([]LibraryRoot, len(roots))
+	copy(ml.Roots, roots)
+	ml.SelectedFolderPath = ""
+	if len(roots) > 0 {
+		ml.SelectedFolderPath = roots[0].Path
+	}
+	ml.mutex.Unlock()
+	
+	// A scan of the old roots would be discarded anyway
+	ml.CancelScan()
+	
+	for _, root := range roots {
+		if root.Enabled {
+			logger.Info("Library root selected", "root", root.Path, "label", root.DisplayName())
+		}
+	}
+}
+
+// ScanFolder scans the selected folder for MP3 files
+func (ml *MusicLibrary) ScanFolder() {
+	if err := ml.ScanFolderContext(context.Background()); err != nil {
+		logger.Warn("Scan not completed", "err", err)
+	}
+}
+
+// ScanFolderContext scans all enabled roots until ctx is cancelled.
+// Only one scan runs at a time, a concurrent call returns ErrScanInProgress.
+func (ml *MusicLibrary) ScanFolderContext(ctx context.Context) error {
+	run, err := ml.beginScan(ctx)
+	if err != nil {
+		return err
+	}
+	if run == nil {
+		logger.Warn("No library root selected for scanning")
+		return nil
+	}
+	return ml.runScan(run)
+}
+
+// runScan scans the roots claimed by beginScan and replaces the library unless the scan is cancelled
+func (ml *MusicLibrary) runScan(run *scanRun) error {
+	defer ml.endScan(run)
+	roots := run.roots
+	
+	// Notify scanning started, the previous index stays available until the scan completes
+	if ml.onScanningChanged != nil {
+		ml.onScanningChanged(true)
+	}
+	
+	logger.Info("Scan started", "roots", len(roots))
+	
+	// Scan each root separately so one failing root does not discard the others
+	scanStart := time.Now()
+	var stats ScanStats
+	var discoveredSongs []*Song
+	statuses := make([]RootStatus, 0, len(roots))
+	failedRoots := 0
+	for _, root := range roots {
+		if run.ctx.Err() != nil {
+			break
+		}
+		
+		// Keep the last known songs of a root that went away, e.g. an unplugged drive
+		if err := checkRootAvailable(root.Path); err != nil && (err != errRootEmpty || len(run.previousSongs[root.Path]) > 0) {
+			previous := run.previousSongs[root.Path]
+			logger.Warn("Root offline, keeping the songs of the last scan", "root", root.Path, "err", err, "songs", len(previous))
+			statuses = append(statuses, RootStatus{Path: root.Path, Label: root.Label, SongCount: len(previous), Offline: true, Error: err.Error()})
+			run.issues.add(LibraryIssue{Kind: IssueUnreadable, Path: root.Path, Detail: err.Error()})
+			discoveredSongs = append(discoveredSongs, offlineCopies(previous, err.Error())...)
+			failedRoots++
+			continue
+		}
+		
+		var rootSongs []*Song
+		logger.Debug("Scanning root", "root", root.Path)
+		err := ml.scanDirectory(run, root.Path, &rootSongs, &stats)
+		if run.ctx.Err() != nil {
+			break
+		}
+		
+		status := RootStatus{Path: root.Path, Label: root.Label, SongCount: len(rootSongs)}
+		if err != nil {
+			logger.Error("Failed to scan root", "root", root.Path, "err", err)
+			status.Error = err.Error()
+			run.issues.add(LibraryIssue{Kind: IssueUnreadable, Path: root.Path, Detail: err.Error()})
+			failedRoots++
+		}
+		statuses = append(statuses, status)
+		
+		// Record which root each song came from
+		for _, song := range rootSongs {
+			song.RootPath = root.Path
+			song.RootLabel = root.DisplayName()
+		}
+		discoveredSongs = append(discoveredSongs, rootSongs...)
+	}
+	
+	stats.finish(scanStart)
+	
+	// A cancelled scan leaves the previous library untouched
+	if err := run.ctx.Err(); err != nil {
+		logger.Info("Scan cancelled", "files", stats.Files)
+		if ml.onScanningChanged != nil {
+			ml.onScanningChanged(false)
+		}
+		return err
+	}
+	
+	// Offline roots still contribute their previous songs, so the library is rebuilt either way
+	if failedRoots == len(roots) {
+		logger.Error("Scan failed, no library root could be read")
+	}
+	
+	// Apply enhanced sorting and organization BEFORE acquiring the final lock
+	markCompilations(discoveredSongs, run.options.Compilations != CompilationsTags)
+	sortedSongs := ml.organizeAndSortSongs(discoveredSongs)
+	
+	organizedAlbums := ml.organizeIntoAlbums(sortedSongs)
+	artists := buildArtistIndex(sortedSongs, organizedAlbums)
+	index := buildSearchIndex(sortedSongs, organizedAlbums, artists)
+	health := buildHealthReport(sortedSongs, organizedAlbums, run.issues.list())
+	
+	// Now acquire lock only to update the final state
+	ml.mutex.Lock()
+	ml.recordChanges(sortedSongs, organizedAlbums)
+	ml.Songs = sortedSongs
+	ml.Albums = organizedAlbums
+	ml.Artists = artists
+	ml.searchIndex = index
+	ml.RootStatuses = statuses
+	ml.LastScan = stats
+	ml.Health = health
+	ml.mutex.Unlock()
+	
+	logger.Info("Scan complete",
+		"songs", len(sortedSongs),
+		"albums", len(organizedAlbums),
+		"files", stats.Files,
+		"failed", stats.Failed,
+		"duration", stats.Duration.Round(time.Millisecond),
+		"workers", stats.Workers,
+		"files_per_sec", int(stats.FilesPerSecond))
+	ml.printLibraryDebugInfo()
+	
+	// Call callbacks AFTER releasing the mutex to avoid deadlock
+	if ml.onScanningChanged != nil {
+		ml.onScanningChanged(false)
+	}
+	if ml.onLibraryChanged != nil {
+		ml.onLibraryChanged()
+	}
+	return nil
+}
+
+// scanDirectory recursively scans a directory for MP3 files, parsing tags on a pool of workers
+func (ml *MusicLibrary) scanDirectory(run *scanRun, dirPath string, songs *[]*Song, stats *ScanStats) error {
+	found, err := ml.extractSongs(run, dirPath, stats)
+	*songs = append(*songs, found...)
+	return err
+}
+
+// organizeAndSortSongs applies enhanced sorting with numbered track priority
+func (ml *MusicLibrary) organizeAndSortSongs(songs []*Song) []*Song {
+	// Create a copy to avoid modifying the original slice
+	sortedSongs := make([]*Song, len(songs))
+	copy(sortedSongs, songs)
+	years := releaseYears(sortedSongs)
+	
+	sort.Slice(sortedSongs, func(i, j int) bool {
+		song1, song2 := sortedSongs[i], sortedSongs[j]
+		
+		// First sort by album, then by disc so multi-disc sets don't interleave
+		if order := compareAlbumOrder(song1, song2, years); order != 0 {
+			return order < 0
+		}
+		
+		// Within same disc, apply numbered track priority
+		return ml.compareTracksWithNumberPriority(song1, song2)
+	})
+	
+	return sortedSongs
+}
+
+// compareTracksWithNumberPriority implements lexicographic sorting with numbered track priority (01, 02, 10)
+func (ml *MusicLibrary) compareTracksWithNumberPriority(song1, song2 *Song) bool {
+	// First priority: Use actual track numbers from ID3 tags if available
+	if song1.TrackNumber > 0 && song2.TrackNumber > 0 {
+		if song1.TrackNumber != song2.TrackNumber {
+			return song1.TrackNumber < song2.TrackNumber
+		}
+		// If track numbers are same, compare titles
+		return strings.ToLower(song1.Title) < strings.ToLower(song2.Title)
+	}
+	
+	// Second priority: One has track number, other doesn't
+	if song1.TrackNumber > 0 && song2.TrackNumber == 0 {
+		return true // Song with track number comes first
+	}
+	if song1.TrackNumber == 0 && song2.TrackNumber > 0 {
+		return false // Song with track number comes first
+	}
+	
+	// Third priority: Fall back to filename-based track number parsing
+	number1 := ml.extractLeadingNumber(song1.Title)
+	number2 := ml.extractLeadingNumber(song2.Title)
+	
+	switch {
+	case number1 != nil && number2 != nil:
+		// Both have numbers - compare lexicographically (01 comes before 10)
+		str1 := fmt.Sprintf("%02d", *number1)
+		str2 := fmt.Sprintf("%02d", *number2)
+		if str1 != str2 {
+			return strings.ToLower(str1) < strings.ToLower(str2)
+		}
+		// If numbers are same, compare rest of title
+		return strings.ToLower(song1.Title) < strings.ToLower(song2.Title)
+		
+	case number1 != nil && number2 == nil:
+		// First has number, second doesn't - numbered comes first
+		return true
+		
+	case number1 == nil && number2 != nil:
+		// Second has number, first doesn't - numbered comes first
+		return false
+		
+	default:
+		// Neither has numbers - normal alphabetical
+		return strings.ToLower(song1.Title) < strings.ToLower(song2.Title)
+	}
+}
+
+// extractLeadingNumber extracts the leading number from a track title
+func (ml *MusicLibrary) extractLeadingNumber(title string) *int {
+	pattern := regexp.MustCompile(`^(\d+)`)
+	matches := pattern.FindStringSubmatch(title)
+	if len(matches) >= 2 {
+		if num, err := strconv.Atoi(matches[1]); err == nil {
+			return &num
+		}
+	}
+	return nil
+}
+
+// organizeIntoAlbums groups songs into albums by album artist, name and year
+func (ml *MusicLibrary) organizeIntoAlbums(songs []*Song) []*Album {
+	// Group songs by album artist and album name, keeping the sorted track order
+	var keys []string
+	albumMap := make(map[string][]*Song)
+	for _, song := range songs {
+		key := albumKey(song)
+		if _, exists := albumMap[key]; !exists {
+			keys = append(keys, key)
+		}
+		albumMap[key] = append(albumMap[key], song)
+	}
+	
+	// Create Album structs, separating releases of different years
+	var albums []*Album
+	for _, key := range keys {
+		for _, albumSongs := range splitByYear(albumMap[key]) {
+			album := newAlbum(albumSongs)
+			albums = append(albums, album)
+		}
+	}
+	
+	// Sort albums by name, then artist and year
+	sort.SliceStable(albums, func(i, j int) bool {
+		name1, name2 := strings.ToLower(albums[i].Name), strings.ToLower(albums[j].Name)
+		if name1 != name2 {
+			return name1 < name2
+		}
+		artist1, artist2 := strings.ToLower(albums[i].Artist), strings.ToLower(albums[j].Artist)
+		if artist1 != artist2 {
+			return artist1 < artist2
+		}
+		return albums[i].Year < albums[j].Year
+	})
+	
+	return albums
+}
+
+// printLibraryDebugInfo logs the first albums and their tracks at debug level
+func (ml *MusicLibrary) printLibraryDebugInfo() {
+	if !logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	
+	// Show first 3 albums
+	maxAlbums := 3
+	if len(ml.Albums) < maxAlbums {
+		maxAlbums = len(ml.Albums)
+	}
+	
+	for i := 0; i < maxAlbums; i++ {
+		album := ml.Albums[i]
+		
+		// Show first 5 songs per album
+		maxSongs := 5
+		if len(album.Songs) < maxSongs {
+			maxSongs = len(album.Songs)
+		}
+		
+		titles := make([]string, maxSongs)
+		for j := 0; j < maxSongs; j++ {
+			titles[j] = album.Songs[j].Title
+		}
+		logger.Debug("Album", "name", album.Name, "songs", album.TrackCount(), "first_tracks", titles)
+	}
+}
+
+// GetSongByID finds a song by its UUID
+func (ml *MusicLibrary) GetSongByID(id string) *Song {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+	
+	for _, song := range ml.Songs {
+		if song.ID.String() == id {
+			return song
+		}
+	}
+	return nil
+}
+
+// GetSongCount returns the total number of songs in the library
+func (ml *MusicLibrary) GetSongCount() int {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+	return len(ml.Songs)
+}
+
+// GetAlbumCount returns the total number of albums in the library
+func (ml *MusicLibrary) GetAlbumCount() int {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+	return len(ml.Albums)
+}
+
+// GetSongs returns a copy of all songs (thread-safe)
+func (ml *MusicLibrary) GetSongs() []*Song {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+	
+	songs := make([]*Song, len(ml.Songs))
+	copy(songs, ml.Songs)
+	return songs
+}
+
+// GetAlbums returns a copy of all albums (thread-safe)
+func (ml *MusicLibrary) GetAlbums() []*Album {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+	
+	albums := make([]*Album, len(ml.Albums))
+	copy(albums, ml.Albums)
+	return albums
+}
+
+// GetArtists returns a copy of all artists sorted by sort name (thread-safe)
+func (ml *MusicLibrary) GetArtists() []*Artist {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+	
+	artists := make([]*Artist, len(ml.Artists))
+	copy(artists, ml.Artists)
+	return artists
+}
+
+// GetArtistByID finds an artist by its ID
+func (ml *MusicLibrary) GetArtistByID(id string) *Artist {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+	
+	for _, artist := range ml.Artists {
+		if artist.ID.String() == id {
+			return artist
+		}
+	}
+	return nil
+}
+
+// Search finds songs, albums and artists matching the query, at most limit of each (thread-safe).
+// See parseSearchQuery for the query syntax.
+func (ml *MusicLibrary) Search(query string, limit int) SearchResults {
+	ml.mutex.RLock()
+	index := ml.searchIndex
+	ml.mutex.RUnlock()
+	
+	if index == nil {
+		return SearchResults{Songs: []*Song{}, Albums: []*Album{}, Artists: []*Artist{}}
+	}
+	return index.search(query, limit)
+}
+
+// GetRoots returns a copy of the library roots (thread-safe)
+func (ml *MusicLibrary) GetRoots() []LibraryRoot {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+	
+	roots := make([]LibraryRoot, len(ml.Roots))
+	copy(roots, ml.Roots)
+	return roots
+}
+
+// GetRootStatuses returns the per-root results of the last scan (thread-safe)
+func (ml *MusicLibrary) GetRootStatuses() []RootStatus {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+	
+	statuses := make([]RootStatus, len(ml.RootStatuses))
+	copy(statuses, ml.RootStatuses)
+	return statuses
+}
+
+// GetLastScanStats returns the file counts and throughput of the last scan (thread-safe)
+func (ml *MusicLibrary) GetLastScanStats() ScanStats {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+	return ml.LastScan
+}
+
+// GetHealthReport returns the problems found by the last scan, or nil before the first scan
+func (ml *MusicLibrary) GetHealthReport() *HealthReport {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+	return ml.Health
+}
+
+// IsCurrentlyScanning returns true if the library is currently scanning
+func (ml *MusicLibrary) IsCurrentlyScanning() bool {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+	return ml.IsScanning
+}
+
+// Helper function for min
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
\ No newline at end of file
@@ -0,0 +1,119 @@
+package library
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// RootMonitorInterval is how often StartRootMonitor checks the library roots
+const RootMonitorInterval = 30 * time.Second
+
+// errRootEmpty is returned for a root folder without entries, usually an unmounted mount point
+var errRootEmpty = errors.New("folder is empty (drive not mounted?)")
+
+// OfflineError explains why a song cannot be streamed right now
+type OfflineError struct {
+	RootPath string
+	Reason   string
+}
+
+func (e *OfflineError) Error() string {
+	return fmt.Sprintf("library root %s is offline: %s", e.RootPath, e.Reason)
+}
+
+// checkRootAvailable returns an error if a library root cannot be read right now
+func checkRootAvailable(rootPath string) error {
+	entries, err := os.ReadDir(rootPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("folder not found")
+		}
+		return err
+	}
+	if len(entries) == 0 {
+		return errRootEmpty
+	}
+	return nil
+}
+
+// offlineCopies returns copies of songs marked as offline, so readers of the old index are unaffected
+func offlineCopies(songs []*Song, reason string) []*Song {
+	copies := make([]*Song, len(songs))
+	for i, song := range songs {
+		copied := *song
+		copied.Offline = true
+		copied.OfflineReason = reason
+		copies[i] = &copied
+	}
+	return copies
+}
+
+// CheckSongAvailable returns an *OfflineError if the song's root is currently unavailable
+func (ml *MusicLibrary) CheckSongAvailable(song *Song) error {
+	if song.Offline {
+		return &OfflineError{RootPath: song.RootPath, Reason: song.OfflineReason}
+	}
+
+	if _, err := os.Stat(song.Path); err == nil || song.RootPath == "" {
+		return nil
+	}
+
+	// The file vanished since the last scan, check whether the whole root went away
+	if err := checkRootAvailable(song.RootPath); err != nil {
+		logger.Warn("Root went offline", "root", song.RootPath, "err", err)
+		go ml.rescanIfIdle()
+		return &OfflineError{RootPath: song.RootPath, Reason: err.Error()}
+	}
+	return nil
+}
+
+// StartRootMonitor polls the library roots and rescans when one goes offline or comes back
+func (ml *MusicLibrary) StartRootMonitor(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if ml.rootAvailabilityChanged() {
+					logger.Info("Library root availability changed, rescanning")
+					ml.rescanIfIdle()
+				}
+			}
+		}
+	}()
+
+	logger.Debug("Monitoring library roots", "interval", interval)
+	return func() { close(done) }
+}
+
+// rootAvailabilityChanged compares each root's current state with the last scan
+func (ml *MusicLibrary) rootAvailabilityChanged() bool {
+	statuses := ml.GetRootStatuses()
+	for _, status := range statuses {
+		err := checkRootAvailable(status.Path)
+		if err == errRootEmpty && status.SongCount == 0 {
+			err = nil // Root was empty before as well
+		}
+
+		available := err == nil
+		if available == status.Offline {
+			return true
+		}
+	}
+	return false
+}
+
+// rescanIfIdle scans the library unless a scan is already running
+func (ml *MusicLibrary) rescanIfIdle() {
+	if err := ml.ScanFolderContext(context.Background()); err != nil && err != ErrScanInProgress {
+		logger.Warn("Rescan failed", "err", err)
+	}
+}
@@ -0,0 +1,262 @@
+package library
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SongSortKeys are the values accepted by SortSongs, "" keeps the library order
+var SongSortKeys = []string{"title", "artist", "album", "year"}
+
+// AlbumSortKeys are the values accepted by SortAlbums, "" keeps the library order
+var AlbumSortKeys = []string{"name", "artist", "year", "trackCount"}
+
+// LibraryFilter selects songs or albums. Text fields ignore case and accents, empty fields match everything.
+type LibraryFilter struct {
+	Album    string
+	Artist   string // Matches any credited artist or the album artist, "The X" and "X, The" alike
+	Genre    string
+	Folder   string // Absolute path, or relative to the library root; subfolders match too
+	YearFrom int
+	YearTo   int
+}
+
+// ParseYearRange parses "1997" or "1990-1999"
+func ParseYearRange(value string) (from, to int, err error) {
+	first, last, isRange := strings.Cut(value, "-")
+	if from, err = strconv.Atoi(strings.TrimSpace(first)); err != nil {
+		return 0, 0, fmt.Errorf("invalid year %q", value)
+	}
+	if !isRange {
+		return from, from, nil
+	}
+	if to, err = strconv.Atoi(strings.TrimSpace(last)); err != nil || to < from {
+		return 0, 0, fmt.Errorf("invalid year range %q", value)
+	}
+	return from, to, nil
+}
+
+// IsEmpty reports whether the filter matches everything
+func (f LibraryFilter) IsEmpty() bool {
+	return f == LibraryFilter{}
+}
+
+// normalized returns the filter with its text fields prepared for matchesText and creditsArtist
+func (f LibraryFilter) normalized() LibraryFilter {
+	f.Album = NormalizeSearchText(strings.TrimSpace(f.Album))
+	f.Genre = NormalizeSearchText(strings.TrimSpace(f.Genre))
+	if f.Artist != "" {
+		_, sortName := artistNames(f.Artist)
+		f.Artist = NormalizeSearchText(sortName)
+	}
+	return f
+}
+
+// matchesSong reports whether a song passes a normalized filter
+func (f LibraryFilter) matchesSong(song *Song) bool {
+	if f.Album != "" && !matchesText(f.Album, albumName(song)) {
+		return false
+	}
+	if f.Artist != "" && !creditsArtist(song.Artist, f.Artist) && !creditsArtist(song.AlbumArtist, f.Artist) {
+		return false
+	}
+	if f.Genre != "" && !matchesText(f.Genre, song.Genre) {
+		return false
+	}
+	if !f.matchesYear(song.Year) {
+		return false
+	}
+	return f.Folder == "" || inFolder(song, f.Folder)
+}
+
+// matchesAlbum reports whether an album passes a normalized filter. The folder matches if any song is in it.
+func (f LibraryFilter) matchesAlbum(album *Album) bool {
+	if f.Album != "" && !matchesText(f.Album, album.Name) {
+		return false
+	}
+	if f.Artist != "" && !creditsArtist(album.Artist, f.Artist) {
+		return false
+	}
+	if f.Genre != "" && !matchesText(f.Genre, album.Genre) {
+		return false
+	}
+	if !f.matchesYear(album.Year) {
+		return false
+	}
+	if f.Folder == "" {
+		return true
+	}
+	for _, song := range album.Songs {
+		if inFolder(song, f.Folder) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesYear reports whether year is in the filter's year range
+func (f LibraryFilter) matchesYear(year int) bool {
+	if f.YearFrom == 0 && f.YearTo == 0 {
+		return true
+	}
+	return year >= f.YearFrom && year <= f.YearTo
+}
+
+// FilterSongs returns the songs that pass the filter, in order
+func FilterSongs(songs []*Song, filter LibraryFilter) []*Song {
+	if filter.IsEmpty() {
+		return songs
+	}
+	filter = filter.normalized()
+	matched := make([]*Song, 0, len(songs))
+	for _, song := range songs {
+		if filter.matchesSong(song) {
+			matched = append(matched, song)
+		}
+	}
+	return matched
+}
+
+// FilterAlbums returns the albums that pass the filter, in order
+func FilterAlbums(albums []*Album, filter LibraryFilter) []*Album {
+	if filter.IsEmpty() {
+		return albums
+	}
+	filter = filter.normalized()
+	matched := make([]*Album, 0, len(albums))
+	for _, album := range albums {
+		if filter.matchesAlbum(album) {
+			matched = append(matched, album)
+		}
+	}
+	return matched
+}
+
+// matchesText compares a normalized string with text, ignoring case and accents
+func matchesText(normalized, text string) bool {
+	return normalized == NormalizeSearchText(strings.TrimSpace(text))
+}
+
+// creditsArtist reports whether a credit such as "A feat. B" names the artist, given as a normalized sort name
+func creditsArtist(credit, artist string) bool {
+	for _, name := range SplitArtistCredit(credit) {
+		if _, sortName := artistNames(name); matchesText(artist, sortName) {
+			return true
+		}
+	}
+	return false
+}
+
+// inFolder reports whether a song is in folder or one of its subfolders
+func inFolder(song *Song, folder string) bool {
+	dir := filepath.Clean(song.ParentDirectory)
+	if !filepath.IsAbs(folder) {
+		if song.RootPath == "" {
+			return false
+		}
+		folder = filepath.Join(song.RootPath, folder)
+	}
+	folder = filepath.Clean(folder)
+	return dir == folder || strings.HasPrefix(dir, folder+string(filepath.Separator))
+}
+
+// sortEntry is the precomputed sort key of one item
+type sortEntry struct {
+	text   string
+	number int
+}
+
+// sortedOrder returns the positions of n items sorted by their keys, keeping the library order on ties.
+// Items without a number sort last in both directions when sorting by number.
+func sortedOrder(n int, key func(i int) sortEntry, byNumber, descending bool) []int {
+	entries := make([]sortEntry, n)
+	order := make([]int, n)
+	for i := range entries {
+		entries[i] = key(i)
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := entries[order[i]], entries[order[j]]
+		if byNumber {
+			if (a.number == 0) != (b.number == 0) {
+				return b.number == 0
+			}
+			if descending {
+				return a.number > b.number
+			}
+			return a.number < b.number
+		}
+		if descending {
+			return a.text > b.text
+		}
+		return a.text < b.text
+	})
+	return order
+}
+
+// SortSongs returns the songs sorted by one of SongSortKeys
+func SortSongs(songs []*Song, by string, descending bool) ([]*Song, error) {
+	var key func(*Song) sortEntry
+	byNumber := false
+	switch by {
+	case "":
+		return songs, nil
+	case "title":
+		key = func(s *Song) sortEntry { return sortEntry{text: NormalizeSearchText(s.DisplayTitle())} }
+	case "artist":
+		key = func(s *Song) sortEntry {
+			_, sortName := artistNames(s.Artist)
+			return sortEntry{text: NormalizeSearchText(sortName)}
+		}
+	case "album":
+		key = func(s *Song) sortEntry { return sortEntry{text: NormalizeSearchText(albumName(s))} }
+	case "year":
+		key = func(s *Song) sortEntry { return sortEntry{number: s.Year} }
+		byNumber = true
+	default:
+		return nil, fmt.Errorf("unknown sort key %q", by)
+	}
+
+	order := sortedOrder(len(songs), func(i int) sortEntry { return key(songs[i]) }, byNumber, descending)
+	sorted := make([]*Song, len(songs))
+	for i, position := range order {
+		sorted[i] = songs[position]
+	}
+	return sorted, nil
+}
+
+// SortAlbums returns the albums sorted by one of AlbumSortKeys
+func SortAlbums(albums []*Album, by string, descending bool) ([]*Album, error) {
+	var key func(*Album) sortEntry
+	byNumber := false
+	switch by {
+	case "":
+		return albums, nil
+	case "name":
+		key = func(a *Album) sortEntry { return sortEntry{text: NormalizeSearchText(a.Name)} }
+	case "artist":
+		key = func(a *Album) sortEntry {
+			_, sortName := artistNames(a.Artist)
+			return sortEntry{text: NormalizeSearchText(sortName)}
+		}
+	case "year":
+		key = func(a *Album) sortEntry { return sortEntry{number: a.Year} }
+		byNumber = true
+	case "trackCount":
+		key = func(a *Album) sortEntry { return sortEntry{number: a.TrackCount()} }
+		byNumber = true
+	default:
+		return nil, fmt.Errorf("unknown sort key %q", by)
+	}
+
+	order := sortedOrder(len(albums), func(i int) sortEntry { return key(albums[i]) }, byNumber, descending)
+	sorted := make([]*Album, len(albums))
+	for i, position := range order {
+		sorted[i] = albums[position]
+	}
+	return sorted, nil
+}
@@ -0,0 +1,41 @@
+package library
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+)
+
+// LibraryRoot is a folder that is scanned into the merged music library
+type LibraryRoot struct {
+	Path    string `json:"path"`
+	Label   string `json:"label,omitempty"`
+	Enabled bool   `json:"enabled"`
+}
+
+// UnmarshalJSON treats roots without an "enabled" key as enabled
+func (r *LibraryRoot) UnmarshalJSON(data []byte) error {
+	type rawRoot LibraryRoot
+	raw := rawRoot{Enabled: true}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = LibraryRoot(raw)
+	return nil
+}
+
+// DisplayName returns the label, or the folder name if no label is set
+func (r LibraryRoot) DisplayName() string {
+	if r.Label != "" {
+		return r.Label
+	}
+	return filepath.Base(r.Path)
+}
+
+// Matches returns true if pathOrLabel refers to this root
+func (r LibraryRoot) Matches(pathOrLabel string) bool {
+	if r.Path == filepath.Clean(pathOrLabel) {
+		return true
+	}
+	return r.Label != "" && strings.EqualFold(r.Label, pathOrLabel)
+}
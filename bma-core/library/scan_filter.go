@@ -0,0 +1,269 @@
+package library
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFileName is the per-directory ignore file, read with gitignore semantics
+const ignoreFileName = ".bmaignore"
+
+// DefaultScanInclude matches the files scanned when no include patterns are configured
+var DefaultScanInclude = []string{"*.mp3"}
+
+// DefaultScanExclude skips NAS and OS housekeeping folders when no exclude patterns are configured
+var DefaultScanExclude = []string{"@eaDir/", "#recycle/", "$RECYCLE.BIN/", "System Volume Information/", "lost+found/"}
+
+// ScanOptions controls which files and folders a library scan visits
+type ScanOptions struct {
+	Include        []string `json:"include,omitempty"`        // Gitignore-style patterns of files to scan
+	Exclude        []string `json:"exclude,omitempty"`        // Gitignore-style patterns of files and folders to skip
+	IncludeHidden  bool     `json:"includeHidden,omitempty"`  // Scan files and folders starting with "."
+	FollowSymlinks bool     `json:"followSymlinks,omitempty"` // Descend into symlinked folders
+	MaxDepth       int      `json:"maxDepth,omitempty"`       // Folder levels below the root, 0 = unlimited
+	Workers        int      `json:"workers,omitempty"`        // Files whose tags are read in parallel, 0 = automatic
+	Compilations   string   `json:"compilations,omitempty"`   // CompilationsAuto (default) or CompilationsTags to turn off the folder heuristic
+}
+
+// WithDefaults fills in the default include and exclude patterns and compilation detection
+func (o ScanOptions) WithDefaults() ScanOptions {
+	if o.Include == nil {
+		o.Include = DefaultScanInclude
+	}
+	if o.Exclude == nil {
+		o.Exclude = DefaultScanExclude
+	}
+	if o.Compilations == "" {
+		o.Compilations = CompilationsAuto
+	}
+	return o
+}
+
+// ignoreRule is one parsed gitignore-style pattern
+type ignoreRule struct {
+	base     string   // Directory of the .bmaignore file relative to the root, "" for config patterns
+	segments []string // Pattern split at "/", unanchored patterns start with "**"
+	dirOnly  bool
+	negate   bool
+}
+
+// parseIgnoreRules parses gitignore-style patterns relative to base
+func parseIgnoreRules(base string, patterns []string) []ignoreRule {
+	var rules []ignoreRule
+	for _, pattern := range patterns {
+		pattern = strings.TrimRight(pattern, " \t\r")
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(pattern, "!") {
+			rule.negate = true
+			pattern = pattern[1:]
+		} else if strings.HasPrefix(pattern, `\`) {
+			pattern = pattern[1:] // "\#" and "\!" match a literal first character
+		}
+		if strings.HasSuffix(pattern, "/") {
+			rule.dirOnly = true
+			pattern = strings.TrimRight(pattern, "/")
+		}
+
+		// A pattern without a slash matches at any depth, like in .gitignore
+		anchored := strings.Contains(pattern, "/")
+		pattern = strings.TrimPrefix(pattern, "/")
+		if pattern == "" {
+			continue
+		}
+		rule.segments = strings.Split(pattern, "/")
+		if !anchored {
+			rule.segments = append([]string{"**"}, rule.segments...)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// matches returns true if the rule applies to relPath (slash separated, relative to the root)
+func (r ignoreRule) matches(relPath string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(relPath, r.base+"/") {
+			return false
+		}
+		relPath = strings.TrimPrefix(relPath, r.base+"/")
+	}
+	return matchSegments(r.segments, strings.Split(relPath, "/"))
+}
+
+// matchSegments matches path segments against pattern segments, where "**" matches any number of segments
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+// matchRules returns true if the last rule matching relPath is not negated
+func matchRules(rules []ignoreRule, relPath string, isDir bool) bool {
+	matched := false
+	for _, rule := range rules {
+		if rule.matches(relPath, isDir) {
+			matched = !rule.negate
+		}
+	}
+	return matched
+}
+
+// scanWalker walks a library root applying the scan options
+type scanWalker struct {
+	options ScanOptions
+	include []ignoreRule
+	visited map[string]bool // Real paths of visited folders, for symlink loop detection
+	visit   func(path string) error
+	onError func(path string, err error) // Called for unreadable folders and broken links, may be nil
+	stopErr error                        // First error returned by visit, ends the walk
+}
+
+// WalkMusicFiles calls visit for every file under rootPath that passes the scan options.
+// The walk stops at the first error returned by visit, which is passed on to the caller.
+func WalkMusicFiles(rootPath string, options ScanOptions, visit func(path string) error) error {
+	return walkMusicFiles(rootPath, options, visit, nil)
+}
+
+// walkMusicFiles is WalkMusicFiles with a callback for folders and links that could not be read
+func walkMusicFiles(rootPath string, options ScanOptions, visit func(path string) error, onError func(path string, err error)) error {
+	options = options.WithDefaults()
+
+	lowerInclude := make([]string, len(options.Include))
+	for i, pattern := range options.Include {
+		lowerInclude[i] = strings.ToLower(pattern)
+	}
+
+	walker := &scanWalker{
+		options: options,
+		include: parseIgnoreRules("", lowerInclude),
+		visited: make(map[string]bool),
+		visit:   visit,
+		onError: onError,
+	}
+	return walker.walk(rootPath, "", 0, parseIgnoreRules("", options.Exclude))
+}
+
+// walk scans one folder; rules holds the config excludes plus all .bmaignore rules above it
+func (w *scanWalker) walk(dirPath, relDir string, depth int, rules []ignoreRule) error {
+	if realPath, err := filepath.EvalSymlinks(dirPath); err == nil {
+		if w.visited[realPath] {
+			logger.Warn("Skipping folder scanned already, symlink loop or duplicate link", "path", dirPath)
+			return nil
+		}
+		w.visited[realPath] = true
+	}
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %w", dirPath, err)
+	}
+
+	ignoreRules, err := readIgnoreFile(filepath.Join(dirPath, ignoreFileName), relDir)
+	if err != nil {
+		logger.Warn("Failed to read ignore file", "path", filepath.Join(dirPath, ignoreFileName), "err", err)
+		w.reportError(filepath.Join(dirPath, ignoreFileName), err)
+	}
+	// Copy before appending so sibling folders don't share this folder's rules
+	rules = append(rules[:len(rules):len(rules)], ignoreRules...)
+
+	for _, entry := range entries {
+		name := entry.Name()
+		fullPath := filepath.Join(dirPath, name)
+		relPath := path.Join(relDir, name)
+
+		if !w.options.IncludeHidden && strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		isDir := entry.IsDir()
+		if entry.Type()&fs.ModeSymlink != 0 {
+			info, err := os.Stat(fullPath)
+			if err != nil {
+				logger.Warn("Skipping broken symlink", "path", fullPath)
+				w.reportError(fullPath, err)
+				continue
+			}
+			if info.IsDir() && !w.options.FollowSymlinks {
+				continue
+			}
+			isDir = info.IsDir()
+		}
+
+		if matchRules(rules, relPath, isDir) {
+			continue
+		}
+
+		if isDir {
+			if w.options.MaxDepth > 0 && depth >= w.options.MaxDepth {
+				continue
+			}
+			if err := w.walk(fullPath, relPath, depth+1, rules); err != nil {
+				if w.stopErr != nil {
+					return w.stopErr
+				}
+				logger.Warn("Failed to scan folder", "path", fullPath, "err", err)
+				w.reportError(fullPath, err)
+			}
+		} else if matchRules(w.include, strings.ToLower(relPath), false) {
+			if err := w.visit(fullPath); err != nil {
+				w.stopErr = err
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// reportError passes a read error to the onError callback, if set
+func (w *scanWalker) reportError(path string, err error) {
+	if w.onError != nil {
+		w.onError(path, err)
+	}
+}
+
+// readIgnoreFile parses a .bmaignore file, returning no rules if it does not exist
+func readIgnoreFile(filePath, relDir string) ([]ignoreRule, error) {
+	file, err := os.Open(filePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return parseIgnoreRules(relDir, lines), scanner.Err()
+}
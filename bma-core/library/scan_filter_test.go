@@ -0,0 +1,168 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"a/b", "a/b", true},
+		{"a/b", "a/b/c", false},
+		{"a/*", "a/b", true},
+		{"a/*", "a/b/c", false},
+		{"**/b", "b", true},
+		{"**/b", "a/x/b", true},
+		{"**/b", "a/b/c", false},
+		{"a/**", "a/b/c", true},
+		{"a/**", "a", true},
+		{"a/**/c", "a/c", true},
+		{"a/**/c", "a/x/y/c", true},
+		{"a/**/c", "a/x/y/d", false},
+		{"*.mp3", "song.mp3", true},
+		{"*.mp3", "song.flac", false},
+		{"disc?", "disc1", true},
+		{"[ab]*", "b-side", true},
+		{"**", "", true},
+		{"a", "", false},
+	}
+
+	for _, tt := range tests {
+		var segments []string
+		if tt.path != "" {
+			segments = strings.Split(tt.path, "/")
+		}
+		if got := matchSegments(strings.Split(tt.pattern, "/"), segments); got != tt.want {
+			t.Errorf("matchSegments(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMatchRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		patterns []string
+		path     string
+		isDir    bool
+		want     bool
+	}{
+		{"unanchored name at any depth", "", []string{"Live"}, "Artist/Album/Live", true, true},
+		{"unanchored glob", "", []string{"*.m3u"}, "Artist/list.m3u", false, true},
+		{"anchored pattern only at the root", "", []string{"/Live"}, "Artist/Live", true, false},
+		{"anchored pattern at the root", "", []string{"/Live"}, "Live", true, true},
+		{"pattern with a slash is anchored", "", []string{"Artist/Live"}, "Other/Artist/Live", true, false},
+		{"directory only pattern skips files", "", []string{"Live/"}, "Live", false, false},
+		{"directory only pattern matches folders", "", []string{"Live/"}, "Artist/Live", true, true},
+		{"negation re-includes", "", []string{"*.mp3", "!keep.mp3"}, "keep.mp3", false, false},
+		{"last rule wins", "", []string{"!keep.mp3", "*.mp3"}, "keep.mp3", false, true},
+		{"comment and blank lines", "", []string{"# Live", "", "  "}, "# Live", false, false},
+		{"escaped hash", "", []string{`\#tmp`}, "#tmp", false, true},
+		{"exclude is case sensitive", "", []string{"Live/"}, "live", true, false},
+		{"ignore file applies below its folder", "Artist", []string{"Demos/"}, "Artist/Demos", true, true},
+		{"ignore file does not apply elsewhere", "Artist", []string{"Demos/"}, "Other/Demos", true, false},
+		{"ignore file anchored to its folder", "Artist", []string{"/Demos"}, "Artist/Album/Demos", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseIgnoreRules(tt.base, tt.patterns)
+			if got := matchRules(rules, tt.path, tt.isDir); got != tt.want {
+				t.Errorf("matchRules(%q, %q) = %v, want %v", tt.patterns, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalkMusicFiles(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		"Artist/Album/01.mp3",
+		"Artist/Album/02.MP3",
+		"Artist/Album/cover.jpg",
+		"Artist/Live/01.mp3",
+		"Other/live/01.mp3",
+		"Artist/Demos/01.mp3",
+		"Artist/.hidden/01.mp3",
+		"Other/01.flac",
+		"@eaDir/01.mp3",
+	}
+	for _, file := range files {
+		writeTestFile(t, filepath.Join(root, file), "")
+	}
+	writeTestFile(t, filepath.Join(root, "Artist", ignoreFileName), "Demos/\n")
+
+	tests := []struct {
+		name    string
+		options ScanOptions
+		want    []string
+	}{
+		{
+			name: "defaults",
+			want: []string{"Artist/Album/01.mp3", "Artist/Album/02.MP3", "Artist/Live/01.mp3", "Other/live/01.mp3"},
+		},
+		{
+			name:    "include is case insensitive",
+			options: ScanOptions{Include: []string{"*.Flac"}},
+			want:    []string{"Other/01.flac"},
+		},
+		{
+			name:    "exclude is case sensitive",
+			options: ScanOptions{Exclude: []string{"Live/"}},
+			want:    []string{"@eaDir/01.mp3", "Artist/Album/01.mp3", "Artist/Album/02.MP3", "Other/live/01.mp3"},
+		},
+		{
+			name:    "hidden folders",
+			options: ScanOptions{IncludeHidden: true, Exclude: []string{}},
+			want: []string{"@eaDir/01.mp3", "Artist/.hidden/01.mp3", "Artist/Album/01.mp3", "Artist/Album/02.MP3",
+				"Artist/Live/01.mp3", "Other/live/01.mp3"},
+		},
+		{
+			name:    "max depth",
+			options: ScanOptions{Include: []string{"*.flac"}, MaxDepth: 1},
+			want:    []string{"Other/01.flac"},
+		},
+		{
+			name:    "max depth stops above the albums",
+			options: ScanOptions{MaxDepth: 1},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := WalkMusicFiles(root, tt.options, func(path string) error {
+				rel, _ := filepath.Rel(root, path)
+				got = append(got, filepath.ToSlash(rel))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("WalkMusicFiles() error = %v", err)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WalkMusicFiles() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// writeTestFile creates a file and its folders
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
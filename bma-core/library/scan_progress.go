@@ -0,0 +1,175 @@
+package library
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrScanInProgress is returned when a scan is requested while another one is running
+var ErrScanInProgress = errors.New("a library scan is already running")
+
+// scanProgressInterval limits how often the progress callback is called during a scan
+const scanProgressInterval = 100 * time.Millisecond
+
+// ScanProgress reports how far the running (or last) scan has got
+type ScanProgress struct {
+	Scanning    bool      `json:"scanning"`
+	Discovered  int       `json:"discovered"` // Music files found by the walk so far
+	Processed   int       `json:"processed"`  // Files whose tags have been read, including failures
+	Failed      int       `json:"failed"`
+	CurrentPath string    `json:"currentPath,omitempty"`
+	StartedAt   time.Time `json:"startedAt,omitempty"`
+	Cancelled   bool      `json:"cancelled,omitempty"`
+}
+
+// Fraction returns the share of discovered files processed so far, between 0 and 1
+func (p ScanProgress) Fraction() float64 {
+	if p.Discovered == 0 {
+		return 0
+	}
+	return float64(p.Processed) / float64(p.Discovered)
+}
+
+// scanRun holds the state of one scan between beginScan and runScan
+type scanRun struct {
+	ctx           context.Context
+	cancel        context.CancelFunc
+	done          chan struct{}
+	roots         []LibraryRoot
+	options       ScanOptions
+	previousSongs map[string][]*Song // Songs of the previous scan by root path
+	issues        issueCollector
+}
+
+// SetScanProgressCallback sets the callback for scan progress updates
+func (ml *MusicLibrary) SetScanProgressCallback(callback func(ScanProgress)) {
+	ml.progressMutex.Lock()
+	defer ml.progressMutex.Unlock()
+	ml.onScanProgress = callback
+}
+
+// GetScanProgress returns the progress of the running or last scan (thread-safe)
+func (ml *MusicLibrary) GetScanProgress() ScanProgress {
+	ml.progressMutex.Lock()
+	defer ml.progressMutex.Unlock()
+	return ml.progress
+}
+
+// StartScan starts a scan in the background, returning ErrScanInProgress if one is running
+func (ml *MusicLibrary) StartScan(ctx context.Context) error {
+	run, err := ml.beginScan(ctx)
+	if err != nil || run == nil {
+		return err
+	}
+	go ml.runScan(run)
+	return nil
+}
+
+// CancelScan stops the running scan and waits for it to finish.
+// It returns false if no scan was running.
+func (ml *MusicLibrary) CancelScan() bool {
+	ml.mutex.RLock()
+	run := ml.currentScan
+	ml.mutex.RUnlock()
+
+	if run == nil {
+		return false
+	}
+	run.cancel()
+	<-run.done
+	return true
+}
+
+// beginScan claims the scanner, returning a nil run if there is nothing to scan
+func (ml *MusicLibrary) beginScan(ctx context.Context) (*scanRun, error) {
+	ml.mutex.Lock()
+	if ml.currentScan != nil {
+		ml.mutex.Unlock()
+		return nil, ErrScanInProgress
+	}
+
+	var roots []LibraryRoot
+	for _, root := range ml.Roots {
+		if root.Enabled && root.Path != "" {
+			roots = append(roots, root)
+		}
+	}
+	if len(roots) == 0 {
+		ml.mutex.Unlock()
+		return nil, nil
+	}
+
+	run := &scanRun{
+		done:          make(chan struct{}),
+		roots:         roots,
+		options:       ml.scanOptions,
+		previousSongs: make(map[string][]*Song),
+	}
+	run.ctx, run.cancel = context.WithCancel(ctx)
+	for _, song := range ml.Songs {
+		run.previousSongs[song.RootPath] = append(run.previousSongs[song.RootPath], song)
+	}
+
+	ml.currentScan = run
+	ml.IsScanning = true
+	ml.mutex.Unlock()
+
+	ml.updateScanProgress(true, func(p *ScanProgress) {
+		*p = ScanProgress{Scanning: true, StartedAt: time.Now()}
+	})
+	return run, nil
+}
+
+// endScan releases the scanner claimed by beginScan
+func (ml *MusicLibrary) endScan(run *scanRun) {
+	ml.mutex.Lock()
+	ml.currentScan = nil
+	ml.IsScanning = false
+	ml.mutex.Unlock()
+
+	ml.updateScanProgress(true, func(p *ScanProgress) {
+		p.Scanning = false
+		p.Cancelled = run.ctx.Err() != nil
+		p.CurrentPath = ""
+	})
+
+	run.cancel()
+	close(run.done)
+}
+
+// reportDiscovered counts a file found by the walk
+func (ml *MusicLibrary) reportDiscovered() {
+	ml.updateScanProgress(false, func(p *ScanProgress) {
+		p.Discovered++
+	})
+}
+
+// reportProcessed counts a file whose tags have been read
+func (ml *MusicLibrary) reportProcessed(path string, failed bool) {
+	ml.updateScanProgress(false, func(p *ScanProgress) {
+		p.Processed++
+		if failed {
+			p.Failed++
+		}
+		p.CurrentPath = path
+	})
+}
+
+// updateScanProgress applies update and calls the progress callback, at most every scanProgressInterval unless force is set
+func (ml *MusicLibrary) updateScanProgress(force bool, update func(*ScanProgress)) {
+	ml.progressMutex.Lock()
+	update(&ml.progress)
+	progress := ml.progress
+	callback := ml.onScanProgress
+	notify := force || time.Since(ml.progressReported) >= scanProgressInterval
+	if notify {
+		ml.progressReported = time.Now()
+	}
+	ml.progressMutex.Unlock()
+
+	// Call the callback after releasing the mutex, like the other library callbacks
+	if notify && callback != nil {
+		callback(progress)
+	}
+}
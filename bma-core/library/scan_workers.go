@@ -0,0 +1,126 @@
+package library
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxDefaultScanWorkers caps the default pool size, more workers rarely help on a single disk
+const maxDefaultScanWorkers = 16
+
+// ScanStats summarizes the work done by a library scan
+type ScanStats struct {
+	Files          int           `json:"files"`  // Music files found by the walk
+	Failed         int           `json:"failed"` // Files whose tags could not be read
+	Workers        int           `json:"workers"`
+	Duration       time.Duration `json:"duration"`
+	FilesPerSecond float64       `json:"filesPerSecond"`
+}
+
+// finish records the duration and throughput of a scan that started at start
+func (s *ScanStats) finish(start time.Time) {
+	s.Duration = time.Since(start)
+	if seconds := s.Duration.Seconds(); seconds > 0 {
+		s.FilesPerSecond = float64(s.Files) / seconds
+	}
+}
+
+// scanWorkerCount returns the configured worker count or a default based on the CPU count.
+// Tag parsing mostly waits for I/O, so the default uses more workers than CPUs.
+func scanWorkerCount(options ScanOptions) int {
+	if options.Workers > 0 {
+		return options.Workers
+	}
+	workers := runtime.NumCPU() * 2
+	if workers > maxDefaultScanWorkers {
+		workers = maxDefaultScanWorkers
+	}
+	return workers
+}
+
+// extractSongs walks rootPath and parses the tags of the files it finds on a pool of workers.
+// Songs are returned in walk order, whichever worker finishes first.
+func (ml *MusicLibrary) extractSongs(run *scanRun, rootPath string, stats *ScanStats) ([]*Song, error) {
+	type job struct {
+		index int
+		path  string
+	}
+	type result struct {
+		index int
+		song  *Song
+	}
+
+	ctx := run.ctx
+	workers := scanWorkerCount(run.options)
+	jobs := make(chan job, workers*4)
+	results := make(chan result, workers*4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if ctx.Err() != nil {
+					continue // Drain the queue without reading tags
+				}
+				song, err := NewSongFromFile(j.path)
+				if err != nil {
+					logger.Warn("Failed to read music file", "path", j.path, "err", err)
+					run.issues.add(LibraryIssue{Kind: IssueUnreadable, Path: j.path, Detail: err.Error()})
+					song = nil
+				}
+				ml.reportProcessed(j.path, song == nil)
+				results <- result{index: j.index, song: song}
+			}
+		}()
+	}
+
+	// Walk the folders while the workers parse tags
+	var walkErr error
+	go func() {
+		index := 0
+		walkErr = walkMusicFiles(rootPath, run.options, func(path string) error {
+			ml.reportDiscovered()
+			select {
+			case jobs <- job{index: index, path: path}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			index++
+			return nil
+		}, func(path string, err error) {
+			run.issues.add(LibraryIssue{Kind: IssueUnreadable, Path: path, Detail: err.Error()})
+		})
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	var collected []result
+	for r := range results {
+		collected = append(collected, r)
+	}
+	sort.Slice(collected, func(i, j int) bool {
+		return collected[i].index < collected[j].index
+	})
+
+	songs := make([]*Song, 0, len(collected))
+	for _, r := range collected {
+		if r.song == nil {
+			stats.Failed++
+			continue
+		}
+		songs = append(songs, r.song)
+	}
+	stats.Files += len(collected)
+	if workers > stats.Workers {
+		stats.Workers = workers
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return songs, walkErr
+}
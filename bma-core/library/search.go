@@ -0,0 +1,415 @@
+package library
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultSearchLimit is the number of results per kind returned when no limit is given
+const DefaultSearchLimit = 20
+
+// searchField is the part of a song, album or artist a term was found in
+type searchField uint8
+
+const (
+	fieldTitle searchField = iota // Song title, album name or artist name
+	fieldArtist
+	fieldAlbum
+	fieldYear
+	fieldGenre
+	fieldComposer
+)
+
+// fieldWeights ranks matches in titles above matches in other fields
+var fieldWeights = map[searchField]float64{
+	fieldTitle:    3,
+	fieldArtist:   2,
+	fieldAlbum:    1.5,
+	fieldYear:     1,
+	fieldGenre:    1,
+	fieldComposer: 0.5,
+}
+
+// searchQualifiers maps query qualifiers such as "artist:" to the fields they search
+var searchQualifiers = map[string][]searchField{
+	"artist": {fieldArtist},
+	"album":  {fieldAlbum},
+	"year":   {fieldYear},
+	"genre":  {fieldGenre},
+	"title":  {fieldTitle},
+}
+
+// foldedLetters spells out letters that Unicode does not decompose into a base letter and an accent
+var foldedLetters = strings.NewReplacer("ø", "o", "æ", "ae", "œ", "oe", "ł", "l", "đ", "d", "ð", "d", "þ", "th", "ı", "i")
+
+// NormalizeSearchText removes accents and folds case, so "Beyoncé" and "BEYONCE" both become "beyonce"
+func NormalizeSearchText(text string) string {
+	// Transformers keep state, so each call builds its own chain
+	stripAccents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(transform.Chain(stripAccents, cases.Fold()), text)
+	if err != nil {
+		folded = strings.ToLower(text)
+	}
+	return foldedLetters.Replace(folded)
+}
+
+// searchTerms splits text into normalized words, dropping apostrophes so "Don't" matches "dont"
+func searchTerms(text string) []string {
+	text = strings.NewReplacer("'", "", "’", "").Replace(NormalizeSearchText(text))
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// searchKind says whether a document is a song, album or artist
+type searchKind uint8
+
+const (
+	kindSong searchKind = iota
+	kindAlbum
+	kindArtist
+)
+
+// searchDoc identifies a song, album or artist by its position in the library
+type searchDoc struct {
+	kind  searchKind
+	index int
+}
+
+// posting records that a term appears in one field of a document
+type posting struct {
+	doc   searchDoc
+	field searchField
+}
+
+// searchIndex is an inverted index over the songs, albums and artists of one library state.
+// It is rebuilt on every library change and never modified afterwards, so searches need no lock.
+type searchIndex struct {
+	postings map[string][]posting
+	terms    []string // Sorted, for prefix lookups
+	songs    []*Song
+	albums   []*Album
+	artists  []*Artist
+}
+
+// buildSearchIndex indexes songs, albums and artists
+func buildSearchIndex(songs []*Song, albums []*Album, artists []*Artist) *searchIndex {
+	index := &searchIndex{
+		postings: make(map[string][]posting),
+		songs:    songs,
+		albums:   albums,
+		artists:  artists,
+	}
+
+	for i, song := range songs {
+		doc := searchDoc{kindSong, i}
+		index.add(doc, fieldTitle, song.Title)
+		index.add(doc, fieldArtist, song.Artist)
+		if !song.Compilation {
+			index.add(doc, fieldArtist, song.AlbumArtist)
+		}
+		index.add(doc, fieldAlbum, song.Album)
+		index.addYear(doc, song.Year)
+		index.add(doc, fieldGenre, song.Genre)
+		index.add(doc, fieldComposer, song.Composer)
+	}
+	for i, album := range albums {
+		doc := searchDoc{kindAlbum, i}
+		index.add(doc, fieldTitle, album.Name)
+		index.add(doc, fieldAlbum, album.Name)
+		index.add(doc, fieldArtist, album.Artist)
+		index.addYear(doc, album.Year)
+		index.add(doc, fieldGenre, album.Genre)
+	}
+	for i, artist := range artists {
+		doc := searchDoc{kindArtist, i}
+		index.add(doc, fieldTitle, artist.Name)
+		index.add(doc, fieldArtist, artist.Name)
+	}
+
+	index.terms = make([]string, 0, len(index.postings))
+	for term := range index.postings {
+		index.terms = append(index.terms, term)
+	}
+	sort.Strings(index.terms)
+	return index
+}
+
+// add indexes the words of text in one field of a document
+func (idx *searchIndex) add(doc searchDoc, field searchField, text string) {
+	for _, term := range searchTerms(text) {
+		list := idx.postings[term]
+		if n := len(list); n > 0 && list[n-1] == (posting{doc, field}) {
+			continue // Word repeated in the same field
+		}
+		idx.postings[term] = append(list, posting{doc, field})
+	}
+}
+
+// addYear indexes a year, if the document has one
+func (idx *searchIndex) addYear(doc searchDoc, year int) {
+	if year > 0 {
+		idx.add(doc, fieldYear, strconv.Itoa(year))
+	}
+}
+
+// SearchResults holds the best matches of a search, best first
+type SearchResults struct {
+	Songs   []*Song
+	Albums  []*Album
+	Artists []*Artist
+}
+
+// queryTerm is one word of a search query, optionally limited to some fields
+type queryTerm struct {
+	text   string
+	fields []searchField // nil searches all fields
+}
+
+// parseSearchQuery splits a query into terms. "artist:abba", `album:"abbey road"` and
+// "year:1990-1999" limit the following word, quoted words or year range to one field.
+func parseSearchQuery(query string) []queryTerm {
+	var terms []queryTerm
+	for query = strings.TrimSpace(query); query != ""; query = strings.TrimSpace(query) {
+		var fields []searchField
+		if colon := strings.IndexByte(query, ':'); colon > 0 && !strings.ContainsAny(query[:colon], " \t\"") {
+			if qualified, ok := searchQualifiers[strings.ToLower(query[:colon])]; ok {
+				fields = qualified
+				query = query[colon+1:]
+			}
+		}
+
+		// Take a quoted phrase or a single word
+		var value string
+		if strings.HasPrefix(query, `"`) {
+			end := strings.IndexByte(query[1:], '"')
+			if end < 0 {
+				value, query = query[1:], ""
+			} else {
+				value, query = query[1:end+1], query[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(query, unicode.IsSpace)
+			if end < 0 {
+				end = len(query)
+			}
+			value, query = query[:end], query[end:]
+		}
+
+		// "year:" or `artist:""` without a value would prefix match every word of the field
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		if len(fields) == 1 && fields[0] == fieldYear {
+			terms = append(terms, yearTerms(value)...)
+			continue
+		}
+		for _, text := range searchTerms(value) {
+			terms = append(terms, queryTerm{text: text, fields: fields})
+		}
+	}
+	return terms
+}
+
+// yearTerms turns "1997", "199" or "1990-1999" into a year query term
+func yearTerms(value string) []queryTerm {
+	from, to, isRange := strings.Cut(strings.ReplaceAll(value, "..", "-"), "-")
+	first, err1 := strconv.Atoi(from)
+	last, err2 := strconv.Atoi(to)
+	if !isRange || err1 != nil || err2 != nil || last < first || last-first > 200 {
+		return []queryTerm{{text: value, fields: []searchField{fieldYear}}}
+	}
+
+	// A range matches any of its years, so it is one term with alternatives
+	years := make([]string, 0, last-first+1)
+	for year := first; year <= last; year++ {
+		years = append(years, strconv.Itoa(year))
+	}
+	return []queryTerm{{text: strings.Join(years, "|"), fields: []searchField{fieldYear}}}
+}
+
+// maxTypos returns how many typos a query word of this length may contain, none for numbers
+func maxTypos(word string) int {
+	if strings.IndexFunc(word, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+		return 0
+	}
+	switch n := len([]rune(word)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// matchTerm returns the index terms that match a query word and how well, 1 for an exact match
+func (idx *searchIndex) matchTerm(term queryTerm) map[string]float64 {
+	matches := make(map[string]float64)
+	exactOnly := len(term.fields) == 1 && term.fields[0] == fieldYear
+
+	for _, text := range strings.Split(term.text, "|") {
+		if _, ok := idx.postings[text]; ok {
+			matches[text] = 1
+		}
+
+		// Prefix matches, so "beat" finds "beatles" while typing
+		start := sort.SearchStrings(idx.terms, text)
+		for _, candidate := range idx.terms[start:] {
+			if !strings.HasPrefix(candidate, text) {
+				break
+			}
+			if _, ok := matches[candidate]; !ok {
+				matches[candidate] = 0.5 + 0.3*float64(len(text))/float64(len(candidate))
+			}
+		}
+
+		typos := maxTypos(text)
+		if exactOnly || typos == 0 {
+			continue
+		}
+		for _, candidate := range idx.terms {
+			if _, ok := matches[candidate]; ok {
+				continue
+			}
+			if distance := editDistance(text, candidate, typos); distance <= typos {
+				matches[candidate] = 0.5 - 0.15*float64(distance)
+			}
+		}
+	}
+	return matches
+}
+
+// search returns the songs, albums and artists that match every word of the query, best first
+func (idx *searchIndex) search(query string, limit int) SearchResults {
+	results := SearchResults{Songs: []*Song{}, Albums: []*Album{}, Artists: []*Artist{}}
+	terms := parseSearchQuery(query)
+	if len(terms) == 0 {
+		return results
+	}
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	// Every query word must match somewhere in a document; its best match counts
+	var scores map[searchDoc]float64
+	for _, term := range terms {
+		termScores := make(map[searchDoc]float64)
+		for text, quality := range idx.matchTerm(term) {
+			for _, p := range idx.postings[text] {
+				if !fieldAllowed(p.field, term.fields) {
+					continue
+				}
+				if score := quality * fieldWeights[p.field]; score > termScores[p.doc] {
+					termScores[p.doc] = score
+				}
+			}
+		}
+
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for doc, score := range scores {
+			if termScore, ok := termScores[doc]; ok {
+				scores[doc] = score + termScore
+			} else {
+				delete(scores, doc)
+			}
+		}
+	}
+
+	ranked := make([]searchDoc, 0, len(scores))
+	for doc := range scores {
+		ranked = append(ranked, doc)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if scores[ranked[i]] != scores[ranked[j]] {
+			return scores[ranked[i]] > scores[ranked[j]]
+		}
+		if ranked[i].kind != ranked[j].kind {
+			return ranked[i].kind < ranked[j].kind
+		}
+		return ranked[i].index < ranked[j].index // Library order on a tie
+	})
+
+	for _, doc := range ranked {
+		switch {
+		case doc.kind == kindSong && len(results.Songs) < limit:
+			results.Songs = append(results.Songs, idx.songs[doc.index])
+		case doc.kind == kindAlbum && len(results.Albums) < limit:
+			results.Albums = append(results.Albums, idx.albums[doc.index])
+		case doc.kind == kindArtist && len(results.Artists) < limit:
+			results.Artists = append(results.Artists, idx.artists[doc.index])
+		}
+	}
+	return results
+}
+
+// fieldAllowed reports whether field is one of fields, or fields is empty
+func fieldAllowed(field searchField, fields []searchField) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	for _, allowed := range fields {
+		if field == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance returns the number of inserted, deleted, replaced or swapped letters between a and b.
+// It gives up and returns maxDistance+1 once the distance is known to exceed maxDistance.
+func editDistance(a, b string, maxDistance int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > maxDistance || -diff > maxDistance {
+		return maxDistance + 1
+	}
+
+	previous2 := make([]int, len(rb)+1)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			best := previous[j-1] + cost
+			if d := previous[j] + 1; d < best {
+				best = d
+			}
+			if d := current[j-1] + 1; d < best {
+				best = d
+			}
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				if d := previous2[j-2] + 1; d < best {
+					best = d
+				}
+			}
+			current[j] = best
+			if best < rowMin {
+				rowMin = best
+			}
+		}
+		if rowMin > maxDistance {
+			return maxDistance + 1
+		}
+		previous2, previous, current = previous, current, previous2
+	}
+	return previous[len(rb)]
+}
@@ -0,0 +1,125 @@
+package library
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b        string
+		maxDistance int
+		want        int
+	}{
+		{"beatles", "beatles", 2, 0},
+		{"beatles", "beatle", 2, 1},   // Deletion
+		{"beatles", "beatless", 2, 1}, // Insertion
+		{"beatles", "beetles", 2, 1},  // Replacement
+		{"beatles", "baetles", 2, 1},  // Swapped letters
+		{"beatles", "bxatlxs", 2, 2},
+		{"beatles", "stones", 2, 3}, // Gives up at maxDistance+1
+		{"abba", "abbafoo", 2, 3},   // Length difference alone is too large
+		{"björk", "bjork", 1, 1},    // Runes, not bytes
+		{"", "abc", 3, 3},
+		{"", "", 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b, tt.maxDistance); got != tt.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.maxDistance, got, tt.want)
+		}
+	}
+}
+
+func TestMaxTypos(t *testing.T) {
+	tests := []struct {
+		word string
+		want int
+	}{
+		{"abc", 0},
+		{"abba", 1},
+		{"beatles", 1},
+		{"radiohead", 2},
+		{"19971997", 0}, // Numbers must match exactly
+		{"blur", 1},
+	}
+
+	for _, tt := range tests {
+		if got := maxTypos(tt.word); got != tt.want {
+			t.Errorf("maxTypos(%q) = %d, want %d", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	artist := []searchField{fieldArtist}
+	album := []searchField{fieldAlbum}
+	year := []searchField{fieldYear}
+
+	tests := []struct {
+		query string
+		want  []queryTerm
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"Abbey Road", []queryTerm{{"abbey", nil}, {"road", nil}}},
+		{"Beyoncé", []queryTerm{{"beyonce", nil}}},
+		{"Don't", []queryTerm{{"dont", nil}}},
+		{"artist:abba waterloo", []queryTerm{{"abba", artist}, {"waterloo", nil}}},
+		{"ARTIST:abba", []queryTerm{{"abba", artist}}},
+		{`album:"abbey road" beatles`, []queryTerm{{"abbey", album}, {"road", album}, {"beatles", nil}}},
+		{`album:"abbey road`, []queryTerm{{"abbey", album}, {"road", album}}}, // Unclosed quote
+		{"year:1997", []queryTerm{{"1997", year}}},
+		{"year:1990-1992", []queryTerm{{"1990|1991|1992", year}}},
+		{"year:1990..1991", []queryTerm{{"1990|1991", year}}},
+		{"year:1999-1990", []queryTerm{{"1999-1990", year}}}, // Backwards range
+		{"unknown:word", []queryTerm{{"unknown", nil}, {"word", nil}}},
+		{"year:", nil},
+		{"year: beatles", []queryTerm{{"beatles", nil}}},
+		{`artist:""`, nil},
+		{"genre:", nil},
+		{"artist: abba", []queryTerm{{"abba", nil}}},
+	}
+
+	for _, tt := range tests {
+		if got := parseSearchQuery(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSearchQuery(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	songs := []*Song{
+		{Title: "Waterloo", Artist: "ABBA", Album: "Waterloo", Year: 1974},
+		{Title: "Come Together", Artist: "The Beatles", Album: "Abbey Road", Year: 1969},
+		{Title: "Crazy in Love", Artist: "Beyoncé", Album: "Dangerously in Love", Year: 2003, Genre: "R&B"},
+	}
+	index := buildSearchIndex(songs, nil, nil)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"waterloo", []string{"Waterloo"}},
+		{"beatls", []string{"Come Together"}}, // Typo
+		{"beyonce", []string{"Crazy in Love"}},
+		{"abb", []string{"Waterloo", "Come Together"}}, // Prefix of ABBA and Abbey
+		{"artist:abba", []string{"Waterloo"}},
+		{"year:1960-1970", []string{"Come Together"}},
+		{"year:197", []string{"Waterloo"}}, // The 1970s
+		{"year:", nil},
+		{"year: love", []string{"Crazy in Love"}},
+		{"genre:", nil},
+		{"abba beatles", nil}, // Every word must match
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, song := range index.search(tt.query, 0).Songs {
+			got = append(got, song.Title)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("search(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
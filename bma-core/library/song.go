@@ -1,8 +1,7 @@
-package models
+package library
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -22,25 +21,37 @@ type Song struct {
 	Title           string        `json:"title"`
 	Artist          string        `json:"artist,omitempty"`
 	Album           string        `json:"album,omitempty"`
+	AlbumArtist     string        `json:"albumArtist,omitempty"`
 	Duration        time.Duration `json:"duration,omitempty"`
 	ParentDirectory string        `json:"parentDirectory"`
+	RootPath        string        `json:"rootPath,omitempty"`  // Library root the song was found in
+	RootLabel       string        `json:"rootLabel,omitempty"`
+	Offline         bool          `json:"offline,omitempty"`       // Root was unavailable at the last scan
+	OfflineReason   string        `json:"offlineReason,omitempty"`
 	TrackNumber     int           `json:"trackNumber,omitempty"`
+	TrackTotal      int           `json:"trackTotal,omitempty"`
+	DiscNumber      int           `json:"discNumber,omitempty"`
+	DiscTotal       int           `json:"discTotal,omitempty"`
+	Year            int           `json:"year,omitempty"`
+	Genre           string        `json:"genre,omitempty"`
+	Composer        string        `json:"composer,omitempty"`
+	Compilation     bool          `json:"compilation,omitempty"` // Part of a compilation, from tags or the folder heuristic
 	ArtworkData     []byte        `json:"-"` // Exclude from JSON, store artwork bytes
+	
+	metadataErr       error // Why the ID3 tags could not be read, nil if they were
+	titleFromFilename bool
+	compilationTag    bool // Compilation flag or "Various Artists" album artist in the tags
 }
 
 // NewSongFromFile creates a Song from an MP3 file path with full metadata extraction
 func NewSongFromFile(filePath string) (*Song, error) {
-	log.Printf("🎵 [DEBUG] Creating song from file: %s", filePath)
-	
-	// Generate unique ID
-	id := uuid.New()
+	// ID derived from the path, so it stays the same across scans
+	id := songID(filePath)
 	
 	// Extract basic file info
 	filename := filepath.Base(filePath)
 	parentDir := filepath.Dir(filePath)
 	
-	log.Printf("🎵 [DEBUG] File info - name: %s, dir: %s", filename, parentDir)
-	
 	// Initialize song with basic info
 	song := &Song{
 		ID:              id,
@@ -50,68 +61,70 @@ func NewSongFromFile(filePath string) (*Song, error) {
 	}
 	
 	// Extract metadata from MP3 file
-	log.Printf("🎵 [DEBUG] Attempting MP3 metadata extraction")
 	if err := song.extractMP3Metadata(); err != nil {
-		log.Printf("⚠️ [DEBUG] MP3 metadata extraction failed: %v, falling back to filename", err)
+		logger.Debug("Tags unreadable, using the filename", "path", filePath, "err", err)
 		// If MP3 metadata extraction fails, fall back to filename parsing
+		song.metadataErr = err
+		song.titleFromFilename = true
 		song.extractMetadataFromFilename()
-	} else {
-		log.Printf("🎵 [DEBUG] MP3 metadata extraction successful")
 	}
 	
 	// Apply folder-based inference if metadata is missing
-	log.Printf("🎵 [DEBUG] Applying folder inference")
 	song.applyFolderInference()
 	
-	log.Printf("🎵 [DEBUG] Song created successfully: %s by %s", song.Title, song.Artist)
+	logger.Debug("Song read", "path", filePath, "title", song.Title, "artist", song.Artist, "album", song.Album)
 	
 	return song, nil
 }
 
 // extractMP3Metadata extracts metadata from MP3 ID3 tags using github.com/dhowden/tag
 func (s *Song) extractMP3Metadata() error {
-	log.Printf("🎵 [DEBUG] Opening file for metadata: %s", s.Path)
-	
 	file, err := os.Open(s.Path)
 	if err != nil {
 		return fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 	
-	log.Printf("🎵 [DEBUG] Reading tags from file")
-	
 	metadata, err := tag.ReadFrom(file)
 	if err != nil {
 		return fmt.Errorf("failed to read tags: %w", err)
 	}
 	
-	log.Printf("🎵 [DEBUG] Processing metadata tags")
-	
 	// Extract basic metadata
 	if title := metadata.Title(); title != "" {
 		s.Title = title
-		log.Printf("🎵 [DEBUG] Found title: %s", title)
 	} else {
 		// Fallback to filename without extension
 		s.Title = strings.TrimSuffix(s.Filename, filepath.Ext(s.Filename))
-		log.Printf("🎵 [DEBUG] No title found, using filename: %s", s.Title)
+		s.titleFromFilename = true
 	}
 	
 	if artist := metadata.Artist(); artist != "" {
 		s.Artist = artist
-		log.Printf("🎵 [DEBUG] Found artist: %s", artist)
 	}
 	
 	if album := metadata.Album(); album != "" {
 		s.Album = album
-		log.Printf("🎵 [DEBUG] Found album: %s", album)
 	}
 	
-	// Extract track number
-	if track, _ := metadata.Track(); track != 0 {
+	if albumArtist := metadata.AlbumArtist(); albumArtist != "" {
+		s.AlbumArtist = albumArtist
+	}
+	
+	// Extract track and disc numbers ("3/12" tags give both number and total)
+	if track, total := metadata.Track(); track != 0 {
 		s.TrackNumber = track
-		log.Printf("🎵 [DEBUG] Found track number: %d", track)
+		s.TrackTotal = total
 	}
+	if disc, total := metadata.Disc(); disc != 0 {
+		s.DiscNumber = disc
+		s.DiscTotal = total
+	}
+	
+	s.Year = metadata.Year()
+	s.Genre = strings.TrimSpace(metadata.Genre())
+	s.Composer = strings.TrimSpace(metadata.Composer())
+	s.compilationTag = hasCompilationFlag(metadata.Raw())
 	
 	// Duration extraction is not available in this tag library
 	// We'll leave duration as 0 for now
@@ -119,10 +132,8 @@ func (s *Song) extractMP3Metadata() error {
 	// Extract artwork
 	if picture := metadata.Picture(); picture != nil {
 		s.ArtworkData = picture.Data
-		log.Printf("🎵 [DEBUG] Found artwork: %d bytes", len(picture.Data))
 	}
 	
-	log.Printf("🎵 [DEBUG] Metadata extraction completed")
 	return nil
 }
 
@@ -231,6 +242,22 @@ func (s *Song) InferredArtist() string {
 	return ""
 }
 
+// AlbumArtistOrArtist returns the album artist, falling back to the track artist
+func (s *Song) AlbumArtistOrArtist() string {
+	if s.AlbumArtist != "" {
+		return s.AlbumArtist
+	}
+	return s.Artist
+}
+
+// Disc returns the disc number, treating untagged songs as disc 1
+func (s *Song) Disc() int {
+	if s.DiscNumber > 0 {
+		return s.DiscNumber
+	}
+	return 1
+}
+
 // DisplayTitle returns a cleaned up title for display (removes track numbers)
 func (s *Song) DisplayTitle() string {
 	// Clean up track numbers for display
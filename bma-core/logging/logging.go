@@ -0,0 +1,167 @@
+// Package logging sets up the leveled, structured logger shared by bma-cli and the BMA desktop app.
+// Each part of the program logs through its Component logger, and request handlers pass the
+// request context so their log lines carry the request ID.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Output formats
+const (
+	FormatText = "text" // key=value lines, the default
+	FormatJSON = "json" // One JSON object per line, for log shippers
+)
+
+// Environment variables overriding the configured level and format
+const (
+	LevelEnv  = "BMA_LOG_LEVEL"
+	FormatEnv = "BMA_LOG_FORMAT"
+)
+
+// Options configures the logger
+type Options struct {
+	Level  string    // debug, info, warn or error, "" for info
+	Format string    // text or json, "" for text
+	Output io.Writer // nil for stderr
+}
+
+// ApplyEnv replaces the level and format with those set in BMA_LOG_LEVEL and BMA_LOG_FORMAT
+func (o *Options) ApplyEnv() {
+	if value := os.Getenv(LevelEnv); value != "" {
+		o.Level = value
+	}
+	if value := os.Getenv(FormatEnv); value != "" {
+		o.Format = value
+	}
+}
+
+// output is the handler installed by Setup
+type output struct {
+	handler slog.Handler
+}
+
+var (
+	level   = new(slog.LevelVar)
+	current atomic.Pointer[output]
+)
+
+func init() {
+	current.Store(&output{slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})})
+}
+
+// Setup installs the logger. Component loggers and the standard log package, which third
+// party code uses, write through it from then on.
+func Setup(options Options) error {
+	minLevel, err := ParseLevel(options.Level)
+	if err != nil {
+		return err
+	}
+	format, err := ParseFormat(options.Format)
+	if err != nil {
+		return err
+	}
+	out := options.Output
+	if out == nil {
+		out = os.Stderr
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if format == FormatJSON {
+		handler = slog.NewJSONHandler(out, handlerOptions)
+	} else {
+		handler = slog.NewTextHandler(out, handlerOptions)
+	}
+
+	level.Set(minLevel)
+	current.Store(&output{handler})
+	slog.SetDefault(slog.New(componentHandler{}))
+	return nil
+}
+
+// ParseLevel converts a level name, "" is info
+func ParseLevel(name string) (slog.Level, error) {
+	var l slog.Level
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := l.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q (use debug, info, warn or error)", name)
+	}
+	return l, nil
+}
+
+// ParseFormat validates a format name, "" is text
+func ParseFormat(name string) (string, error) {
+	switch strings.ToLower(name) {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q (use text or json)", name)
+	}
+}
+
+// Component returns the logger of a part of the program, e.g. "library" or "api"
+func Component(name string) *slog.Logger {
+	return slog.New(componentHandler{}).With("component", name)
+}
+
+// requestIDKey stores the request ID in a context
+type requestIDKey struct{}
+
+// WithRequestID returns a context whose log lines carry the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID of a context, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// componentHandler writes through the handler installed by Setup, so loggers created
+// before Setup, like the package level Component loggers, follow its level and format
+type componentHandler struct {
+	wrap []func(slog.Handler) slog.Handler // WithAttrs and WithGroup calls, in order
+}
+
+func (h componentHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= level.Level()
+}
+
+func (h componentHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+
+	handler := current.Load().handler
+	for _, wrap := range h.wrap {
+		handler = wrap(handler)
+	}
+	return handler.Handle(ctx, record)
+}
+
+func (h componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h componentHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+// with returns a copy of h with another wrapping step
+func (h componentHandler) with(wrap func(slog.Handler) slog.Handler) componentHandler {
+	steps := make([]func(slog.Handler) slog.Handler, len(h.wrap), len(h.wrap)+1)
+	copy(steps, h.wrap)
+	return componentHandler{wrap: append(steps, wrap)}
+}
@@ -0,0 +1,372 @@
+// Package metrics keeps the counters, gauges and histograms of a server and writes them in the
+// Prometheus text format, so bma-cli and the BMA desktop app can be scraped without the Prometheus client.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ContentType is the media type of the Prometheus text format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DurationBuckets are the histogram upper bounds, in seconds, used for request durations
+var DurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry holds the metrics of a server. Registering a name again returns the metric
+// registered first, so a server can rebuild its handlers without losing the counts.
+type Registry struct {
+	mutex    sync.Mutex
+	families map[string]*family
+}
+
+// family is one metric name with its help text and samples
+type family struct {
+	help   string
+	kind   string // counter, gauge or histogram
+	metric interface{}
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// register returns the metric registered as name, or stores the one create returns
+func (r *Registry) register(name, help, kind string, create func() interface{}) interface{} {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if existing, ok := r.families[name]; ok {
+		if existing.kind != kind {
+			panic(fmt.Sprintf("metrics: %s is already registered as a %s", name, existing.kind))
+		}
+		return existing.metric
+	}
+
+	metric := create()
+	r.families[name] = &family{help: help, kind: kind, metric: metric}
+	return metric
+}
+
+// Counter registers a counter without labels
+func (r *Registry) Counter(name, help string) *Counter {
+	return r.CounterVec(name, help).With()
+}
+
+// CounterVec registers a counter with one series per combination of label values
+func (r *Registry) CounterVec(name, help string, labels ...string) *CounterVec {
+	return r.register(name, help, "counter", func() interface{} {
+		return &CounterVec{newSeriesSet(labels, func() *Counter { return &Counter{} })}
+	}).(*CounterVec)
+}
+
+// Gauge registers a gauge without labels
+func (r *Registry) Gauge(name, help string) *Gauge {
+	return r.register(name, help, "gauge", func() interface{} { return &Gauge{} }).(*Gauge)
+}
+
+// GaugeFunc registers a gauge whose value is read from value at every scrape.
+// Registering the name again replaces the function.
+func (r *Registry) GaugeFunc(name, help string, value func() float64) {
+	gauge := r.register(name, help, "gauge", func() interface{} { return &gaugeFunc{} })
+	gaugeFunc, ok := gauge.(*gaugeFunc)
+	if !ok {
+		panic(fmt.Sprintf("metrics: %s is already registered as a gauge without a function", name))
+	}
+	gaugeFunc.value.Store(&value)
+}
+
+// HistogramVec registers a histogram with one series per combination of label values
+func (r *Registry) HistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return r.register(name, help, "histogram", func() interface{} {
+		return &HistogramVec{newSeriesSet(labels, func() *Histogram { return newHistogram(buckets) })}
+	}).(*HistogramVec)
+}
+
+// Handler serves the registry in the Prometheus text format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		r.Write(w)
+	})
+}
+
+// Write writes all metrics in the Prometheus text format, sorted by name
+func (r *Registry) Write(w io.Writer) error {
+	r.mutex.Lock()
+	names := make([]string, 0, len(r.families))
+	families := make(map[string]*family, len(r.families))
+	for name, f := range r.families {
+		names = append(names, name)
+		families[name] = f
+	}
+	r.mutex.Unlock()
+	sort.Strings(names)
+
+	out := bufio.NewWriter(w)
+	for _, name := range names {
+		f := families[name]
+		fmt.Fprintf(out, "# HELP %s %s\n", name, escapeHelp(f.help))
+		fmt.Fprintf(out, "# TYPE %s %s\n", name, f.kind)
+		switch metric := f.metric.(type) {
+		case *CounterVec:
+			metric.series.each(func(labels string, counter *Counter) {
+				writeSample(out, name, labels, counter.Value())
+			})
+		case *Gauge:
+			writeSample(out, name, "", metric.Value())
+		case *gaugeFunc:
+			writeSample(out, name, "", (*metric.value.Load())())
+		case *HistogramVec:
+			metric.series.each(func(labels string, histogram *Histogram) {
+				histogram.write(out, name, labels)
+			})
+		}
+	}
+	return out.Flush()
+}
+
+// Bool converts a state to the 1 or 0 of a gauge
+func Bool(state bool) float64 {
+	if state {
+		return 1
+	}
+	return 0
+}
+
+// Counter is a value that only goes up
+type Counter struct {
+	bits atomic.Uint64
+}
+
+// Add increases the counter by delta, which must not be negative
+func (c *Counter) Add(delta float64) {
+	addFloat(&c.bits, delta)
+}
+
+// Inc increases the counter by one
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Value returns the current count
+func (c *Counter) Value() float64 {
+	return math.Float64frombits(c.bits.Load())
+}
+
+// CounterVec is a counter split by label values
+type CounterVec struct {
+	series *seriesSet[*Counter]
+}
+
+// With returns the counter of the label values, in the order the labels were registered
+func (v *CounterVec) With(values ...string) *Counter {
+	return v.series.with(values)
+}
+
+// Gauge is a value that goes up and down
+type Gauge struct {
+	bits atomic.Uint64
+}
+
+// Set replaces the value
+func (g *Gauge) Set(value float64) {
+	g.bits.Store(math.Float64bits(value))
+}
+
+// Add changes the value by delta
+func (g *Gauge) Add(delta float64) {
+	addFloat(&g.bits, delta)
+}
+
+// Inc increases the value by one
+func (g *Gauge) Inc() {
+	g.Add(1)
+}
+
+// Dec decreases the value by one
+func (g *Gauge) Dec() {
+	g.Add(-1)
+}
+
+// Value returns the current value
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(g.bits.Load())
+}
+
+// gaugeFunc is a gauge read from a function at scrape time
+type gaugeFunc struct {
+	value atomic.Pointer[func() float64]
+}
+
+// Histogram counts observations in buckets
+type Histogram struct {
+	mutex   sync.Mutex
+	bounds  []float64 // Upper bounds, ascending
+	buckets []uint64  // Observations per bucket, the last one is +Inf
+	sum     float64
+	count   uint64
+}
+
+// newHistogram creates a histogram with the given upper bounds
+func newHistogram(bounds []float64) *Histogram {
+	sorted := append([]float64(nil), bounds...)
+	sort.Float64s(sorted)
+	return &Histogram{bounds: sorted, buckets: make([]uint64, len(sorted)+1)}
+}
+
+// Observe records one value
+func (h *Histogram) Observe(value float64) {
+	i := sort.SearchFloat64s(h.bounds, value)
+
+	h.mutex.Lock()
+	h.buckets[i]++
+	h.sum += value
+	h.count++
+	h.mutex.Unlock()
+}
+
+// write writes the cumulative buckets, the sum and the count
+func (h *Histogram) write(out *bufio.Writer, name, labels string) {
+	h.mutex.Lock()
+	buckets := append([]uint64(nil), h.buckets...)
+	sum, count := h.sum, h.count
+	h.mutex.Unlock()
+
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += buckets[i]
+		writeSample(out, name+"_bucket", joinLabels(labels, "le", formatFloat(bound)), float64(cumulative))
+	}
+	writeSample(out, name+"_bucket", joinLabels(labels, "le", "+Inf"), float64(count))
+	writeSample(out, name+"_sum", labels, sum)
+	writeSample(out, name+"_count", labels, float64(count))
+}
+
+// HistogramVec is a histogram split by label values
+type HistogramVec struct {
+	series *seriesSet[*Histogram]
+}
+
+// With returns the histogram of the label values, in the order the labels were registered
+func (v *HistogramVec) With(values ...string) *Histogram {
+	return v.series.with(values)
+}
+
+// seriesSet holds one metric per combination of label values
+type seriesSet[T any] struct {
+	labels []string
+	create func() T
+	mutex  sync.RWMutex
+	series map[string]T // By formatted labels, e.g. method="GET",route="/songs"
+}
+
+func newSeriesSet[T any](labels []string, create func() T) *seriesSet[T] {
+	return &seriesSet[T]{labels: labels, create: create, series: make(map[string]T)}
+}
+
+// with returns the metric of the label values, creating it on first use
+func (s *seriesSet[T]) with(values []string) T {
+	if len(values) != len(s.labels) {
+		panic(fmt.Sprintf("metrics: got %d label values for labels %v", len(values), s.labels))
+	}
+
+	pairs := make([]string, len(values))
+	for i, value := range values {
+		pairs[i] = s.labels[i] + `="` + escapeLabel(value) + `"`
+	}
+	key := strings.Join(pairs, ",")
+
+	s.mutex.RLock()
+	metric, ok := s.series[key]
+	s.mutex.RUnlock()
+	if ok {
+		return metric
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if metric, ok := s.series[key]; ok {
+		return metric
+	}
+	metric = s.create()
+	s.series[key] = metric
+	return metric
+}
+
+// each calls visit for every series, sorted by labels
+func (s *seriesSet[T]) each(visit func(labels string, metric T)) {
+	s.mutex.RLock()
+	keys := make([]string, 0, len(s.series))
+	metrics := make(map[string]T, len(s.series))
+	for key, metric := range s.series {
+		keys = append(keys, key)
+		metrics[key] = metric
+	}
+	s.mutex.RUnlock()
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		visit(key, metrics[key])
+	}
+}
+
+// addFloat atomically adds delta to the float64 stored in bits
+func addFloat(bits *atomic.Uint64, delta float64) {
+	for {
+		old := bits.Load()
+		if bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+// writeSample writes one sample line
+func writeSample(out *bufio.Writer, name, labels string, value float64) {
+	out.WriteString(name)
+	if labels != "" {
+		out.WriteString("{" + labels + "}")
+	}
+	out.WriteString(" " + formatFloat(value) + "\n")
+}
+
+// joinLabels appends one label to formatted labels
+func joinLabels(labels, name, value string) string {
+	label := name + `="` + value + `"`
+	if labels == "" {
+		return label
+	}
+	return labels + "," + label
+}
+
+// formatFloat formats a sample value the way Prometheus expects
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// escapeLabel escapes a label value
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// escapeHelp escapes a help text
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
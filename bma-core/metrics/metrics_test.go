@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.CounterVec("test_requests_total", "Requests by route", "method", "route")
+	requests.With("GET", "/songs").Add(3)
+	requests.With("GET", `/say "hi"\now`).Inc()
+	requests.With("DELETE", "/songs").Inc()
+	registry.Gauge("test_active", "Active streams\nright now").Set(2)
+	registry.GaugeFunc("test_up", `Up, 1 or 0 \ yes`, func() float64 { return Bool(true) })
+	duration := registry.HistogramVec("test_duration_seconds", "Durations", []float64{1, 0.1}, "route")
+	for _, value := range []float64{0.05, 0.1, 0.5, 2} {
+		duration.With("/songs").Observe(value)
+	}
+
+	var out strings.Builder
+	if err := registry.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	// Families sorted by name, series sorted by labels, histogram buckets cumulative and ending with +Inf
+	want := `# HELP test_active Active streams\nright now
+# TYPE test_active gauge
+test_active 2
+# HELP test_duration_seconds Durations
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/songs",le="0.1"} 2
+test_duration_seconds_bucket{route="/songs",le="1"} 3
+test_duration_seconds_bucket{route="/songs",le="+Inf"} 4
+test_duration_seconds_sum{route="/songs"} 2.65
+test_duration_seconds_count{route="/songs"} 4
+# HELP test_requests_total Requests by route
+# TYPE test_requests_total counter
+test_requests_total{method="DELETE",route="/songs"} 1
+test_requests_total{method="GET",route="/say \"hi\"\\now"} 1
+test_requests_total{method="GET",route="/songs"} 3
+# HELP test_up Up, 1 or 0 \\ yes
+# TYPE test_up gauge
+test_up 1
+`
+	if out.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestFormatFloat(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{0, "0"},
+		{42, "42"},
+		{0.005, "0.005"},
+		{1e21, "1e+21"},
+		{math.Inf(1), "+Inf"},
+		{math.Inf(-1), "-Inf"},
+		{math.NaN(), "NaN"},
+	}
+
+	for _, tt := range tests {
+		if got := formatFloat(tt.value); got != tt.want {
+			t.Errorf("formatFloat(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterAgain(t *testing.T) {
+	registry := NewRegistry()
+	registry.Counter("test_total", "Count").Inc()
+	registry.Counter("test_total", "Count").Inc()
+	if got := registry.Counter("test_total", "Count").Value(); got != 2 {
+		t.Errorf("counter registered again = %v, want the first one with 2", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a counter name as a gauge did not panic")
+		}
+	}()
+	registry.Gauge("test_total", "Count")
+}
+
+func TestHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.Gauge("test_up", "Up").Set(1)
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if got := recorder.Header().Get("Content-Type"); got != ContentType {
+		t.Errorf("Content-Type = %q, want %q", got, ContentType)
+	}
+	if !strings.Contains(recorder.Body.String(), "test_up 1\n") {
+		t.Errorf("body = %q, want the test_up sample", recorder.Body.String())
+	}
+}
@@ -0,0 +1,327 @@
+// Package pairing manages the tokens devices pair with, and the QR codes that carry them
+package pairing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"bma-core/settings"
+	"github.com/google/uuid"
+)
+
+// PairedDevice represents a device paired with the server through a QR token
+type PairedDevice struct {
+	ID         uuid.UUID `json:"id"`
+	Token      string    `json:"token"`
+	DeviceName string    `json:"deviceName,omitempty"`
+	IPAddress  string    `json:"ipAddress,omitempty"`
+	UserAgent  string    `json:"userAgent,omitempty"`
+	PairedAt   time.Time `json:"pairedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	LastSeenAt time.Time `json:"lastSeenAt,omitempty"`
+}
+
+// IsExpired returns true if the pairing token is no longer valid
+func (d *PairedDevice) IsExpired() bool {
+	return time.Now().After(d.ExpiresAt)
+}
+
+// unusedAndExpired returns true if the token expired before any device made a request with it
+func (d *PairedDevice) unusedAndExpired() bool {
+	return d.LastSeenAt.IsZero() && d.IsExpired()
+}
+
+// DeviceStore persists pairing tokens and devices in the config directory.
+// It is shared between the running server and CLI commands, so it reloads
+// the file whenever another process has changed it.
+type DeviceStore struct {
+	mutex   sync.Mutex
+	path    string
+	modTime time.Time
+	Devices []*PairedDevice `json:"devices"`
+}
+
+// Errors of ValidateToken and TrackActivity
+var (
+	ErrUnknownToken = errors.New("unknown pairing token")
+	ErrTokenExpired = errors.New("pairing token expired")
+)
+
+// lastSeenSaveInterval limits how often request activity is written to disk
+const lastSeenSaveInterval = time.Minute
+
+// GetDeviceStorePath returns the path to the devices file
+func GetDeviceStorePath() (string, error) {
+	configDir, err := settings.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "devices.json"), nil
+}
+
+// LoadDeviceStore loads the device store from the config directory
+func LoadDeviceStore() (*DeviceStore, error) {
+	path, err := GetDeviceStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	ds := &DeviceStore{path: path}
+	if err := ds.reloadIfChanged(); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// NewMemoryDeviceStore creates a store that is never written to disk
+func NewMemoryDeviceStore() *DeviceStore {
+	return &DeviceStore{}
+}
+
+// reloadIfChanged re-reads the file if it was modified since the last load (assumes lock held or unshared)
+func (ds *DeviceStore) reloadIfChanged() error {
+	if ds.path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(ds.path)
+	if os.IsNotExist(err) {
+		ds.Devices = nil
+		ds.modTime = time.Time{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if info.ModTime().Equal(ds.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(ds.path)
+	if err != nil {
+		return err
+	}
+
+	var stored struct {
+		Devices []*PairedDevice `json:"devices"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", ds.path, err)
+	}
+
+	ds.Devices = stored.Devices
+	ds.modTime = info.ModTime()
+	return nil
+}
+
+// save writes the store to disk (assumes lock held).
+// Every /pair and /qr request issues a token, so tokens no device used are dropped once they expire.
+func (ds *DeviceStore) save() error {
+	kept := ds.Devices[:0]
+	for _, device := range ds.Devices {
+		if !device.unusedAndExpired() {
+			kept = append(kept, device)
+		}
+	}
+	ds.Devices = kept
+
+	if ds.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(struct {
+		Devices []*PairedDevice `json:"devices"`
+	}{ds.Devices}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Tokens grant access to the library, keep the file private
+	if err := os.WriteFile(ds.path, data, 0600); err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(ds.path); err == nil {
+		ds.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// IssueToken creates a new pairing token valid for the given duration
+func (ds *DeviceStore) IssueToken(validFor time.Duration) (*PairedDevice, error) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if err := ds.reloadIfChanged(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	device := &PairedDevice{
+		ID:        uuid.New(),
+		Token:     uuid.New().String(),
+		PairedAt:  now,
+		ExpiresAt: now.Add(validFor),
+	}
+	ds.Devices = append(ds.Devices, device)
+
+	if err := ds.save(); err != nil {
+		return nil, err
+	}
+
+	copied := *device
+	return &copied, nil
+}
+
+// IsValidToken checks if a token is known and not expired
+func (ds *DeviceStore) IsValidToken(token string) bool {
+	return ds.ValidateToken(token) == nil
+}
+
+// ValidateToken returns ErrUnknownToken or ErrTokenExpired if a token cannot be used
+func (ds *DeviceStore) ValidateToken(token string) error {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	ds.reloadIfChanged()
+	_, err := ds.findValidUnsafe(token)
+	return err
+}
+
+// TrackActivity records a request made with a token, it returns ErrUnknownToken or ErrTokenExpired for tokens that cannot be used
+func (ds *DeviceStore) TrackActivity(token, ipAddress, userAgent string) error {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	ds.reloadIfChanged()
+	device, err := ds.findValidUnsafe(token)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	changed := device.IPAddress != ipAddress || device.UserAgent != userAgent ||
+		now.Sub(device.LastSeenAt) > lastSeenSaveInterval
+
+	device.IPAddress = ipAddress
+	device.UserAgent = userAgent
+	device.DeviceName = DeviceNameFromUserAgent(userAgent)
+	device.LastSeenAt = now
+
+	if changed {
+		ds.save()
+	}
+	return nil
+}
+
+// List returns a copy of all paired devices
+func (ds *DeviceStore) List() []PairedDevice {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	ds.reloadIfChanged()
+	devices := make([]PairedDevice, len(ds.Devices))
+	for i, device := range ds.Devices {
+		devices[i] = *device
+	}
+	return devices
+}
+
+// Revoke removes the device whose ID or token starts with the given prefix
+func (ds *DeviceStore) Revoke(idOrToken string) (*PairedDevice, error) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if err := ds.reloadIfChanged(); err != nil {
+		return nil, err
+	}
+
+	idOrToken = strings.ToLower(strings.TrimSpace(idOrToken))
+	if idOrToken == "" {
+		return nil, fmt.Errorf("no device ID given")
+	}
+
+	matchIndex := -1
+	for i, device := range ds.Devices {
+		if strings.HasPrefix(device.ID.String(), idOrToken) || strings.HasPrefix(device.Token, idOrToken) {
+			if matchIndex >= 0 {
+				return nil, fmt.Errorf("%q matches more than one device", idOrToken)
+			}
+			matchIndex = i
+		}
+	}
+	if matchIndex < 0 {
+		return nil, fmt.Errorf("no device matches %q", idOrToken)
+	}
+
+	revoked := ds.Devices[matchIndex]
+	ds.Devices = append(ds.Devices[:matchIndex], ds.Devices[matchIndex+1:]...)
+	if err := ds.save(); err != nil {
+		return nil, err
+	}
+	return revoked, nil
+}
+
+// RevokeAll removes every paired device and returns how many were removed
+func (ds *DeviceStore) RevokeAll() (int, error) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if err := ds.reloadIfChanged(); err != nil {
+		return 0, err
+	}
+
+	count := len(ds.Devices)
+	ds.Devices = nil
+	return count, ds.save()
+}
+
+// findByTokenUnsafe looks up a device by token (assumes lock held)
+func (ds *DeviceStore) findByTokenUnsafe(token string) *PairedDevice {
+	for _, device := range ds.Devices {
+		if device.Token == token {
+			return device
+		}
+	}
+	return nil
+}
+
+// findValidUnsafe looks up the device of a token that is known and not expired (assumes lock held)
+func (ds *DeviceStore) findValidUnsafe(token string) (*PairedDevice, error) {
+	device := ds.findByTokenUnsafe(token)
+	if device == nil {
+		return nil, ErrUnknownToken
+	}
+	if device.IsExpired() {
+		return nil, ErrTokenExpired
+	}
+	return device, nil
+}
+
+// DeviceNameFromUserAgent extracts a friendly device name from a user agent
+func DeviceNameFromUserAgent(userAgent string) string {
+	switch {
+	case userAgent == "":
+		return "Unknown Device"
+	case strings.Contains(userAgent, "Android"):
+		return "Android Device"
+	case strings.Contains(userAgent, "iPhone"):
+		return "iPhone"
+	case strings.Contains(userAgent, "iPad"):
+		return "iPad"
+	case strings.Contains(userAgent, "Mac"):
+		return "Mac"
+	case strings.Contains(userAgent, "BMA"):
+		return "BMA App"
+	default:
+		return "Unknown Device"
+	}
+}
@@ -0,0 +1,85 @@
+package pairing
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIssueTokenPrunesUnusedTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devices.json")
+	now := time.Now()
+
+	tests := []struct {
+		token     string
+		expiresAt time.Time
+		lastSeen  time.Time
+		wantKept  bool
+	}{
+		{"unused-expired", now.Add(-time.Minute), time.Time{}, false},
+		{"unused-valid", now.Add(time.Hour), time.Time{}, true},
+		{"used-expired", now.Add(-time.Minute), now.Add(-time.Hour), true}, // Still listed, so the user sees why it stopped working
+		{"used-valid", now.Add(time.Hour), now.Add(-time.Hour), true},
+	}
+
+	var stored struct {
+		Devices []*PairedDevice `json:"devices"`
+	}
+	for _, tt := range tests {
+		stored.Devices = append(stored.Devices, &PairedDevice{Token: tt.token, PairedAt: now.Add(-2 * time.Hour), ExpiresAt: tt.expiresAt, LastSeenAt: tt.lastSeen})
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ds := &DeviceStore{path: path}
+	issued, err := ds.IssueToken(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the file back, like another process would
+	reloaded := &DeviceStore{path: path}
+	if err := reloaded.reloadIfChanged(); err != nil {
+		t.Fatal(err)
+	}
+	kept := make(map[string]bool)
+	for _, device := range reloaded.List() {
+		kept[device.Token] = true
+	}
+
+	for _, tt := range tests {
+		if kept[tt.token] != tt.wantKept {
+			t.Errorf("%s kept = %v, want %v", tt.token, kept[tt.token], tt.wantKept)
+		}
+	}
+	if !kept[issued.Token] {
+		t.Error("the issued token was not saved")
+	}
+	if len(kept) != 4 {
+		t.Errorf("devices.json has %d devices, want 4", len(kept))
+	}
+}
+
+func TestReloadingQRCodeKeepsStoreSmall(t *testing.T) {
+	ds := &DeviceStore{path: filepath.Join(t.TempDir(), "devices.json")}
+
+	// Each reload of the /qr page issues a token; only those that have not expired yet are kept
+	for i := 0; i < 50; i++ {
+		if _, err := ds.IssueToken(-time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := ds.IssueToken(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if devices := ds.List(); len(devices) != 1 {
+		t.Errorf("store has %d devices, want only the token that has not expired", len(devices))
+	}
+}
@@ -1,4 +1,4 @@
-package models
+package pairing
 
 import (
 	"encoding/base64"
@@ -90,9 +90,3 @@ func (qr *QRCodeGenerator) GetPairingDataJSON(serverURL, token string, expiresAt
 	
 	return string(jsonData), nil
 }
-
-// TODO: Phase 4 Implementation
-// - QR code generation using github.com/skip2/go-qrcode
-// - Token generation and management
-// - Expiration handling
-// - Display in Fyne GUI 
\ No newline at end of file
@@ -0,0 +1,219 @@
+// Package settings loads and saves the configuration shared by bma-cli and the BMA desktop app
+package settings
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"bma-core/library"
+	"bma-core/logging"
+)
+
+// DefaultPort is the HTTP port used when none is configured
+const DefaultPort = 8080
+
+// DefaultMetricsAddr is where /metrics is served unless metricsAddr is set. Loopback only,
+// since the metrics need no token.
+const DefaultMetricsAddr = "127.0.0.1:9108"
+
+// Tailscale modes supported by the server
+const (
+	// TailscaleModeSystem uses the tailscaled installed on the host (default)
+	TailscaleModeSystem = "system"
+	// TailscaleModeEmbedded runs BMA's own userspace tailnet node
+	TailscaleModeEmbedded = "embedded"
+
+	// DefaultTailscaleHostname is the tailnet name used by the embedded node
+	DefaultTailscaleHostname = "bma"
+)
+
+// ConfigDirName is the folder in the home directory that holds the config file and app state.
+// bma-cli changes it before loading the config, so it keeps its settings apart from the desktop app.
+var ConfigDirName = ".bma"
+
+// Config represents the application configuration
+type Config struct {
+	SetupComplete     bool                  `json:"setupComplete"`
+	MusicFolder       string                `json:"musicFolder,omitempty"` // First library root, kept for older versions
+	LibraryRoots      []library.LibraryRoot `json:"libraryRoots,omitempty"`
+	TailscaleIP       string                `json:"tailscaleIP,omitempty"`       // bma-cli
+	Port              int                   `json:"port,omitempty"`              // bma-cli
+	BrowseRoots       []string              `json:"browseRoots,omitempty"`       // bma-cli, folders the setup page may browse
+	TailscaleMode     string                `json:"tailscaleMode,omitempty"`     // Desktop app
+	TailscaleHostname string                `json:"tailscaleHostname,omitempty"` // Desktop app
+	Scan              *library.ScanOptions  `json:"scan,omitempty"`
+	LogLevel          string                `json:"logLevel,omitempty"`    // debug, info, warn or error
+	LogFormat         string                `json:"logFormat,omitempty"`   // text or json
+	MetricsAddr       string                `json:"metricsAddr,omitempty"` // Listener for /metrics, empty for DefaultMetricsAddr
+}
+
+// GetConfigDir returns the directory holding the config file and app state
+func GetConfigDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	
+	configDir := filepath.Join(homeDir, ConfigDirName)
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", err
+	}
+	
+	return configDir, nil
+}
+
+// GetConfigPath returns the path to the config file
+func GetConfigPath() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	
+	return filepath.Join(configDir, "config.json"), nil
+}
+
+// GetTailscaleStateDir returns the state directory of the embedded tailnet node
+func GetTailscaleStateDir() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	
+	stateDir := filepath.Join(configDir, "tsnet")
+	if err := os.MkdirAll(stateDir, 0700); err != nil {
+		return "", err
+	}
+	
+	return stateDir, nil
+}
+
+// GetPort returns the configured HTTP port or the default
+func (c *Config) GetPort() int {
+	if c.Port > 0 {
+		return c.Port
+	}
+	return DefaultPort
+}
+
+// GetMetricsAddr returns the configured /metrics listener or the default
+func (c *Config) GetMetricsAddr() string {
+	if c.MetricsAddr != "" {
+		return c.MetricsAddr
+	}
+	return DefaultMetricsAddr
+}
+
+// GetLogOptions returns the configured log level and format, overridden by BMA_LOG_LEVEL and BMA_LOG_FORMAT
+func (c *Config) GetLogOptions() logging.Options {
+	options := logging.Options{Level: c.LogLevel, Format: c.LogFormat}
+	options.ApplyEnv()
+	return options
+}
+
+// GetBrowseRoots returns the folders the setup folder picker may browse.
+// Without configured roots it uses the home directory and common mount points.
+func (c *Config) GetBrowseRoots() []string {
+	candidates := c.BrowseRoots
+	if len(candidates) == 0 {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			candidates = append(candidates, homeDir)
+		}
+		candidates = append(candidates, "/media", "/mnt", "/srv")
+		if user := os.Getenv("USER"); user != "" {
+			candidates = append(candidates, filepath.Join("/run/media", user))
+		}
+		if runtime.GOOS == "darwin" {
+			candidates = append(candidates, "/Volumes")
+		}
+	}
+	
+	// Only offer roots that exist
+	var roots []string
+	for _, root := range candidates {
+		if info, err := os.Stat(root); err == nil && info.IsDir() {
+			roots = append(roots, filepath.Clean(root))
+		}
+	}
+	return roots
+}
+
+// LoadConfig loads the configuration from file or returns default config
+func LoadConfig() (*Config, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	
+	// If config file doesn't exist, return default config
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return &Config{
+			SetupComplete: false,
+		}, nil
+	}
+	
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	
+	return &config, nil
+}
+
+// SaveConfig saves the configuration to file
+func (c *Config) SaveConfig() error {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return err
+	}
+	
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	
+	return os.WriteFile(configPath, data, 0644)
+}
+
+// MarkSetupComplete marks the setup as complete and saves the config
+func (c *Config) MarkSetupComplete() error {
+	c.SetupComplete = true
+	return c.SaveConfig()
+}
+
+// SetMusicFolder makes folderPath the only library root and saves the config
+func (c *Config) SetMusicFolder(folderPath string) error {
+	c.ResetLibraryRoots(folderPath)
+	return c.SaveConfig()
+}
+
+// SetTailscaleIP sets the Tailscale IP and saves the config
+func (c *Config) SetTailscaleIP(ip string) error {
+	c.TailscaleIP = ip
+	return c.SaveConfig()
+}
+
+// UsesEmbeddedTailscale reports whether BMA should run its own tailnet node
+func (c *Config) UsesEmbeddedTailscale() bool {
+	return c.TailscaleMode == TailscaleModeEmbedded
+}
+
+// EmbeddedTailscaleHostname returns the tailnet hostname for the embedded node
+func (c *Config) EmbeddedTailscaleHostname() string {
+	if c.TailscaleHostname != "" {
+		return c.TailscaleHostname
+	}
+	return DefaultTailscaleHostname
+}
+
+// SetTailscaleMode sets the Tailscale mode and saves the config
+func (c *Config) SetTailscaleMode(mode string) error {
+	c.TailscaleMode = mode
+	return c.SaveConfig()
+}
@@ -0,0 +1,122 @@
+package settings
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"bma-core/library"
+)
+
+// GetLibraryRoots returns all configured library roots, including a legacy MusicFolder
+func (c *Config) GetLibraryRoots() []library.LibraryRoot {
+	if len(c.LibraryRoots) == 0 && c.MusicFolder != "" {
+		return []library.LibraryRoot{{Path: c.MusicFolder, Enabled: true}}
+	}
+
+	roots := make([]library.LibraryRoot, len(c.LibraryRoots))
+	copy(roots, c.LibraryRoots)
+	return roots
+}
+
+// EnabledLibraryRoots returns the roots that should be scanned
+func (c *Config) EnabledLibraryRoots() []library.LibraryRoot {
+	var roots []library.LibraryRoot
+	for _, root := range c.GetLibraryRoots() {
+		if root.Enabled {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// ResetLibraryRoots replaces all roots with a single folder (does not save)
+func (c *Config) ResetLibraryRoots(folderPath string) {
+	c.LibraryRoots = nil
+	if folderPath != "" {
+		c.LibraryRoots = []library.LibraryRoot{{Path: filepath.Clean(folderPath), Enabled: true}}
+	}
+	c.syncMusicFolder()
+}
+
+// AddLibraryRoot adds a folder to the library and saves the config. Folders inside or
+// containing another root are rejected, their songs would be scanned twice.
+func (c *Config) AddLibraryRoot(folderPath, label string) error {
+	if folderPath == "" {
+		return fmt.Errorf("no folder given")
+	}
+	folderPath = filepath.Clean(folderPath)
+	resolved := resolveRootPath(folderPath)
+
+	roots := c.GetLibraryRoots()
+	for _, root := range roots {
+		existing := resolveRootPath(root.Path)
+		switch {
+		case root.Path == folderPath || existing == resolved:
+			return fmt.Errorf("%s is already a library root", folderPath)
+		case isWithin(resolved, existing):
+			return fmt.Errorf("%s is inside the library root %s", folderPath, root.Path)
+		case isWithin(existing, resolved):
+			return fmt.Errorf("%s contains the library root %s, remove that root first", folderPath, root.Path)
+		}
+		if label != "" && strings.EqualFold(root.Label, label) {
+			return fmt.Errorf("label %q is already used by %s", label, root.Path)
+		}
+	}
+
+	c.LibraryRoots = append(roots, library.LibraryRoot{Path: folderPath, Label: label, Enabled: true})
+	c.syncMusicFolder()
+	return c.SaveConfig()
+}
+
+// RemoveLibraryRoot removes the root with the given path or label and saves the config
+func (c *Config) RemoveLibraryRoot(pathOrLabel string) (library.LibraryRoot, error) {
+	roots := c.GetLibraryRoots()
+	for i, root := range roots {
+		if root.Matches(pathOrLabel) {
+			c.LibraryRoots = append(roots[:i], roots[i+1:]...)
+			c.syncMusicFolder()
+			return root, c.SaveConfig()
+		}
+	}
+	return library.LibraryRoot{}, fmt.Errorf("no library root matches %q", pathOrLabel)
+}
+
+// SetLibraryRootEnabled enables or disables a root and saves the config
+func (c *Config) SetLibraryRootEnabled(pathOrLabel string, enabled bool) error {
+	roots := c.GetLibraryRoots()
+	for i, root := range roots {
+		if root.Matches(pathOrLabel) {
+			roots[i].Enabled = enabled
+			c.LibraryRoots = roots
+			c.syncMusicFolder()
+			return c.SaveConfig()
+		}
+	}
+	return fmt.Errorf("no library root matches %q", pathOrLabel)
+}
+
+// resolveRootPath returns path with symlinks resolved, or as is if it can't be resolved (e.g. an unplugged drive)
+func resolveRootPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// isWithin returns true if path is inside dir
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// syncMusicFolder keeps the legacy MusicFolder pointing at the first root
+func (c *Config) syncMusicFolder() {
+	c.MusicFolder = ""
+	if len(c.LibraryRoots) > 0 {
+		c.MusicFolder = c.LibraryRoots[0].Path
+	}
+}
@@ -0,0 +1,26 @@
+package settings
+
+import "testing"
+
+func TestIsWithin(t *testing.T) {
+	tests := []struct {
+		path string
+		dir  string
+		want bool
+	}{
+		{"/music/jazz", "/music", true},
+		{"/music/jazz/live", "/music", true},
+		{"/music", "/music", false},
+		{"/music", "/music/jazz", false},
+		{"/musicals", "/music", false},
+		{"/music/../other", "/music", false},
+		{"/other", "/music", false},
+		{"/music/..jazz", "/music", true}, // A folder whose name starts with two dots
+	}
+
+	for _, tt := range tests {
+		if got := isWithin(tt.path, tt.dir); got != tt.want {
+			t.Errorf("isWithin(%q, %q) = %v, want %v", tt.path, tt.dir, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package settings
+
+import "bma-core/library"
+
+// GetScanOptions returns the configured scan options with defaults filled in
+func (c *Config) GetScanOptions() library.ScanOptions {
+	var options library.ScanOptions
+	if c.Scan != nil {
+		options = *c.Scan
+	}
+	return options.WithDefaults()
+}